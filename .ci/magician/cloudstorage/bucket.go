@@ -17,8 +17,10 @@ package cloudstorage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"time"
 
@@ -92,3 +94,9 @@ func (gcs *Client) DownloadFile(bucket, object, filePath string) error {
 	return nil
 
 }
+
+// IsNotExist reports whether err is from downloading an object that doesn't
+// exist, from GCS or from a local bucket.
+func IsNotExist(err error) bool {
+	return errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,80 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cloudstorage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalClient stores objects in a local directory instead of GCS, so that
+// commands can run offline. Objects are stored at <dir>/<bucket>/<object>.
+type LocalClient struct {
+	dir string
+}
+
+func NewLocalClient(dir string) *LocalClient {
+	return &LocalClient{dir: dir}
+}
+
+// ObjectPath returns the local path of an object.
+func (c *LocalClient) ObjectPath(bucket, object string) string {
+	return filepath.Join(c.dir, bucket, filepath.FromSlash(object))
+}
+
+func (c *LocalClient) WriteToGCSBucket(bucket, object, filePath string) error {
+	if err := copyFile(filePath, c.ObjectPath(bucket, object)); err != nil {
+		return err
+	}
+	fmt.Printf("File stored locally in bucket %s as %s\n", bucket, object)
+	return nil
+}
+
+func (c *LocalClient) DownloadFile(bucket, object, filePath string) error {
+	if err := copyFile(c.ObjectPath(bucket, object), filePath); err != nil {
+		return err
+	}
+	fmt.Printf("Object %s copied from local bucket %s as %s\n", object, bucket, filePath)
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+	defer in.Close()
+
+	if dir := filepath.Dir(dest); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("os.MkdirAll: %w", err)
+		}
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("file.Close: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cloudstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalClientRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	c := NewLocalClient(filepath.Join(dir, "gcs"))
+
+	src := filepath.Join(dir, "src.json")
+	if err := os.WriteFile(src, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WriteToGCSBucket("bucket", "a/b/c.json", src); err != nil {
+		t.Fatalf("WriteToGCSBucket() error: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gcs", "bucket", "a", "b", "c.json")); err != nil {
+		t.Errorf("object not stored at the expected path: %s", err)
+	}
+
+	dest := filepath.Join(dir, "dest.json")
+	if err := c.DownloadFile("bucket", "a/b/c.json", dest); err != nil {
+		t.Fatalf("DownloadFile() error: %s", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Errorf("DownloadFile() wrote %q, want %q", got, `{"a":1}`)
+	}
+
+	if err := c.DownloadFile("bucket", "missing.json", dest); err == nil {
+		t.Error("DownloadFile() of a missing object returned no error")
+	}
+}
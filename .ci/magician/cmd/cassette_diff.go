@@ -0,0 +1,77 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"magician/vcr"
+
+	"github.com/spf13/cobra"
+
+	_ "embed"
+)
+
+//go:embed templates/vcr/cassette_diff.tmpl
+var cassetteDiffTmplText string
+
+// cassetteDiffCmd represents the cassetteDiff command
+var cassetteDiffCmd = &cobra.Command{
+	Use:   "cassette-diff BASE_DIR RECORDED_DIR",
+	Short: "Compares recorded VCR cassettes with the base cassettes",
+	Long: `This command compares each cassette in RECORDED_DIR with the cassette of the same name in BASE_DIR.
+
+	Random resource names, operation names, timestamps and server-generated IDs are normalized
+	before comparing. It reports, per cassette:
+	1. API calls that were added or removed.
+	2. Request bodies that changed.
+	3. Error responses that the base cassette didn't have.
+
+	test-terraform-vcr includes the same report in its comment after re-recording cassettes.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rnr, err := newRunner()
+		if err != nil {
+			return fmt.Errorf("error creating a runner: %w", err)
+		}
+		return execCassetteDiff(args[0], args[1], rnr)
+	},
+}
+
+func execCassetteDiff(baseDir, recordedDir string, rnr ExecRunner) error {
+	diffs, err := vcr.DiffCassetteDirs(rnr, baseDir, recordedDir)
+	if err != nil {
+		return fmt.Errorf("error comparing cassettes: %w", err)
+	}
+	report, err := formatCassetteDiff(diffs)
+	if err != nil {
+		return fmt.Errorf("error formatting cassette diff: %w", err)
+	}
+	if report == "" {
+		fmt.Println("No API call changes found")
+		return nil
+	}
+	fmt.Println(report)
+	return nil
+}
+
+func formatCassetteDiff(diffs []vcr.CassetteDiff) (string, error) {
+	return formatComment("cassette_diff.tmpl", cassetteDiffTmplText, diffs)
+}
+
+func init() {
+	rootCmd.AddCommand(cassetteDiffCmd)
+}
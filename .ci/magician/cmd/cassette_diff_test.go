@@ -0,0 +1,82 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"magician/vcr"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatCassetteDiff(t *testing.T) {
+	cases := map[string]struct {
+		diffs []vcr.CassetteDiff
+		want  string
+	}{
+		"no changes": {},
+		"changes": {
+			diffs: []vcr.CassetteDiff{
+				{Name: "TestAccPubsubTopic_new", NewCassette: true},
+				{
+					Name:          "TestAccPubsubTopic_update",
+					AddedCalls:    []string{"PATCH https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json"},
+					RemovedCalls:  []string{"DELETE https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json"},
+					ChangedBodies: []vcr.BodyChange{{Call: "PUT https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json", Base: `{"labels":{"foo":"bar"}}`, Recorded: `{"labels":{"foo":"baz"}}`}},
+					NewErrors:     []vcr.ErrorResponse{{Call: "PATCH https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json", Status: "400 Bad Request", Body: `{"error":{"code":400}}`}},
+				},
+			},
+			want: "<details>\n" +
+				"<summary>API call changes in 2 re-recorded cassettes compared to the base branch</summary>\n" +
+				"\n" +
+				"#### `TestAccPubsubTopic_new`\n" +
+				"New cassette, not present on the base branch.\n" +
+				"\n" +
+				"#### `TestAccPubsubTopic_update`\n" +
+				"Added API calls:\n" +
+				"- `PATCH https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json`\n" +
+				"\n" +
+				"Removed API calls:\n" +
+				"- `DELETE https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json`\n" +
+				"\n" +
+				"Changed request bodies:\n" +
+				"- `PUT https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json`\n" +
+				"  ```diff\n" +
+				"  - {\"labels\":{\"foo\":\"bar\"}}\n" +
+				"  + {\"labels\":{\"foo\":\"baz\"}}\n" +
+				"  ```\n" +
+				"\n" +
+				"New error responses:\n" +
+				"- `PATCH https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json`: 400 Bad Request\n" +
+				"  ```\n" +
+				"  {\"error\":{\"code\":400}}\n" +
+				"  ```\n" +
+				"\n" +
+				"</details>",
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			got, err := formatCassetteDiff(tc.diffs)
+			if err != nil {
+				t.Fatalf("formatCassetteDiff() error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("formatCassetteDiff() returned unexpected difference (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
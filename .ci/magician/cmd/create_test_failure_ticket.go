@@ -18,7 +18,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"magician/cloudstorage"
 	"magician/provider"
 	utils "magician/utility"
 	"os"
@@ -78,6 +77,8 @@ func (s testFailureRateLabel) String() string {
 type testFailure struct {
 	TestName          string
 	AffectedResource  string
+	Service           string
+	ErrorMessages     map[provider.Version]string
 	DebugLogLinks     map[provider.Version]string
 	ErrorMessageLinks map[provider.Version]string
 	FailureRates      map[provider.Version]string
@@ -96,7 +97,8 @@ var createTestFailureTicketCmd = &cobra.Command{
 	  		a. failed 100% in last 3 days, or 
 			b. failed 50%+ in last 7 days
 	  3. Retrieves existing active and recently closed(within 24 hours) test failure tickets
-	  4. Creates new tickets for identified failing tests detected in step 3 that don't already have a corresponding ticket.
+	  4. Groups the identified failing tests that don't already have a corresponding ticket by normalized error message.
+	  5. Adds each group to the open ticket for the same error message, or creates a new ticket for it.
   
 	  The following environment variables are required:
   ` + listCTFTRequiredEnvironmentVariables(),
@@ -110,8 +112,11 @@ var createTestFailureTicketCmd = &cobra.Command{
 			env[ev] = val
 		}
 
-		gh := github.NewClient(nil).WithAuthToken(env["GITHUB_TOKEN"])
-		gcs := cloudstorage.NewClient()
+		issues, err := newIssuesService(env["GITHUB_TOKEN"])
+		if err != nil {
+			return err
+		}
+		gcs := newCloudstorageClient()
 
 		now := time.Now()
 
@@ -121,7 +126,7 @@ var createTestFailureTicketCmd = &cobra.Command{
 		}
 		date := now.In(loc)
 
-		return execCreateTestFailureTicket(date, gh, gcs)
+		return execCreateTestFailureTicket(date, issues, gcs)
 	},
 }
 
@@ -133,7 +138,7 @@ func listCTFTRequiredEnvironmentVariables() string {
 	return result
 }
 
-func execCreateTestFailureTicket(now time.Time, gh *github.Client, gcs CloudstorageClient) error {
+func execCreateTestFailureTicket(now time.Time, issues IssuesService, gcs CloudstorageClient) error {
 	ctx := context.Background()
 
 	gaTestFailuresMap := make(map[string][]bool)
@@ -159,24 +164,39 @@ func execCreateTestFailureTicket(now time.Time, gh *github.Client, gcs Cloudstor
 	}
 
 	// Get existing GitHub test failure issues
-	existTestNames, err := failingTestNamesFromActiveIssues(ctx, gh)
+	activeIssues, err := activeTestFailureIssues(ctx, issues)
+	if err != nil {
+		return fmt.Errorf("error getting active test failure issues: %w", err)
+	}
+	existTestNames, err := testNamesFromIssues(activeIssues)
 	if err != nil {
 		return fmt.Errorf("error getting active test failure issues: %w", err)
 	}
 
 	// Get Github test failue issues closed (fixed) today
-	closedTestNames, err := failingTestNamesFromClosedIssuesToday(ctx, gh, now)
+	closedTestNames, err := failingTestNamesFromClosedIssuesToday(ctx, issues, now)
 	if err != nil {
 		return fmt.Errorf("error getting today's closed test failure issues: %w", err)
 	}
 
-	// Create tickets
+	var newFailures []*testFailure
 	for _, testFailure := range testFailuresToday {
 		if shouldCreateTicket(*testFailure, existTestNames, closedTestNames) {
-			err := createTicket(ctx, gh, testFailure)
-			if err != nil {
-				return fmt.Errorf("error creating test failure ticket: %w", err)
+			newFailures = append(newFailures, testFailure)
+		}
+	}
+
+	// Create one ticket per root cause, reusing open tickets for the same error
+	clusterIssues := clusterIssuesBySignature(activeIssues)
+	for _, cluster := range clusterTestFailures(newFailures) {
+		if issue, ok := clusterIssues[cluster.Signature]; ok {
+			if err := addToTicket(ctx, issues, issue, cluster); err != nil {
+				return fmt.Errorf("error adding tests to test failure ticket: %w", err)
 			}
+			continue
+		}
+		if err := createTicket(ctx, issues, cluster); err != nil {
+			return fmt.Errorf("error creating test failure ticket: %w", err)
 		}
 	}
 	return nil
@@ -201,6 +221,8 @@ func lastNDaysTestFailureMap(pVersion provider.Version, n int, now time.Time, gc
 					testFailuresToday[testName] = &testFailure{
 						TestName:          testName,
 						AffectedResource:  convertTestNameToResource(testName),
+						Service:           testInfo.Service,
+						ErrorMessages:     map[provider.Version]string{provider.GA: "", provider.Beta: ""},
 						ErrorMessageLinks: map[provider.Version]string{provider.GA: "", provider.Beta: ""},
 						DebugLogLinks:     map[provider.Version]string{provider.GA: "", provider.Beta: ""},
 						FailureRates:      map[provider.Version]string{provider.GA: "N/A", provider.Beta: "N/A"},
@@ -214,6 +236,7 @@ func lastNDaysTestFailureMap(pVersion provider.Version, n int, now time.Time, gc
 				if err != nil {
 					return err
 				}
+				testFailuresToday[testName].ErrorMessages[pVersion] = testInfo.ErrorMessage
 				testFailuresToday[testName].ErrorMessageLinks[pVersion] = errorMessageLink
 				testFailuresToday[testName].DebugLogLinks[pVersion] = testInfo.LogLink
 			}
@@ -268,16 +291,27 @@ func getTestInfoList(pVersion provider.Version, date time.Time, gcs Cloudstorage
 	objectName := fmt.Sprintf("test-metadata/%s/%s", pVersion.String(), testStatusFileName)
 
 	var testInfoList []TestInfo
-	err := gcs.DownloadFile(NightlyDataBucket, objectName, testStatusFileName)
-	if err != nil {
+	if err := downloadJson(gcs, NightlyDataBucket, objectName, &testInfoList); err != nil {
 		return testInfoList, err
 	}
+	return testInfoList, nil
+}
 
-	err = utils.ReadFromJson(&testInfoList, testStatusFileName)
+// downloadJson reads a JSON object from GCS into v. The object is downloaded
+// to a temporary file of its own, since build steps that run in parallel share
+// the working directory.
+func downloadJson(gcs CloudstorageClient, bucket, object string, v any) error {
+	f, err := os.CreateTemp("", "magician-*.json")
 	if err != nil {
-		return testInfoList, err
+		return err
 	}
-	return testInfoList, nil
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if err := gcs.DownloadFile(bucket, object, f.Name()); err != nil {
+		return err
+	}
+	return utils.ReadFromJson(v, f.Name())
 }
 
 func shouldCreateTicket(testfailure testFailure, existTestNames []string, todayClosedTestNames []string) bool {
@@ -336,27 +370,16 @@ func convertTestNameToResource(testName string) string {
 	return resourceName
 }
 
-func failingTestNamesFromActiveIssues(ctx context.Context, gh *github.Client) ([]string, error) {
-
+func activeTestFailureIssues(ctx context.Context, issues IssuesService) ([]*github.Issue, error) {
 	opts := &github.IssueListByRepoOptions{
 		State:       "open",
 		Labels:      []string{"test-failure"},
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	issues, err := ListIssuesWithOpts(ctx, gh, opts)
-	if err != nil {
-		return nil, err
-	}
-	tests, err := testNamesFromIssues(issues)
-	if err != nil {
-		return nil, err
-	}
-
-	return tests, nil
-
+	return ListIssuesWithOpts(ctx, issues, opts)
 }
 
-func failingTestNamesFromClosedIssuesToday(ctx context.Context, gh *github.Client, date time.Time) ([]string, error) {
+func failingTestNamesFromClosedIssuesToday(ctx context.Context, issueService IssuesService, date time.Time) ([]string, error) {
 	lastday := date.AddDate(0, 0, -1)
 	opts := &github.IssueListByRepoOptions{
 		State:       "closed",
@@ -364,7 +387,7 @@ func failingTestNamesFromClosedIssuesToday(ctx context.Context, gh *github.Clien
 		Since:       lastday,
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	issues, err := ListIssuesWithOpts(ctx, gh, opts)
+	issues, err := ListIssuesWithOpts(ctx, issueService, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -376,11 +399,11 @@ func failingTestNamesFromClosedIssuesToday(ctx context.Context, gh *github.Clien
 	return tests, nil
 }
 
-func ListIssuesWithOpts(ctx context.Context, gh *github.Client, opts *github.IssueListByRepoOptions) ([]*github.Issue, error) {
+func ListIssuesWithOpts(ctx context.Context, issueService IssuesService, opts *github.IssueListByRepoOptions) ([]*github.Issue, error) {
 
 	var allIssues []*github.Issue
 	for {
-		issues, resp, err := gh.Issues.ListByRepo(ctx, GithubOwner, GithubRepo, opts)
+		issues, resp, err := issueService.ListByRepo(ctx, GithubOwner, GithubRepo, opts)
 		if err != nil {
 			return nil, fmt.Errorf("error listing issues: %w", err)
 		}
@@ -395,23 +418,17 @@ func ListIssuesWithOpts(ctx context.Context, gh *github.Client, opts *github.Iss
 	return allIssues, nil
 }
 
-func createTicket(ctx context.Context, gh *github.Client, testFailure *testFailure) error {
-	issueTitle := fmt.Sprintf("Failing test(s): %s", testFailure.TestName)
-	issueBody, err := formatIssueBody(*testFailure)
+func createTicket(ctx context.Context, issues IssuesService, cluster *testFailureCluster) error {
+	issueTitle := cluster.Title()
+	issueBody, err := formatIssueBody(cluster)
 	if err != nil {
 		return fmt.Errorf("error formatting issue body: %w", err)
 	}
 
-	failureRatelabel := testFailure.FailureRateLabels[provider.GA].String()
-
-	if testFailure.FailureRateLabels[provider.Beta] > testFailure.FailureRateLabels[provider.GA] {
-		failureRatelabel = testFailure.FailureRateLabels[provider.Beta].String()
-	}
-
 	ticketLabels := []string{
 		"size/xs",
 		"test-failure",
-		failureRatelabel,
+		cluster.FailureRateLabel().String(),
 	}
 
 	// Apply service labels to forward test failure ticket automatically
@@ -420,7 +437,7 @@ func createTicket(ctx context.Context, gh *github.Client, testFailure *testFailu
 		return fmt.Errorf("error building regex labels: %w", err)
 	}
 
-	labels := labeler.ComputeLabels([]string{testFailure.AffectedResource}, regexpLabels)
+	labels := labeler.ComputeLabels(cluster.AffectedResources(), regexpLabels)
 	ticketLabels = append(ticketLabels, labels...)
 
 	issueRquest := &github.IssueRequest{
@@ -432,18 +449,38 @@ func createTicket(ctx context.Context, gh *github.Client, testFailure *testFailu
 		Milestone: github.Int(11),
 	}
 
-	_, _, err = gh.Issues.Create(ctx, GithubOwner, GithubRepo, issueRquest)
+	_, _, err = issues.Create(ctx, GithubOwner, GithubRepo, issueRquest)
 	if err != nil {
 		return fmt.Errorf("error creating issue: %w", err)
 	}
 	return nil
 }
 
-func formatIssueBody(testFailure testFailure) (string, error) {
+// addToTicket links new failures to the open ticket for the same error.
+func addToTicket(ctx context.Context, issues IssuesService, issue *github.Issue, cluster *testFailureCluster) error {
+	tests := cluster.TestNames()
+	issueRequest := &github.IssueRequest{
+		Body: github.String(addTestsToIssueBody(issue.GetBody(), tests)),
+	}
+	if _, _, err := issues.Edit(ctx, GithubOwner, GithubRepo, issue.GetNumber(), issueRequest); err != nil {
+		return fmt.Errorf("error editing issue %d: %w", issue.GetNumber(), err)
+	}
+
+	comment := fmt.Sprintf("More tests failed with the same error:\n\n- %s\n", strings.Join(tests, "\n- "))
+	if _, _, err := issues.CreateComment(ctx, GithubOwner, GithubRepo, issue.GetNumber(), &github.IssueComment{Body: github.String(comment)}); err != nil {
+		return fmt.Errorf("error commenting on issue %d: %w", issue.GetNumber(), err)
+	}
+	return nil
+}
+
+func formatIssueBody(cluster *testFailureCluster) (string, error) {
 	tmpl, err := template.New("issue").Parse(testFailureIssueTemplate)
+	if err != nil {
+		return "", err
+	}
 
 	sb := new(strings.Builder)
-	err = tmpl.Execute(sb, testFailure)
+	err = tmpl.Execute(sb, cluster)
 	if err != nil {
 		return "", err
 	}
@@ -28,8 +28,6 @@ import (
 	"strings"
 	"text/template"
 
-	"magician/exec"
-	"magician/github"
 	"magician/provider"
 	"magician/source"
 
@@ -145,8 +143,11 @@ var generateCommentCmd = &cobra.Command{
 			}
 			env[tokenName] = val
 		}
-		gh := github.NewClient(env["GITHUB_TOKEN_MAGIC_MODULES"])
-		rnr, err := exec.NewRunner()
+		gh, err := newGithubClient(env["GITHUB_TOKEN_MAGIC_MODULES"])
+		if err != nil {
+			return err
+		}
+		rnr, err := newRunner()
 		if err != nil {
 			return fmt.Errorf("error creating a runner: %w", err)
 		}
@@ -309,6 +310,16 @@ func execGenerateComment(prNumber int, ghTokenMagicModules, buildId, buildStep,
 		"GOPATH": os.Getenv("GOPATH"),
 		"HOME":   os.Getenv("HOME"),
 	}
+	// The breaking change rules that read mmv1 metadata need the products
+	// from both sides of the merge commit; the left parent is the base branch.
+	oldProductsPath, newProductsPath := "", ""
+	oldMMPath := filepath.Join(mmLocalPath, "..", "mm-old")
+	if err := checkoutBaseMagicModules(mmLocalPath, oldMMPath, rnr); err != nil {
+		fmt.Println("Failed to check out base magic-modules; skipping mmv1 breaking change rules: ", err)
+	} else {
+		oldProductsPath = filepath.Join(oldMMPath, "mmv1", "products")
+		newProductsPath = filepath.Join(mmLocalPath, "mmv1", "products")
+	}
 	for _, repo := range []source.Repo{tpgRepo, tpgbRepo} {
 		if !repo.Cloned {
 			fmt.Println("Skipping diff processor; repo failed to clone: ", repo.Name)
@@ -325,7 +336,7 @@ func execGenerateComment(prNumber int, ghTokenMagicModules, buildId, buildStep,
 			continue
 		}
 
-		breakingChanges, err := computeBreakingChanges(diffProcessorPath, rnr)
+		breakingChanges, err := computeBreakingChanges(diffProcessorPath, oldProductsPath, newProductsPath, rnr)
 		if err != nil {
 			fmt.Println("computing breaking changes: ", err)
 			errors[repo.Title] = append(errors[repo.Title], "The diff processor crashed while computing breaking changes. This is usually due to the downstream provider failing to compile.")
@@ -369,6 +380,11 @@ func execGenerateComment(prNumber int, ghTokenMagicModules, buildId, buildStep,
 			uniqueAffectedResources[resource] = struct{}{}
 		}
 	}
+	if oldProductsPath != "" {
+		if err := removeBaseMagicModules(mmLocalPath, oldMMPath, rnr); err != nil {
+			fmt.Println("Failed to remove base magic-modules checkout: ", err)
+		}
+	}
 	breakingChangesSlice := maps.Values(uniqueBreakingChanges)
 	sort.Slice(breakingChangesSlice, func(i, j int) bool {
 		return breakingChangesSlice[i].Message < breakingChangesSlice[j].Message
@@ -548,11 +564,41 @@ func buildDiffProcessor(diffProcessorPath, providerLocalPath string, env map[str
 	return rnr.PopDir()
 }
 
-func computeBreakingChanges(diffProcessorPath string, rnr ExecRunner) ([]BreakingChange, error) {
+// checkoutBaseMagicModules checks out the base side of the merged magic-modules
+// PR (the left parent of HEAD) as a detached worktree at path.
+func checkoutBaseMagicModules(mmLocalPath, path string, rnr ExecRunner) error {
+	if err := rnr.PushDir(mmLocalPath); err != nil {
+		return err
+	}
+	if _, err := rnr.Run("git", []string{"worktree", "add", "--detach", path, "HEAD~"}, nil); err != nil {
+		rnr.PopDir()
+		return err
+	}
+	return rnr.PopDir()
+}
+
+// removeBaseMagicModules removes the worktree added by checkoutBaseMagicModules,
+// so that later runs in the same checkout can add it again.
+func removeBaseMagicModules(mmLocalPath, path string, rnr ExecRunner) error {
+	if err := rnr.PushDir(mmLocalPath); err != nil {
+		return err
+	}
+	if _, err := rnr.Run("git", []string{"worktree", "remove", "--force", path}, nil); err != nil {
+		rnr.PopDir()
+		return err
+	}
+	return rnr.PopDir()
+}
+
+func computeBreakingChanges(diffProcessorPath, oldProductsPath, newProductsPath string, rnr ExecRunner) ([]BreakingChange, error) {
 	if err := rnr.PushDir(diffProcessorPath); err != nil {
 		return nil, err
 	}
-	output, err := rnr.Run("bin/diff-processor", []string{"breaking-changes"}, nil)
+	args := []string{"breaking-changes", "--old-provider", "old", "--new-provider", "new"}
+	if oldProductsPath != "" && newProductsPath != "" {
+		args = append(args, "--old-products", oldProductsPath, "--new-products", newProductsPath)
+	}
+	output, err := rnr.Run("bin/diff-processor", args, nil)
 	if err != nil {
 		return nil, err
 	}
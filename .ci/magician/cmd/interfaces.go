@@ -16,13 +16,18 @@
 package cmd
 
 import (
+	"context"
 	"magician/github"
 	"magician/teamcity"
+	"path/filepath"
+
+	gh "github.com/google/go-github/v68/github"
 )
 
 type GithubClient interface {
 	GetPullRequest(prNumber string) (github.PullRequest, error)
 	GetPullRequests(state, base, sort, direction string) ([]github.PullRequest, error)
+	GetAllPullRequests(state, base, sort, direction string) ([]github.PullRequest, error)
 	GetPullRequestRequestedReviewers(prNumber string) ([]github.User, error)
 	GetPullRequestPreviousReviewers(prNumber string) ([]github.User, error)
 	GetPullRequestComments(prNumber string) ([]github.PullRequestComment, error)
@@ -40,6 +45,15 @@ type GithubClient interface {
 	CreateWorkflowDispatchEvent(workflowFileName string, inputs map[string]any) error
 }
 
+// IssuesService is the part of the go-github issues API used to manage test
+// failure tickets.
+type IssuesService interface {
+	ListByRepo(ctx context.Context, owner, repo string, opts *gh.IssueListByRepoOptions) ([]*gh.Issue, *gh.Response, error)
+	Create(ctx context.Context, owner, repo string, issue *gh.IssueRequest) (*gh.Issue, *gh.Response, error)
+	Edit(ctx context.Context, owner, repo string, number int, issue *gh.IssueRequest) (*gh.Issue, *gh.Response, error)
+	CreateComment(ctx context.Context, owner, repo string, number int, comment *gh.IssueComment) (*gh.IssueComment, *gh.Response, error)
+}
+
 type CloudbuildClient interface {
 	ApproveDownstreamGenAndTest(prNumber, commitSha string) error
 }
@@ -63,6 +77,7 @@ type ExecRunner interface {
 	PopDir() error
 	ReadFile(name string) (string, error)
 	WriteFile(name, data string) error
+	Walk(root string, fn filepath.WalkFunc) error
 	AppendFile(name, data string) error // Not used (yet).
 	Run(name string, args []string, env map[string]string) (string, error)
 	MustRun(name string, args []string, env map[string]string) string
@@ -0,0 +1,264 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"magician/cloudstorage"
+	"magician/exec"
+	"magician/github"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gh "github.com/google/go-github/v68/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	localGithubStateFile = "github.json"
+	localStorageDir      = "gcs"
+)
+
+var (
+	// used for flags
+	localDir string
+
+	// shared by all clients so that they see each other's changes
+	localGithub *github.LocalClient
+)
+
+// newGithubClient returns a GitHub client, or the local fake when --local-dir
+// is set.
+func newGithubClient(token string) (GithubClient, error) {
+	if localDir == "" {
+		return github.NewClient(token), nil
+	}
+	return localGithubClient()
+}
+
+// newIssuesService returns the GitHub issues API, or the local fake when
+// --local-dir is set.
+func newIssuesService(token string) (IssuesService, error) {
+	if localDir == "" {
+		return gh.NewClient(nil).WithAuthToken(token).Issues, nil
+	}
+	c, err := localGithubClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.Issues, nil
+}
+
+func localGithubClient() (*github.LocalClient, error) {
+	if localGithub != nil {
+		return localGithub, nil
+	}
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(localDir, localGithubStateFile)
+	c, err := github.NewLocalClient(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading local GitHub state: %w", err)
+	}
+	fmt.Printf("Using local GitHub state in %s\n", path)
+	localGithub = c
+	return c, nil
+}
+
+// newCloudstorageClient returns a GCS client, or a client for the local
+// storage directory when --local-dir is set.
+func newCloudstorageClient() CloudstorageClient {
+	if localDir == "" {
+		return cloudstorage.NewClient()
+	}
+	return cloudstorage.NewLocalClient(filepath.Join(localDir, localStorageDir))
+}
+
+// newRunner returns a runner. When --local-dir is set, gsutil copies go to and
+// from the local storage directory instead of GCS.
+func newRunner() (ExecRunner, error) {
+	rnr, err := exec.NewRunner()
+	if err != nil {
+		return nil, err
+	}
+	if localDir == "" {
+		return rnr, nil
+	}
+	return &localStorageRunner{
+		ExecRunner: rnr,
+		gcs:        cloudstorage.NewLocalClient(filepath.Join(localDir, localStorageDir)),
+	}, nil
+}
+
+// localStorageRunner runs commands with the wrapped runner, except for gsutil,
+// which is emulated against a local storage directory. Only cp is supported.
+type localStorageRunner struct {
+	ExecRunner
+	gcs *cloudstorage.LocalClient
+}
+
+func (r *localStorageRunner) Run(name string, args []string, env map[string]string) (string, error) {
+	if name != "gsutil" {
+		return r.ExecRunner.Run(name, args, env)
+	}
+	return "", r.gsutil(args)
+}
+
+func (r *localStorageRunner) MustRun(name string, args []string, env map[string]string) string {
+	out, err := r.Run(name, args, env)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return out
+}
+
+func (r *localStorageRunner) gsutil(args []string) error {
+	command := strings.Join(args, " ")
+	// Skip top-level options, such as -m, -q and -h <header>.
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-h" || args[0] == "-o" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] != "cp" {
+		return fmt.Errorf("gsutil %s is not supported with --local-dir", command)
+	}
+
+	recursive := false
+	var urls []string
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "-r" || arg == "-R":
+			recursive = true
+		case strings.HasPrefix(arg, "-"):
+		default:
+			urls = append(urls, arg)
+		}
+	}
+	if len(urls) < 2 {
+		return fmt.Errorf("gsutil %s: expected a source and a destination", command)
+	}
+
+	var sources []string
+	for _, url := range urls[:len(urls)-1] {
+		matches, err := filepath.Glob(r.localPath(url))
+		if err != nil {
+			return err
+		}
+		sources = append(sources, matches...)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("gsutil %s: no URLs matched", command)
+	}
+
+	destURL := urls[len(urls)-1]
+	dest := r.localPath(destURL)
+	destIsDir := strings.HasSuffix(destURL, "/") || len(sources) > 1
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		destIsDir = true
+	}
+	for _, src := range sources {
+		target := dest
+		if destIsDir {
+			target = filepath.Join(dest, filepath.Base(src))
+		}
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if !recursive {
+				fmt.Printf("Omitting directory %s\n", src)
+				continue
+			}
+			if err := copyLocalDir(src, target); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := copyLocalFile(src, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localPath maps gs://bucket/object URLs to the local storage directory, and
+// relative paths to the runner's working directory.
+func (r *localStorageRunner) localPath(url string) string {
+	if rest, ok := strings.CutPrefix(url, "gs://"); ok {
+		bucket, object, _ := strings.Cut(rest, "/")
+		return r.gcs.ObjectPath(bucket, object)
+	}
+	if filepath.IsAbs(url) {
+		return url
+	}
+	return filepath.Join(r.GetCWD(), url)
+}
+
+func copyLocalDir(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return copyLocalFile(path, filepath.Join(dest, rel))
+	})
+}
+
+func copyLocalFile(src, dest string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
+
+func init() {
+	// Only commands that build their clients with the functions above can run
+	// offline, so the flag isn't available to the others.
+	for _, cmd := range []*cobra.Command{
+		cassetteDiffCmd,
+		createTestFailureTicketCmd,
+		generateCommentCmd,
+		generateNightlyDashboardCmd,
+		scrubCassettesCmd,
+		testTerraformVCRCmd,
+	} {
+		cmd.Flags().StringVar(&localDir, "local-dir", "", "Run offline: read and record GitHub state in <dir>/github.json and store GCS objects in <dir>/gcs/<bucket>/<object>")
+	}
+	cobra.OnInitialize(func() {
+		if localDir == "" {
+			return
+		}
+		abs, err := filepath.Abs(localDir)
+		if err != nil {
+			log.Fatalf("error resolving --local-dir: %s", err)
+		}
+		localDir = abs
+	})
+}
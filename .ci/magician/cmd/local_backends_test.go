@@ -0,0 +1,123 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"magician/cloudstorage"
+	"magician/exec"
+	"magician/github"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorageRunnerGsutil(t *testing.T) {
+	dir := t.TempDir()
+	rnr, err := exec.NewRunner()
+	require.NoError(t, err)
+	gcs := cloudstorage.NewLocalClient(filepath.Join(dir, "gcs"))
+	r := &localStorageRunner{ExecRunner: rnr, gcs: gcs}
+
+	writeFile := func(path string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(filepath.Base(path)), 0644))
+	}
+	writeFile(gcs.ObjectPath("ci-vcr-cassettes", "fixtures/TestAccOne.yaml"))
+	writeFile(gcs.ObjectPath("ci-vcr-cassettes", "fixtures/TestAccTwo.yaml"))
+	writeFile(filepath.Join(dir, "logs", "TestAccOne_replaying_test.log"))
+	writeFile(filepath.Join(dir, "logs", "build", "output.log"))
+
+	// Fetching cassettes.
+	cassettePath := filepath.Join(dir, "cassettes")
+	require.NoError(t, os.MkdirAll(cassettePath, 0755))
+	_, err = r.Run("gsutil", []string{"-m", "-q", "cp", "gs://ci-vcr-cassettes/fixtures/*", cassettePath}, nil)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(cassettePath, "TestAccOne.yaml"))
+	assert.FileExists(t, filepath.Join(cassettePath, "TestAccTwo.yaml"))
+
+	// Uploading logs.
+	_, err = r.Run("gsutil", []string{"-h", "Content-Type:text/plain", "-m", "-q", "cp", "-r", filepath.Join(dir, "logs", "*"), "gs://ci-vcr-logs/beta/refs/heads/auto-pr-123/"}, nil)
+	require.NoError(t, err)
+	assert.FileExists(t, gcs.ObjectPath("ci-vcr-logs", "beta/refs/heads/auto-pr-123/TestAccOne_replaying_test.log"))
+	assert.FileExists(t, gcs.ObjectPath("ci-vcr-logs", "beta/refs/heads/auto-pr-123/build/output.log"))
+
+	// Uploading a single file to a new object name.
+	_, err = r.Run("gsutil", []string{"-q", "cp", filepath.Join(dir, "logs", "TestAccOne_replaying_test.log"), "gs://ci-vcr-logs/beta/build-log/replaying_test.log"}, nil)
+	require.NoError(t, err)
+	assert.FileExists(t, gcs.ObjectPath("ci-vcr-logs", "beta/build-log/replaying_test.log"))
+
+	_, err = r.Run("gsutil", []string{"-m", "-q", "cp", "gs://ci-vcr-cassettes/refs/heads/missing/fixtures/*", cassettePath}, nil)
+	assert.Error(t, err)
+	_, err = r.Run("gsutil", []string{"ls", "gs://ci-vcr-cassettes/fixtures/"}, nil)
+	assert.Error(t, err)
+}
+
+func TestCreateTestFailureTicketLocal(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	gcs := cloudstorage.NewLocalClient(filepath.Join(dir, "gcs"))
+	now := time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC)
+	for i := 0; i < TotalDays; i++ {
+		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+		object := gcs.ObjectPath(NightlyDataBucket, fmt.Sprintf("test-metadata/ga/%s-ga.json", date))
+		require.NoError(t, os.MkdirAll(filepath.Dir(object), 0755))
+		require.NoError(t, os.WriteFile(object, []byte(`[
+  {"name": "TestAccPubsubTopic_basic", "status": "FAILURE", "service": "pubsub", "error_message": "Error 503: projects/p1 is unavailable"},
+  {"name": "TestAccPubsubSubscription_basic", "status": "SUCCESS", "service": "pubsub"}
+]`), 0644))
+	}
+
+	gh, err := github.NewLocalClient(filepath.Join(dir, "github.json"))
+	require.NoError(t, err)
+	require.NoError(t, execCreateTestFailureTicket(now, gh.Issues, gcs))
+	// Running again doesn't file the same failure twice.
+	require.NoError(t, execCreateTestFailureTicket(now, gh.Issues, gcs))
+
+	issues := gh.State().Issues
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Failing test(s): TestAccPubsubTopic_basic", issues[0].GetTitle())
+	var labels []string
+	for _, label := range issues[0].Labels {
+		labels = append(labels, label.GetName())
+	}
+	assert.Contains(t, labels, "test-failure-100")
+	assert.FileExists(t, gcs.ObjectPath(NightlyDataBucket, "test-errors/ga/2025-03-10/TestAccPubsubTopic_basic-ga-2025-03-10.txt"))
+}
+
+func TestLocalDirFlag(t *testing.T) {
+	supported := map[string]bool{
+		"cassette-diff":              true,
+		"create-test-failure-ticket": true,
+		"generate-comment":           true,
+		"generate-nightly-dashboard": true,
+		"scrub-cassettes":            true,
+		"test-terraform-vcr":         true,
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if got := cmd.Flags().Lookup("local-dir") != nil; got != supported[cmd.Name()] {
+			t.Errorf("%s accepts --local-dir = %t, want %t", cmd.Name(), got, supported[cmd.Name()])
+		}
+	}
+}
@@ -89,7 +89,7 @@ func execManageTestFailureTicket(now time.Time, gh *github.Client, gcs Cloudstor
 		Labels:      []string{"test-failure", "forward/review"},
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	issues, err := ListIssuesWithOpts(ctx, gh, opts)
+	issues, err := ListIssuesWithOpts(ctx, gh.Issues, opts)
 	if err != nil {
 		return err
 	}
@@ -115,7 +115,7 @@ func execManageTestFailureTicket(now time.Time, gh *github.Client, gcs Cloudstor
 		Labels:      []string{"test-failure-100"},
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	issues, err = ListIssuesWithOpts(ctx, gh, opts)
+	issues, err = ListIssuesWithOpts(ctx, gh.Issues, opts)
 	if err != nil {
 		return err
 	}
@@ -23,6 +23,7 @@ import (
 
 type mockGithub struct {
 	pullRequest         github.PullRequest
+	pullRequests        []github.PullRequest
 	userType            github.UserType
 	requestedReviewers  []github.User
 	previousReviewers   []github.User
@@ -39,6 +40,17 @@ func (m *mockGithub) GetPullRequest(prNumber string) (github.PullRequest, error)
 
 func (m *mockGithub) GetPullRequests(state, base, sort, direction string) ([]github.PullRequest, error) {
 	m.calledMethods["GetPullRequests"] = append(m.calledMethods["GetPullRequests"], []any{state, base, sort, direction})
+	if m.pullRequests != nil {
+		return m.pullRequests, nil
+	}
+	return []github.PullRequest{m.pullRequest}, nil
+}
+
+func (m *mockGithub) GetAllPullRequests(state, base, sort, direction string) ([]github.PullRequest, error) {
+	m.calledMethods["GetAllPullRequests"] = append(m.calledMethods["GetAllPullRequests"], []any{state, base, sort, direction})
+	if m.pullRequests != nil {
+		return m.pullRequests, nil
+	}
 	return []github.PullRequest{m.pullRequest}, nil
 }
 
@@ -0,0 +1,392 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"magician/provider"
+	utils "magician/utility"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	_ "embed"
+)
+
+var (
+	//go:embed templates/NIGHTLY_DASHBOARD.html.tmpl
+	nightlyDashboardTemplate string
+)
+
+const (
+	dashboardJSONFileName = "dashboard.json"
+	dashboardHTMLFileName = "index.html"
+)
+
+var (
+	// used for flags
+	dashboardStartDate string
+	dashboardEndDate   string
+	dashboardDays      int
+	dashboardInputDir  string
+	dashboardOutputDir string
+)
+
+// Results of a test on a day in the dashboard.
+const (
+	dashboardPass = "pass"
+	dashboardFail = "fail"
+	dashboardSkip = "skip"
+)
+
+// nightlyDashboard is the aggregated nightly test status for a date range.
+type nightlyDashboard struct {
+	GeneratedAt string             `json:"generated_at"`
+	StartDate   string             `json:"start_date"`
+	EndDate     string             `json:"end_date"`
+	Dates       []string           `json:"dates"`
+	Services    []dashboardService `json:"services"`
+}
+
+// dashboardService is the status of the tests of a service package in one
+// provider version.
+type dashboardService struct {
+	Name            string          `json:"name"`
+	ProviderVersion string          `json:"provider_version"`
+	Runs            int             `json:"runs"`
+	Passed          int             `json:"passed"`
+	PassRate        float64         `json:"pass_rate"`
+	FailingTests    int             `json:"failing_tests"`
+	Tests           []dashboardTest `json:"tests"`
+}
+
+// dashboardTest is the status of a test in one provider version.
+type dashboardTest struct {
+	Name            string `json:"name"`
+	Service         string `json:"service"`
+	ProviderVersion string `json:"provider_version"`
+	// Result on each day of the dashboard, oldest first: pass, fail, skip,
+	// or empty if the test didn't run.
+	Results []string `json:"results"`
+	// Duration in milliseconds on each day of the dashboard, oldest first,
+	// or 0 if the test didn't pass or fail.
+	Durations []int `json:"durations"`
+	Runs      int   `json:"runs"`
+	Passed    int   `json:"passed"`
+	Failed    int   `json:"failed"`
+	Skipped   int   `json:"skipped"`
+	// Percentage of runs that passed. Skipped runs are left out.
+	PassRate float64 `json:"pass_rate"`
+	// Percentage change of the average duration in the second half of the
+	// runs compared to the first half.
+	DurationTrend    float64         `json:"duration_trend"`
+	FirstFailureDate string          `json:"first_failure_date,omitempty"`
+	Streak           dashboardStreak `json:"current_streak"`
+	LastErrorMessage string          `json:"last_error_message,omitempty"`
+	LastLogLink      string          `json:"last_log_link,omitempty"`
+}
+
+// dashboardStreak is the number of most recent runs of a test with the same
+// result.
+type dashboardStreak struct {
+	Result string `json:"result,omitempty"`
+	Runs   int    `json:"runs"`
+	Since  string `json:"since,omitempty"`
+}
+
+// generateNightlyDashboardCmd represents the generateNightlyDashboard command
+var generateNightlyDashboardCmd = &cobra.Command{
+	Use:   "generate-nightly-dashboard",
+	Short: "Generates a nightly test health dashboard",
+	Long: `This command generates a static dashboard of nightly test health from the test status stored by collect-nightly-test-status.
+
+	It performs the following operations:
+	1. Reads the GA and Beta nightly test status for each day from --start-date to --end-date,
+	   from GCS or from --input-dir (<dir>/<version>/<date>-<version>.json).
+	2. Aggregates the results per service, test and provider version: pass rate, duration trend,
+	   first failure date and current streak.
+	3. Writes dashboard.json and index.html to --output-dir.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loc, err := time.LoadLocation("America/Los_Angeles")
+		if err != nil {
+			return fmt.Errorf("Error loading location: %s", err)
+		}
+		now := time.Now().In(loc)
+
+		end := now
+		if dashboardEndDate != "" {
+			end, err = time.ParseInLocation("2006-01-02", dashboardEndDate, loc)
+			if err != nil {
+				return fmt.Errorf("invalid end date: %w", err)
+			}
+		}
+		start := end.AddDate(0, 0, 1-dashboardDays)
+		if dashboardStartDate != "" {
+			start, err = time.ParseInLocation("2006-01-02", dashboardStartDate, loc)
+			if err != nil {
+				return fmt.Errorf("invalid start date: %w", err)
+			}
+		}
+
+		return execGenerateNightlyDashboard(start, end, now, dashboardInputDir, dashboardOutputDir, newCloudstorageClient())
+	},
+}
+
+func execGenerateNightlyDashboard(start, end, now time.Time, inputDir, outputDir string, gcs CloudstorageClient) error {
+	if end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+	var dates []time.Time
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	testInfos := make(map[provider.Version][][]TestInfo)
+	for _, pVersion := range []provider.Version{provider.GA, provider.Beta} {
+		for _, date := range dates {
+			testInfoList, err := readNightlyTestStatus(pVersion, date, inputDir, gcs)
+			if err != nil {
+				fmt.Printf("Skipping %s test status for %s: %s\n", pVersion, date.Format("2006-01-02"), err)
+			}
+			testInfos[pVersion] = append(testInfos[pVersion], testInfoList)
+		}
+	}
+
+	dashboard := buildNightlyDashboard(dates, testInfos)
+	dashboard.GeneratedAt = now.Format(time.RFC3339)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	if err := utils.WriteToJson(dashboard, filepath.Join(outputDir, dashboardJSONFileName)); err != nil {
+		return err
+	}
+	html, err := formatNightlyDashboard(dashboard)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, dashboardHTMLFileName), []byte(html), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote nightly test dashboard for %s to %s to %s\n", dashboard.StartDate, dashboard.EndDate, outputDir)
+	return nil
+}
+
+// readNightlyTestStatus reads the test status of a day from inputDir if set,
+// or from GCS.
+func readNightlyTestStatus(pVersion provider.Version, date time.Time, inputDir string, gcs CloudstorageClient) ([]TestInfo, error) {
+	if inputDir == "" {
+		return getTestInfoList(pVersion, date, gcs)
+	}
+	var testInfoList []TestInfo
+	path := filepath.Join(inputDir, pVersion.String(), fmt.Sprintf("%s-%s.json", date.Format("2006-01-02"), pVersion.String()))
+	if err := utils.ReadFromJson(&testInfoList, path); err != nil {
+		return nil, err
+	}
+	return testInfoList, nil
+}
+
+// buildNightlyDashboard aggregates the test status of each day, which is
+// indexed like dates, by provider version, service and test.
+func buildNightlyDashboard(dates []time.Time, testInfos map[provider.Version][][]TestInfo) nightlyDashboard {
+	dashboard := nightlyDashboard{}
+	for _, date := range dates {
+		dashboard.Dates = append(dashboard.Dates, date.Format("2006-01-02"))
+	}
+	if len(dates) > 0 {
+		dashboard.StartDate = dashboard.Dates[0]
+		dashboard.EndDate = dashboard.Dates[len(dates)-1]
+	}
+
+	for _, pVersion := range []provider.Version{provider.GA, provider.Beta} {
+		tests := make(map[string]*dashboardTest)
+		for day, testInfoList := range testInfos[pVersion] {
+			for _, testInfo := range testInfoList {
+				test, ok := tests[testInfo.Name]
+				if !ok {
+					test = &dashboardTest{
+						Name:            testInfo.Name,
+						Service:         testInfo.Service,
+						ProviderVersion: pVersion.String(),
+						Results:         make([]string, len(dates)),
+						Durations:       make([]int, len(dates)),
+					}
+					tests[testInfo.Name] = test
+				}
+				test.addResult(day, testInfo)
+			}
+		}
+
+		services := make(map[string]*dashboardService)
+		for _, test := range tests {
+			test.summarize(dashboard.Dates)
+			service, ok := services[test.Service]
+			if !ok {
+				service = &dashboardService{Name: test.Service, ProviderVersion: pVersion.String()}
+				services[test.Service] = service
+			}
+			service.Tests = append(service.Tests, *test)
+			service.Runs += test.Passed + test.Failed
+			service.Passed += test.Passed
+			if test.Streak.Result == dashboardFail {
+				service.FailingTests++
+			}
+		}
+
+		var versionServices []dashboardService
+		for _, service := range services {
+			service.PassRate = passRate(service.Passed, service.Runs)
+			// Show the least healthy tests first.
+			sort.Slice(service.Tests, func(i, j int) bool {
+				if service.Tests[i].PassRate != service.Tests[j].PassRate {
+					return service.Tests[i].PassRate < service.Tests[j].PassRate
+				}
+				return service.Tests[i].Name < service.Tests[j].Name
+			})
+			versionServices = append(versionServices, *service)
+		}
+		sort.Slice(versionServices, func(i, j int) bool {
+			return versionServices[i].Name < versionServices[j].Name
+		})
+		dashboard.Services = append(dashboard.Services, versionServices...)
+	}
+	return dashboard
+}
+
+// addResult records the status of the test on a day. A test that ran more
+// than once on a day passed if any of its runs passed.
+func (t *dashboardTest) addResult(day int, testInfo TestInfo) {
+	var result string
+	switch testInfo.Status {
+	case "SUCCESS":
+		result = dashboardPass
+	case "FAILURE":
+		result = dashboardFail
+	default:
+		// Skipped tests have a status of "UNKNOWN" on TC
+		result = dashboardSkip
+	}
+	switch t.Results[day] {
+	case dashboardPass:
+		return
+	case dashboardFail:
+		if result != dashboardPass {
+			return
+		}
+	}
+	t.Results[day] = result
+	if result != dashboardSkip {
+		t.Durations[day] = testInfo.Duration
+	}
+	if result == dashboardFail {
+		t.LastErrorMessage = testInfo.ErrorMessage
+		t.LastLogLink = testInfo.LogLink
+	}
+}
+
+// summarize computes the statistics of the test from its daily results.
+func (t *dashboardTest) summarize(dates []string) {
+	var durations []int
+	for day, result := range t.Results {
+		switch result {
+		case dashboardPass:
+			t.Passed++
+		case dashboardFail:
+			t.Failed++
+			if t.FirstFailureDate == "" {
+				t.FirstFailureDate = dates[day]
+			}
+		case dashboardSkip:
+			// Skipped runs don't count towards the pass rate and don't
+			// break a streak.
+			t.Skipped++
+			continue
+		default:
+			continue
+		}
+		durations = append(durations, t.Durations[day])
+		if result != t.Streak.Result {
+			t.Streak = dashboardStreak{Result: result, Since: dates[day]}
+		}
+		t.Streak.Runs++
+	}
+	t.Runs = t.Passed + t.Failed + t.Skipped
+	t.PassRate = passRate(t.Passed, t.Passed+t.Failed)
+	t.DurationTrend = durationTrend(durations)
+	if t.Failed == 0 {
+		t.LastErrorMessage = ""
+		t.LastLogLink = ""
+	}
+}
+
+// passRate returns the percentage of runs that passed, or 0 if there were no
+// runs.
+func passRate(passed, runs int) float64 {
+	if runs == 0 {
+		return 0
+	}
+	return float64(passed) * 100 / float64(runs)
+}
+
+// durationTrend returns the percentage change of the average duration in the
+// second half of durations compared to the first half. The middle value of an
+// odd number of durations is left out.
+func durationTrend(durations []int) float64 {
+	half := len(durations) / 2
+	if half == 0 {
+		return 0
+	}
+	before, after := 0, 0
+	for i := 0; i < half; i++ {
+		before += durations[i]
+		after += durations[len(durations)-half+i]
+	}
+	if before == 0 {
+		return 0
+	}
+	return float64(after-before) * 100 / float64(before)
+}
+
+func formatNightlyDashboard(dashboard nightlyDashboard) (string, error) {
+	tmpl, err := template.New("NIGHTLY_DASHBOARD.html.tmpl").Funcs(template.FuncMap{
+		"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+		"trend":   func(f float64) string { return fmt.Sprintf("%+.0f%%", f) },
+	}).Parse(nightlyDashboardTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse NIGHTLY_DASHBOARD.html.tmpl: %s", err))
+	}
+	sb := new(strings.Builder)
+	if err := tmpl.Execute(sb, dashboard); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(generateNightlyDashboardCmd)
+	generateNightlyDashboardCmd.Flags().StringVar(&dashboardStartDate, "start-date", "", "First day of the dashboard in YYYY-MM-DD format (default: --days before --end-date)")
+	generateNightlyDashboardCmd.Flags().StringVar(&dashboardEndDate, "end-date", "", "Last day of the dashboard in YYYY-MM-DD format (default: today)")
+	generateNightlyDashboardCmd.Flags().IntVar(&dashboardDays, "days", 30, "Number of days shown when --start-date is not set")
+	generateNightlyDashboardCmd.Flags().StringVar(&dashboardInputDir, "input-dir", "", "Read the nightly test status from <dir>/<version>/<date>-<version>.json instead of GCS")
+	generateNightlyDashboardCmd.Flags().StringVar(&dashboardOutputDir, "output-dir", "nightly-dashboard", "Directory the dashboard is written to")
+}
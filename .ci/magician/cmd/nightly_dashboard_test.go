@@ -0,0 +1,267 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"magician/cloudstorage"
+	"magician/provider"
+	utils "magician/utility"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildNightlyDashboard(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+	testInfos := map[provider.Version][][]TestInfo{
+		provider.GA: {
+			{
+				{Name: "TestAccBroken", Service: "compute", Status: "SUCCESS", Duration: 100},
+				{Name: "TestAccStable", Service: "compute", Status: "SUCCESS", Duration: 100},
+				{Name: "TestAccBucket", Service: "storage", Status: "SUCCESS", Duration: 10},
+			},
+			{
+				{Name: "TestAccBroken", Service: "compute", Status: "FAILURE", Duration: 100, ErrorMessage: "old error", LogLink: "old-link"},
+				{Name: "TestAccStable", Service: "compute", Status: "SUCCESS", Duration: 100},
+				// Retried after failing
+				{Name: "TestAccBucket", Service: "storage", Status: "FAILURE", Duration: 10},
+				{Name: "TestAccBucket", Service: "storage", Status: "SUCCESS", Duration: 20},
+			},
+			// Missing day
+			nil,
+			{
+				{Name: "TestAccBroken", Service: "compute", Status: "FAILURE", Duration: 300, ErrorMessage: "new error", LogLink: "new-link"},
+				{Name: "TestAccStable", Service: "compute", Status: "UNKNOWN"},
+				{Name: "TestAccBucket", Service: "storage", Status: "SUCCESS", Duration: 30},
+			},
+		},
+		provider.Beta: {
+			{{Name: "TestAccBroken", Service: "compute", Status: "FAILURE", Duration: 100}},
+		},
+	}
+	want := nightlyDashboard{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-04",
+		Dates:     []string{"2025-03-01", "2025-03-02", "2025-03-03", "2025-03-04"},
+		Services: []dashboardService{
+			{
+				Name:            "compute",
+				ProviderVersion: "ga",
+				Runs:            5,
+				Passed:          3,
+				PassRate:        60,
+				FailingTests:    1,
+				Tests: []dashboardTest{
+					{
+						Name:             "TestAccBroken",
+						Service:          "compute",
+						ProviderVersion:  "ga",
+						Results:          []string{"pass", "fail", "", "fail"},
+						Durations:        []int{100, 100, 0, 300},
+						Runs:             3,
+						Passed:           1,
+						Failed:           2,
+						PassRate:         100.0 / 3,
+						DurationTrend:    200,
+						FirstFailureDate: "2025-03-02",
+						Streak:           dashboardStreak{Result: "fail", Runs: 2, Since: "2025-03-02"},
+						LastErrorMessage: "new error",
+						LastLogLink:      "new-link",
+					},
+					{
+						Name:            "TestAccStable",
+						Service:         "compute",
+						ProviderVersion: "ga",
+						Results:         []string{"pass", "pass", "", "skip"},
+						Durations:       []int{100, 100, 0, 0},
+						Runs:            3,
+						Passed:          2,
+						Skipped:         1,
+						PassRate:        100,
+						Streak:          dashboardStreak{Result: "pass", Runs: 2, Since: "2025-03-01"},
+					},
+				},
+			},
+			{
+				Name:            "storage",
+				ProviderVersion: "ga",
+				Runs:            3,
+				Passed:          3,
+				PassRate:        100,
+				Tests: []dashboardTest{
+					{
+						Name:            "TestAccBucket",
+						Service:         "storage",
+						ProviderVersion: "ga",
+						Results:         []string{"pass", "pass", "", "pass"},
+						Durations:       []int{10, 20, 0, 30},
+						Runs:            3,
+						Passed:          3,
+						PassRate:        100,
+						DurationTrend:   200,
+						Streak:          dashboardStreak{Result: "pass", Runs: 3, Since: "2025-03-01"},
+					},
+				},
+			},
+			{
+				Name:            "compute",
+				ProviderVersion: "beta",
+				Runs:            1,
+				FailingTests:    1,
+				Tests: []dashboardTest{
+					{
+						Name:             "TestAccBroken",
+						Service:          "compute",
+						ProviderVersion:  "beta",
+						Results:          []string{"fail", "", "", ""},
+						Durations:        []int{100, 0, 0, 0},
+						Runs:             1,
+						Failed:           1,
+						FirstFailureDate: "2025-03-01",
+						Streak:           dashboardStreak{Result: "fail", Runs: 1, Since: "2025-03-01"},
+					},
+				},
+			},
+		},
+	}
+	got := buildNightlyDashboard(dates, testInfos)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("buildNightlyDashboard() returned unexpected difference (-want +got):\n%s", diff)
+	}
+}
+
+func TestDurationTrend(t *testing.T) {
+	cases := map[string]struct {
+		durations []int
+		want      float64
+	}{
+		"no runs": {},
+		"one run": {
+			durations: []int{100},
+		},
+		"slower": {
+			durations: []int{100, 100, 150, 150},
+			want:      50,
+		},
+		"faster, middle run left out": {
+			durations: []int{200, 1000, 100},
+			want:      -50,
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			if got := durationTrend(tc.durations); got != tc.want {
+				t.Errorf("durationTrend(%v) = %v, want %v", tc.durations, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecGenerateNightlyDashboard(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "dashboard")
+	if err := os.MkdirAll(filepath.Join(inputDir, "ga"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	testInfos := []TestInfo{
+		{Name: "TestAccBroken", Service: "compute", Status: "FAILURE", ErrorMessage: "<error>", LogLink: "https://example.com/log"},
+	}
+	if err := utils.WriteToJson(testInfos, filepath.Join(inputDir, "ga", "2025-03-02-ga.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	if err := execGenerateNightlyDashboard(start, end, end, inputDir, outputDir, nil); err != nil {
+		t.Fatalf("execGenerateNightlyDashboard() returned error: %s", err)
+	}
+
+	var dashboard nightlyDashboard
+	if err := utils.ReadFromJson(&dashboard, filepath.Join(outputDir, "dashboard.json")); err != nil {
+		t.Fatal(err)
+	}
+	if len(dashboard.Services) != 1 || dashboard.Services[0].Tests[0].Streak.Result != "fail" {
+		t.Errorf("dashboard.json has unexpected services: %+v", dashboard.Services)
+	}
+	html, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<td>TestAccBroken</td>`,
+		`title="2025-03-01: not run"`,
+		`title="2025-03-02: fail"`,
+		`<a href="https://example.com/log" title="&lt;error&gt;">debug log</a>`,
+	} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("index.html doesn't contain %q:\n%s", want, html)
+		}
+	}
+
+	if err := execGenerateNightlyDashboard(end, start, end, inputDir, outputDir, nil); err == nil {
+		t.Error("execGenerateNightlyDashboard() with end before start returned no error")
+	}
+}
+
+func TestExecGenerateNightlyDashboardFromGCS(t *testing.T) {
+	// The dashboard step runs in parallel with other steps that read the same
+	// objects, so it must not download them to the shared working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := t.TempDir()
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	gcs := cloudstorage.NewLocalClient(t.TempDir())
+	object := gcs.ObjectPath(NightlyDataBucket, "test-metadata/ga/2025-03-02-ga.json")
+	if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+		t.Fatal(err)
+	}
+	testInfos := []TestInfo{{Name: "TestAccBroken", Service: "compute", Status: "FAILURE"}}
+	if err := utils.WriteToJson(testInfos, object); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "dashboard")
+	day := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	if err := execGenerateNightlyDashboard(day, day, day, "", outputDir, gcs); err != nil {
+		t.Fatalf("execGenerateNightlyDashboard() returned error: %s", err)
+	}
+
+	var dashboard nightlyDashboard
+	if err := utils.ReadFromJson(&dashboard, filepath.Join(outputDir, "dashboard.json")); err != nil {
+		t.Fatal(err)
+	}
+	if len(dashboard.Services) != 1 || dashboard.Services[0].Tests[0].Name != "TestAccBroken" {
+		t.Errorf("dashboard.json has unexpected services: %+v", dashboard.Services)
+	}
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("execGenerateNightlyDashboard() left %s in the working directory", entry.Name())
+	}
+}
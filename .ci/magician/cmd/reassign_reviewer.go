@@ -27,7 +27,7 @@ import (
 // reassignReviewerCmd represents the reassignReviewer command
 var reassignReviewerCmd = &cobra.Command{
 	Use:   "reassign-reviewer PR_NUMBER [REVIEWER]",
-	Short: "Reassigns primary reviewer to the given reviewer or a random reviewer if none given",
+	Short: "Reassigns primary reviewer to the given reviewer or an available reviewer if none given",
 	Long: `This command reassigns reviewers when invoked via a comment on a pull request.
 
 	The command expects the following PR details as arguments:
@@ -75,8 +75,11 @@ func execReassignReviewer(prNumber, newPrimaryReviewer string, gh GithubClient)
 	}
 
 	reviewerComment, currentReviewer := github.FindReviewerComment(comments)
+	assignment := github.ReviewerAssignment{Reviewer: newPrimaryReviewer}
 	if newPrimaryReviewer == "" {
-		newPrimaryReviewer = github.GetRandomReviewer([]string{currentReviewer, pullRequest.User.Login})
+		candidates := github.AvailableReviewers([]string{currentReviewer, pullRequest.User.Login})
+		assignment = github.ChooseReviewer(candidates, reviewerWorkload(gh, pullRequest))
+		newPrimaryReviewer = assignment.Reviewer
 	}
 
 	if newPrimaryReviewer == "" {
@@ -87,7 +90,7 @@ func execReassignReviewer(prNumber, newPrimaryReviewer string, gh GithubClient)
 	}
 
 	fmt.Println("New primary reviewer is ", newPrimaryReviewer)
-	comment := github.FormatReviewerComment(newPrimaryReviewer)
+	comment := github.FormatReviewerComment(assignment)
 
 	if currentReviewer == "" {
 		fmt.Println("No reviewer comment found, creating one")
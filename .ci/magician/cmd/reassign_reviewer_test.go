@@ -44,7 +44,7 @@ func TestExecReassignReviewer(t *testing.T) {
 		"reassign from bob to random reviewer": {
 			comments: []github.PullRequestComment{
 				{
-					Body: github.FormatReviewerComment("bob"),
+					Body: github.FormatReviewerComment(github.ReviewerAssignment{Reviewer: "bob"}),
 					ID:   1234,
 				},
 			},
@@ -54,7 +54,7 @@ func TestExecReassignReviewer(t *testing.T) {
 			newPrimaryReviewer: "alice",
 			comments: []github.PullRequestComment{
 				{
-					Body: github.FormatReviewerComment("bob"),
+					Body: github.FormatReviewerComment(github.ReviewerAssignment{Reviewer: "bob"}),
 					ID:   1234,
 				},
 			},
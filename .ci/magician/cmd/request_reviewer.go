@@ -36,7 +36,8 @@ var requestReviewerCmd = &cobra.Command{
 	1. Determines the author of the pull request
 	2. If the author is not a core contributor:
 			a. Identifies the initially requested reviewer and those who previously reviewed this PR.
-			b. Determines and requests reviewers based on the above.
+			b. Determines and requests reviewers based on the above. New primary reviewers are chosen based on
+			   their open review requests, reviewer_availability.yaml and their reviews of the PR's services.
 			c. As appropriate, posts a welcome comment on the PR.
 	`,
 	Args: cobra.ExactArgs(1),
@@ -72,7 +73,8 @@ func execRequestReviewer(prNumber string, gh GithubClient) error {
 			return err
 		}
 
-		reviewersToRequest, newPrimaryReviewer := github.ChooseCoreReviewers(requestedReviewers, previousReviewers)
+		workload := reviewerWorkload(gh, pullRequest)
+		reviewersToRequest, newPrimaryReviewer := github.ChooseCoreReviewers(requestedReviewers, previousReviewers, workload)
 
 		if len(reviewersToRequest) > 0 {
 			err = gh.RequestPullRequestReviewers(prNumber, reviewersToRequest)
@@ -81,7 +83,7 @@ func execRequestReviewer(prNumber string, gh GithubClient) error {
 			}
 		}
 
-		if newPrimaryReviewer != "" {
+		if newPrimaryReviewer.Reviewer != "" {
 			comment := github.FormatReviewerComment(newPrimaryReviewer)
 			err = gh.PostComment(prNumber, comment)
 			if err != nil {
@@ -92,6 +94,17 @@ func execRequestReviewer(prNumber string, gh GithubClient) error {
 	return nil
 }
 
+// reviewerWorkload returns the current load and history of reviewers for
+// the service labels of the pull request. Reviewers are chosen based on an
+// empty workload if it can't be fetched.
+func reviewerWorkload(gh GithubClient, pullRequest github.PullRequest) github.ReviewerWorkload {
+	workload, err := github.GetReviewerWorkload(gh, github.ServiceLabels(pullRequest))
+	if err != nil {
+		fmt.Printf("Error getting reviewer workload, choosing reviewers without it: %s\n", err)
+	}
+	return workload
+}
+
 func init() {
 	rootCmd.AddCommand(requestReviewerCmd)
 }
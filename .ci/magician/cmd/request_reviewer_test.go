@@ -28,8 +28,14 @@ func TestExecRequestReviewer(t *testing.T) {
 	if len(availableReviewers) < 3 {
 		t.Fatalf("not enough available reviewers (%v) to run TestExecRequestReviewer (need at least 3)", availableReviewers)
 	}
+	// Every available reviewer but the last one has an open review request.
+	var openPullRequests []github.PullRequest
+	for _, reviewer := range availableReviewers[:len(availableReviewers)-1] {
+		openPullRequests = append(openPullRequests, github.PullRequest{RequestedReviewers: []github.User{{Login: reviewer}}})
+	}
 	cases := map[string]struct {
 		pullRequest             github.PullRequest
+		pullRequests            []github.PullRequest
 		requestedReviewers      []string
 		previousReviewers       []string
 		teamMembers             map[string][]string
@@ -55,6 +61,13 @@ func TestExecRequestReviewer(t *testing.T) {
 			},
 			expectReviewersFromList: availableReviewers,
 		},
+		"non-core-contributor author gets the least busy reviewer": {
+			pullRequest: github.PullRequest{
+				User: github.User{Login: "author"},
+			},
+			pullRequests:            openPullRequests,
+			expectSpecificReviewers: []string{availableReviewers[len(availableReviewers)-1]},
+		},
 		"non-core-contributor author doesn't get a new reviewer (but does get re-request) with previous reviewers": {
 			pullRequest: github.PullRequest{
 				User: github.User{Login: "author"},
@@ -82,6 +95,7 @@ func TestExecRequestReviewer(t *testing.T) {
 			}
 			gh := &mockGithub{
 				pullRequest:        tc.pullRequest,
+				pullRequests:       tc.pullRequests,
 				requestedReviewers: requestedReviewers,
 				previousReviewers:  previousReviewers,
 				calledMethods:      make(map[string][][]any),
@@ -18,7 +18,6 @@ package cmd
 import (
 	"fmt"
 	"magician/github"
-	"math/rand"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/issue-labeler/labeler"
@@ -33,6 +32,9 @@ var requestServiceReviewersCmd = &cobra.Command{
 	Long: `This command requests (or re-requests) review based on the PR's service labels.
 
 	If a PR has more than 3 service labels, the command will not do anything.
+
+	New reviewers are chosen from the service team based on their open review requests,
+	reviewer_availability.yaml and their reviews of the PR's services.
 	`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -94,7 +96,7 @@ func execRequestServiceReviewers(prNumber string, gh GithubClient, enrolledTeams
 	}
 
 	// For each service team, check if one of the team members is already a reviewer. Rerequest
-	// review if there is and choose a random reviewer from the list if there isn't.
+	// review if there is and choose a reviewer from the team based on workload if there isn't.
 	reviewersToRequest := []string{}
 	requestedReviewersSet := make(map[string]struct{})
 	for _, reviewer := range requestedReviewers {
@@ -106,6 +108,7 @@ func execRequestServiceReviewers(prNumber string, gh GithubClient, enrolledTeams
 		previousReviewersSet[reviewer.Login] = struct{}{}
 	}
 
+	var workload *github.ReviewerWorkload
 	exitCode := 0
 	for githubTeam := range githubTeamsSet {
 		members, err := gh.GetTeamMembers("GoogleCloudPlatform", githubTeam)
@@ -134,7 +137,17 @@ func execRequestServiceReviewers(prNumber string, gh GithubClient, enrolledTeams
 		}
 
 		if !hasReviewer && len(reviewerPool) > 0 {
-			reviewersToRequest = append(reviewersToRequest, reviewerPool[rand.Intn(len(reviewerPool))])
+			if workload == nil {
+				w := reviewerWorkload(gh, pullRequest)
+				workload = &w
+			}
+			assignment := github.ChooseReviewer(reviewerPool, *workload)
+			if assignment.Reviewer == "" {
+				fmt.Printf("No available reviewers in GoogleCloudPlatform/%s\n", githubTeam)
+				continue
+			}
+			fmt.Printf("Chose %s from %d available members of GoogleCloudPlatform/%s (%d open review requests, reviewed %v)\n", assignment.Reviewer, assignment.Candidates, githubTeam, assignment.OpenReviews, assignment.ServiceLabels)
+			reviewersToRequest = append(reviewersToRequest, assignment.Reviewer)
 		}
 	}
 
@@ -0,0 +1,109 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"magician/vcr"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// used for flags
+	scrubRulesPath     string
+	scrubCheck         bool
+	scrubProjectNumber string
+)
+
+// scrubCassettesCmd represents the scrubCassettes command
+var scrubCassettesCmd = &cobra.Command{
+	Use:   "scrub-cassettes DIR",
+	Short: "Redacts secrets from VCR cassettes",
+	Long: `This command redacts secrets from every cassette in DIR and rewrites the cassettes that changed.
+
+	It redacts:
+	1. The values of credential headers, such as Authorization and Set-Cookie.
+	2. Response body fields that hold secrets, such as service account private keys and OAuth tokens.
+	3. Matches of secret patterns, such as OAuth access tokens and PEM private keys.
+	4. The test project number, which is replaced with a placeholder that the provider
+	   swaps back when replaying.
+
+	--rules extends the default rules with a JSON file of the form
+	{"headers": [...], "body_paths": [...], "patterns": [...]}. The provider's recorder reads
+	the same file from VCR_SCRUB_RULES.
+
+	With --check, cassettes are not changed and the command fails if any secrets are found.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rnr, err := newRunner()
+		if err != nil {
+			return fmt.Errorf("error creating a runner: %w", err)
+		}
+		rules := vcr.DefaultScrubRules
+		if scrubRulesPath != "" {
+			if rules, err = vcr.LoadScrubRules(scrubRulesPath); err != nil {
+				return fmt.Errorf("error loading scrub rules: %w", err)
+			}
+		}
+		return execScrubCassettes(args[0], rules, scrubProjectNumber, scrubCheck, rnr)
+	},
+}
+
+func execScrubCassettes(dir string, rules vcr.ScrubRules, projectNumber string, check bool, rnr ExecRunner) error {
+	s, err := vcr.NewScrubber(rules, projectNumber)
+	if err != nil {
+		return fmt.Errorf("error compiling scrub rules: %w", err)
+	}
+	findings, err := vcr.ScrubCassetteDir(rnr, dir, s, !check)
+	if err != nil {
+		return fmt.Errorf("error scrubbing cassettes: %w", err)
+	}
+	if len(findings) == 0 {
+		fmt.Println("No secrets found")
+		return nil
+	}
+	printScrubFindings(findings)
+	if check {
+		return fmt.Errorf("found unredacted secrets in %d cassettes", len(findings))
+	}
+	fmt.Printf("Scrubbed %d cassettes\n", len(findings))
+	return nil
+}
+
+func printScrubFindings(findings map[string][]vcr.Finding) {
+	names := make([]string, 0, len(findings))
+	for name := range findings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(name)
+		for _, f := range findings[name] {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+}
+
+func init() {
+	scrubCassettesCmd.Flags().StringVar(&scrubRulesPath, "rules", "", "JSON file with scrub rules to add to the defaults")
+	scrubCassettesCmd.Flags().BoolVar(&scrubCheck, "check", false, "Only report secrets, and fail if any are found")
+	scrubCassettesCmd.Flags().StringVar(&scrubProjectNumber, "project-number", os.Getenv("GOOGLE_PROJECT_NUMBER"), "Project number to replace with a placeholder")
+	rootCmd.AddCommand(scrubCassettesCmd)
+}
@@ -0,0 +1,72 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"magician/exec"
+	"magician/vcr"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecScrubCassettes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "TestAccServiceAccountKey_basic.yaml")
+	cassette := `---
+version: 1
+interactions:
+- request:
+    body: ""
+    form: {}
+    headers:
+      Authorization:
+      - Bearer ya29.secret
+    url: https://iam.googleapis.com/v1/projects/123456789012/serviceAccounts/sa/keys?alt=json
+    method: GET
+  response:
+    body: '{"keys":[{"privateKeyData":"c2VjcmV0"}]}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+`
+	if err := os.WriteFile(path, []byte(cassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rnr, err := exec.NewRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := execScrubCassettes(dir, vcr.DefaultScrubRules, "123456789012", true, rnr); err == nil {
+		t.Errorf("execScrubCassettes() in check mode didn't fail on a cassette with secrets")
+	}
+	if err := execScrubCassettes(dir, vcr.DefaultScrubRules, "123456789012", false, rnr); err != nil {
+		t.Fatalf("execScrubCassettes() error: %s", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ya29.secret") || strings.Contains(string(data), "c2VjcmV0") {
+		t.Errorf("execScrubCassettes() left secrets in the cassette:\n%s", data)
+	}
+	if err := execScrubCassettes(dir, vcr.DefaultScrubRules, "123456789012", true, rnr); err != nil {
+		t.Errorf("execScrubCassettes() in check mode failed on a scrubbed cassette: %s", err)
+	}
+}
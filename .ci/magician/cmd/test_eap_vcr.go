@@ -206,6 +206,7 @@ func execTestEAPVCR(changeNumber, genPath, kokoroArtifactsDir, modifiedFilePath
 			Version:  provider.Private,
 			TestDirs: testDirs,
 			Tests:    replayingResult.FailedTests,
+			Packages: replayingResult.TestPackages(),
 		})
 
 		if recordingErr != nil {
@@ -238,6 +239,7 @@ func execTestEAPVCR(changeNumber, genPath, kokoroArtifactsDir, modifiedFilePath
 				Version:  provider.Private,
 				TestDirs: testDirs,
 				Tests:    recordingResult.PassedTests,
+				Packages: recordingResult.TestPackages(),
 			})
 			if err := vt.UploadLogs(vcr.UploadLogsOptions{
 				Head:           head,
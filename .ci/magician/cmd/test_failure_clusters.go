@@ -0,0 +1,202 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"magician/provider"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// Maximum length of the normalized error message shown in tickets.
+const maxSignatureMessageLength = 500
+
+// Replacements applied in order to error messages, so that failures with the
+// same root cause in different tests and runs get the same signature.
+var errorMessageNormalizers = []struct {
+	regexp      *regexp.Regexp
+	replacement string
+}{
+	// go test progress lines, which only repeat the test name.
+	{regexp.MustCompile(`(?:===|---) (?:RUN|PAUSE|CONT|NAME|PASS|FAIL|SKIP):? +TestAcc\S*(?: \([0-9.]+s\))?`), ""},
+	{regexp.MustCompile(`\bTestAcc\w+`), "<test>"},
+	// Source locations, such as resource_foo_test.go:123:
+	{regexp.MustCompile(`\b[\w.-]+\.go:\d+:?`), ""},
+	{regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<timestamp>"},
+	{regexp.MustCompile(`\boperations/[^\s"',):]+`), "operations/<operation>"},
+	{regexp.MustCompile(`\bprojects/[^/\s"',)]+`), "projects/<project>"},
+	{regexp.MustCompile(`\btf-test[\w-]*`), "tf-test-<random>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{5,}\b`), "<number>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8,}\b`), "<id>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+var clusterSignatureRegexp = regexp.MustCompile(`<!-- failure-signature: ([0-9a-f]+) -->`)
+
+// testFailureCluster is a group of failing tests whose error messages have the
+// same normalized form, and likely the same root cause.
+type testFailureCluster struct {
+	Signature         string
+	NormalizedMessage string
+	Failures          []*testFailure
+}
+
+// normalizeErrorMessage strips the parts of an error message that differ
+// between runs and tests, such as random resource name suffixes, project IDs,
+// operation names and timestamps.
+func normalizeErrorMessage(message string) string {
+	for _, n := range errorMessageNormalizers {
+		message = n.regexp.ReplaceAllString(message, n.replacement)
+	}
+	return strings.TrimSpace(message)
+}
+
+// clusterTestFailures groups failures by the signature of their normalized
+// error message. Failures without an error message are never grouped.
+func clusterTestFailures(failures []*testFailure) []*testFailureCluster {
+	clusters := make(map[string]*testFailureCluster)
+	for _, failure := range failures {
+		message := failure.ErrorMessages[provider.GA]
+		if message == "" {
+			message = failure.ErrorMessages[provider.Beta]
+		}
+		normalized := normalizeErrorMessage(message)
+		key := normalized
+		if normalized == "" {
+			key = failure.TestName
+		}
+		sum := sha256.Sum256([]byte(key))
+		signature := hex.EncodeToString(sum[:])[:16]
+		cluster, ok := clusters[signature]
+		if !ok {
+			if len(normalized) > maxSignatureMessageLength {
+				normalized = normalized[:maxSignatureMessageLength] + "..."
+			}
+			cluster = &testFailureCluster{
+				Signature:         signature,
+				NormalizedMessage: normalized,
+			}
+			clusters[signature] = cluster
+		}
+		cluster.Failures = append(cluster.Failures, failure)
+	}
+
+	result := make([]*testFailureCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		sort.Slice(cluster.Failures, func(i, j int) bool {
+			return cluster.Failures[i].TestName < cluster.Failures[j].TestName
+		})
+		result = append(result, cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Failures[0].TestName < result[j].Failures[0].TestName
+	})
+	return result
+}
+
+// TestNames returns the names of the tests in the cluster.
+func (c *testFailureCluster) TestNames() []string {
+	names := make([]string, 0, len(c.Failures))
+	for _, failure := range c.Failures {
+		names = append(names, failure.TestName)
+	}
+	return names
+}
+
+// Services returns the sorted service packages of the tests in the cluster.
+func (c *testFailureCluster) Services() []string {
+	return c.uniqueSorted(func(f *testFailure) string { return f.Service })
+}
+
+// AffectedResources returns the sorted resources of the tests in the cluster.
+func (c *testFailureCluster) AffectedResources() []string {
+	return c.uniqueSorted(func(f *testFailure) string { return f.AffectedResource })
+}
+
+func (c *testFailureCluster) uniqueSorted(field func(*testFailure) string) []string {
+	set := make(map[string]struct{})
+	for _, failure := range c.Failures {
+		if v := field(failure); v != "" {
+			set[v] = struct{}{}
+		}
+	}
+	values := make([]string, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// FailureRateLabel returns the highest failure rate label of the tests in the
+// cluster.
+func (c *testFailureCluster) FailureRateLabel() testFailureRateLabel {
+	label := testFailureNone
+	for _, failure := range c.Failures {
+		for _, l := range failure.FailureRateLabels {
+			if l > label {
+				label = l
+			}
+		}
+	}
+	return label
+}
+
+// Title returns the title of the ticket for the cluster.
+func (c *testFailureCluster) Title() string {
+	if len(c.Failures) == 1 {
+		return "Failing test(s): " + c.Failures[0].TestName
+	}
+	services := c.Services()
+	if len(services) == 0 {
+		return fmt.Sprintf("Failing test(s): %s and %d more", c.Failures[0].TestName, len(c.Failures)-1)
+	}
+	return fmt.Sprintf("Failing test(s): %d tests in %s", len(c.Failures), strings.Join(services, ", "))
+}
+
+// clusterIssuesBySignature returns the open test failure issues that were
+// created for a cluster, keyed by cluster signature.
+func clusterIssuesBySignature(issues []*github.Issue) map[string]*github.Issue {
+	bySignature := make(map[string]*github.Issue)
+	for _, issue := range issues {
+		if match := clusterSignatureRegexp.FindStringSubmatch(issue.GetBody()); match != nil {
+			bySignature[match[1]] = issue
+		}
+	}
+	return bySignature
+}
+
+// addTestsToIssueBody adds tests to the impacted tests of an existing ticket,
+// so that they are tracked (and closed) along with the others.
+func addTestsToIssueBody(body string, tests []string) string {
+	var lines strings.Builder
+	for _, test := range tests {
+		lines.WriteString("- " + test + "\n")
+	}
+	const header = "### Impacted tests\n\n"
+	if i := strings.Index(body, header); i != -1 {
+		i += len(header)
+		return body[:i] + lines.String() + body[i:]
+	}
+	return header + lines.String() + "\n" + body
+}
@@ -0,0 +1,134 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"magician/provider"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeErrorMessage(t *testing.T) {
+	cases := map[string]struct {
+		message string
+		want    string
+	}{
+		"random suffix and project": {
+			message: "=== RUN TestAccComputeInstance_basic === PAUSE TestAccComputeInstance_basic === CONT TestAccComputeInstance_basic resource_compute_instance_test.go:123: Step 1/2 error: Error creating Instance: googleapi: Error 403: Permission denied on resource project tf-test-abc123xyz. --- FAIL: TestAccComputeInstance_basic (12.34s)",
+			want:    "Step 1/2 error: Error creating Instance: googleapi: Error 403: Permission denied on resource project tf-test-<random>.",
+		},
+		"operation and timestamp": {
+			message: "2025-01-21T08:06:22.123Z Error waiting for operation projects/my-project-123/locations/us-central1/operations/operation-1737446782-62c2f7e: timeout",
+			want:    "<timestamp> Error waiting for operation projects/<project>/locations/us-central1/operations/<operation>: timeout",
+		},
+		"ids and numbers": {
+			message: "Error 409: resource 1234567890 conflicts with 0f3c2a9b4d5e6f70 (request 123e4567-e89b-12d3-a456-426614174000)",
+			want:    "Error 409: resource <number> conflicts with <id> (request <uuid>)",
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.want, normalizeErrorMessage(tc.message))
+		})
+	}
+}
+
+func TestClusterTestFailures(t *testing.T) {
+	failure := func(name, service, gaMessage, betaMessage string) *testFailure {
+		return &testFailure{
+			TestName:          name,
+			AffectedResource:  convertTestNameToResource(name),
+			Service:           service,
+			ErrorMessages:     map[provider.Version]string{provider.GA: gaMessage, provider.Beta: betaMessage},
+			FailureRateLabels: map[provider.Version]testFailureRateLabel{provider.GA: testFailure50, provider.Beta: testFailure100},
+		}
+	}
+	failures := []*testFailure{
+		failure("TestAccPubsubTopic_basic", "pubsub", "Error 503: projects/p1 is unavailable", ""),
+		failure("TestAccComputeDisk_basic", "compute", "", "Error 503: projects/p2 is unavailable"),
+		failure("TestAccComputeInstance_basic", "compute", "Error 400: bad machine type", ""),
+		failure("TestAccComputeNetwork_basic", "compute", "", ""),
+		failure("TestAccComputeRoute_basic", "compute", "", ""),
+	}
+	clusters := clusterTestFailures(failures)
+
+	var got [][]string
+	for _, cluster := range clusters {
+		got = append(got, cluster.TestNames())
+	}
+	assert.Equal(t, [][]string{
+		{"TestAccComputeDisk_basic", "TestAccPubsubTopic_basic"},
+		{"TestAccComputeInstance_basic"},
+		{"TestAccComputeNetwork_basic"},
+		{"TestAccComputeRoute_basic"},
+	}, got)
+
+	outage := clusters[0]
+	assert.Equal(t, "Error 503: projects/<project> is unavailable", outage.NormalizedMessage)
+	assert.Equal(t, []string{"compute", "pubsub"}, outage.Services())
+	assert.Equal(t, []string{"google_compute_disk", "google_pubsub_topic"}, outage.AffectedResources())
+	assert.Equal(t, testFailure100, outage.FailureRateLabel())
+	assert.Equal(t, "Failing test(s): 2 tests in compute, pubsub", outage.Title())
+	assert.Equal(t, "Failing test(s): TestAccComputeInstance_basic", clusters[1].Title())
+}
+
+func TestClusterTicketRoundTrip(t *testing.T) {
+	cluster := clusterTestFailures([]*testFailure{
+		{
+			TestName:          "TestAccPubsubTopic_basic",
+			AffectedResource:  "google_pubsub_topic",
+			Service:           "pubsub",
+			ErrorMessages:     map[provider.Version]string{provider.GA: "Error 503: unavailable"},
+			ErrorMessageLinks: map[provider.Version]string{provider.GA: "https://example.com/ga.txt", provider.Beta: ""},
+			DebugLogLinks:     map[provider.Version]string{provider.GA: "https://example.com/ga-debug.txt", provider.Beta: ""},
+			FailureRates:      map[provider.Version]string{provider.GA: "100%", provider.Beta: "N/A"},
+		},
+		{
+			TestName:      "TestAccPubsubSubscription_basic",
+			Service:       "pubsub",
+			ErrorMessages: map[provider.Version]string{provider.GA: "Error 503: unavailable"},
+		},
+	})[0]
+
+	body, err := formatIssueBody(cluster)
+	if err != nil {
+		t.Fatalf("formatIssueBody() error: %s", err)
+	}
+	for _, want := range []string{
+		"Error 503: unavailable",
+		"- [TestAccPubsubTopic_basic ga error message](https://example.com/ga.txt)",
+		"- [TestAccPubsubTopic_basic ga debug log](https://example.com/ga-debug.txt)",
+		"<!-- failure-signature: " + cluster.Signature + " -->",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("formatIssueBody() = %q, want it to contain %q", body, want)
+		}
+	}
+
+	issue := &github.Issue{Number: github.Int(1), Body: github.String(body)}
+	tests, err := testNamesFromIssue(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TestAccPubsubSubscription_basic", "TestAccPubsubTopic_basic"}, tests)
+	assert.Equal(t, map[string]*github.Issue{cluster.Signature: issue}, clusterIssuesBySignature([]*github.Issue{issue, {Body: github.String("### Impacted tests\n\nTestAccOther\n")}}))
+
+	issue.Body = github.String(addTestsToIssueBody(body, []string{"TestAccPubsubSchema_basic"}))
+	tests, err = testNamesFromIssue(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TestAccPubsubSchema_basic", "TestAccPubsubSubscription_basic", "TestAccPubsubTopic_basic"}, tests)
+}
@@ -11,8 +11,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"magician/exec"
-	"magician/github"
 	"magician/provider"
 	"magician/source"
 	"magician/vcr"
@@ -73,6 +71,8 @@ type recordReplay struct {
 	HasTerminatedTests            bool
 	RecordingErr                  error
 	AllRecordingPassed            bool
+	QuarantinedFailedTests        []string
+	CassetteDiff                  string
 	LogBucket                     string
 	Version                       string
 	Head                          string
@@ -126,8 +126,11 @@ The following environment variables are required:
 			baseBranch = "main"
 		}
 
-		gh := github.NewClient(env["GITHUB_TOKEN_MAGIC_MODULES"])
-		rnr, err := exec.NewRunner()
+		gh, err := newGithubClient(env["GITHUB_TOKEN_MAGIC_MODULES"])
+		if err != nil {
+			return err
+		}
+		rnr, err := newRunner()
 		if err != nil {
 			return fmt.Errorf("error creating a runner: %w", err)
 		}
@@ -142,7 +145,9 @@ The following environment variables are required:
 			return fmt.Errorf("wrong number of arguments %d, expected 5", len(args))
 		}
 
-		return execTestTerraformVCR(args[0], args[1], args[2], args[3], args[4], baseBranch, gh, rnr, ctlr, vt)
+		quarantined := quarantinedTestNames(newCloudstorageClient())
+
+		return execTestTerraformVCR(args[0], args[1], args[2], args[3], args[4], baseBranch, quarantined, gh, rnr, ctlr, vt)
 	},
 }
 
@@ -154,7 +159,7 @@ func listTTVRequiredEnvironmentVariables() string {
 	return result
 }
 
-func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep, baseBranch string, gh GithubClient, rnr ExecRunner, ctlr *source.Controller, vt *vcr.Tester) error {
+func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep, baseBranch string, quarantined map[string]struct{}, gh GithubClient, rnr ExecRunner, ctlr *source.Controller, vt *vcr.Tester) error {
 	newBranch := "auto-pr-" + prNumber
 	oldBranch := newBranch + "-old"
 
@@ -264,8 +269,11 @@ func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep,
 			Version:  provider.Beta,
 			TestDirs: testDirs,
 			Tests:    replayingResult.FailedTests,
+			Packages: replayingResult.TestPackages(),
 		})
-		if recordingErr != nil {
+		// Failures of quarantined flaky tests don't fail the build.
+		recordingFailed := recordingErr != nil && !onlyQuarantinedFailures(recordingResult, quarantined)
+		if recordingFailed {
 			testState = "failure"
 		} else {
 			testState = "success"
@@ -291,6 +299,17 @@ func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep,
 			return nil
 		}
 
+		// Show reviewers how the re-recorded cassettes differ from the base branch.
+		var cassetteDiff string
+		if len(recordingResult.PassedTests) > 0 {
+			diffs, err := vt.DiffCassettes(provider.Beta, baseBranch)
+			if err != nil {
+				fmt.Println("Error comparing cassettes: ", err)
+			} else if cassetteDiff, err = formatCassetteDiff(diffs); err != nil {
+				return fmt.Errorf("error formatting cassette diff: %w", err)
+			}
+		}
+
 		replayingAfterRecordingResult := vcr.Result{}
 		var replayingAfterRecordingErr error
 		if len(recordingResult.PassedTests) > 0 {
@@ -299,8 +318,9 @@ func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep,
 				Version:  provider.Beta,
 				TestDirs: testDirs,
 				Tests:    recordingResult.PassedTests,
+				Packages: recordingResult.TestPackages(),
 			})
-			if replayingAfterRecordingErr != nil {
+			if replayingAfterRecordingErr != nil && !onlyQuarantinedFailures(replayingAfterRecordingResult, quarantined) {
 				testState = "failure"
 			}
 
@@ -318,14 +338,26 @@ func execTestTerraformVCR(prNumber, mmCommitSha, buildID, projectID, buildStep,
 		}
 
 		hasTerminatedTests := (len(recordingResult.PassedTests) + len(recordingResult.FailedTests)) < len(replayingResult.FailedTests)
-		allRecordingPassed := len(recordingResult.FailedTests) == 0 && !hasTerminatedTests && recordingErr == nil
+
+		displayedRecordingResult := subtestResult(recordingResult)
+		displayedReplayingAfterRecordingResult := subtestResult(replayingAfterRecordingResult)
+		var quarantinedRecordingFailures, quarantinedReplayingFailures []string
+		displayedRecordingResult.FailedTests, quarantinedRecordingFailures = splitQuarantinedTests(displayedRecordingResult.FailedTests, quarantined)
+		displayedReplayingAfterRecordingResult.FailedTests, quarantinedReplayingFailures = splitQuarantinedTests(displayedReplayingAfterRecordingResult.FailedTests, quarantined)
+		allRecordingPassed := len(displayedRecordingResult.FailedTests) == 0 && !hasTerminatedTests && !recordingFailed
+		if !recordingFailed {
+			// Don't report an error that only comes from quarantined failures.
+			recordingErr = nil
+		}
 
 		recordReplayData := recordReplay{
-			RecordingResult:               subtestResult(recordingResult),
-			ReplayingAfterRecordingResult: subtestResult(replayingAfterRecordingResult),
+			RecordingResult:               displayedRecordingResult,
+			ReplayingAfterRecordingResult: displayedReplayingAfterRecordingResult,
 			RecordingErr:                  recordingErr,
 			HasTerminatedTests:            hasTerminatedTests,
 			AllRecordingPassed:            allRecordingPassed,
+			QuarantinedFailedTests:        append(quarantinedRecordingFailures, quarantinedReplayingFailures...),
+			CassetteDiff:                  cassetteDiff,
 			LogBucket:                     "ci-vcr-logs",
 			Version:                       provider.Beta.String(),
 			Head:                          newBranch,
@@ -398,13 +430,37 @@ func subtestResult(original vcr.Result) vcr.Result {
 	}
 }
 
+// Splits tests into the ones that block the PR and the ones that are
+// quarantined as flaky. Subtests are quarantined along with their compound test.
+func splitQuarantinedTests(tests []string, quarantined map[string]struct{}) ([]string, []string) {
+	var blocking, quarantinedTests []string
+	for _, test := range tests {
+		if _, ok := quarantined[compoundTest(test)]; ok {
+			quarantinedTests = append(quarantinedTests, test)
+		} else {
+			blocking = append(blocking, test)
+		}
+	}
+	return blocking, quarantinedTests
+}
+
+// Returns true if every failure in the result is a quarantined test, so the
+// error from the test run can be ignored.
+func onlyQuarantinedFailures(result vcr.Result, quarantined map[string]struct{}) bool {
+	if len(result.FailedTests) == 0 || len(result.Panics) > 0 {
+		return false
+	}
+	blocking, _ := splitQuarantinedTests(result.FailedTests, quarantined)
+	return len(blocking) == 0
+}
+
 // Returns the name of the compound test that the given subtest belongs to.
 func compoundTest(subtest string) string {
-	parts := strings.Split(subtest, "__")
-	if len(parts) != 2 {
+	compound, _, found := strings.Cut(subtest, "__")
+	if !found {
 		return subtest
 	}
-	return parts[0]
+	return compound
 }
 
 // Returns subtests and tests that are not compound tests.
@@ -478,6 +534,12 @@ func runReplaying(runFullVCR bool, version provider.Version, services map[string
 			result.SkippedTests = append(result.SkippedTests, serviceResult.SkippedTests...)
 			result.FailedTests = append(result.FailedTests, serviceResult.FailedTests...)
 			result.Panics = append(result.Panics, serviceResult.Panics...)
+			for test, detail := range serviceResult.Details {
+				if result.Details == nil {
+					result.Details = make(map[string]vcr.TestDetail)
+				}
+				result.Details[test] = detail
+			}
 		}
 	} else {
 		fmt.Println("runReplaying: no impacted services")
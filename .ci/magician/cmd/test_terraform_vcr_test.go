@@ -564,6 +564,45 @@ func TestRecordReplay(t *testing.T) {
 				"[debug log](https://console.cloud.google.com/storage/browser/ci-vcr-logs/beta/refs/heads/auto-pr-123/artifacts/build-123/recording)",
 			},
 		},
+		{
+			name: "quarantined tests failed",
+			data: recordReplay{
+				RecordingResult: vcr.Result{
+					PassedTests: []string{"a"},
+				},
+				ReplayingAfterRecordingResult: vcr.Result{
+					PassedTests: []string{"a"},
+				},
+				QuarantinedFailedTests: []string{"b", "c__sub"},
+				AllRecordingPassed:     true,
+				BuildID:                "build-123",
+				Head:                   "auto-pr-123",
+				Version:                provider.Beta.String(),
+				LogBucket:              "ci-vcr-logs",
+			},
+			wantContains: []string{
+				color("yellow", "Quarantined tests failed. These tests are known to be flaky in nightly runs, so their failures don't block your PR:"),
+				"`b`\n`c__sub`\n",
+				color("green", "All tests passed!"),
+			},
+		},
+		{
+			name: "cassette diff",
+			data: recordReplay{
+				RecordingResult: vcr.Result{
+					PassedTests: []string{"a"},
+				},
+				AllRecordingPassed: true,
+				CassetteDiff:       "<details>\n<summary>API call changes</summary>\n</details>",
+				BuildID:            "build-123",
+				Head:               "auto-pr-123",
+				Version:            provider.Beta.String(),
+				LogBucket:          "ci-vcr-logs",
+			},
+			wantContains: []string{
+				"for each test\n\n<details>\n<summary>API call changes</summary>\n</details>",
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
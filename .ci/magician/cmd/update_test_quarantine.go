@@ -0,0 +1,251 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"magician/cloudstorage"
+	"magician/provider"
+	utils "magician/utility"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	quarantineObjectName = "test-quarantine/quarantine.json"
+	quarantineFileName   = "quarantine.json"
+)
+
+var (
+	// used for flags
+	quarantineLookbackDays     int
+	quarantineMinFlips         int
+	unquarantinePassStreakDays int
+	quarantineDryRun           bool
+)
+
+// QuarantinedTest is a nightly test that is known to be flaky. Failures of
+// quarantined tests don't fail VCR tests on PRs.
+type QuarantinedTest struct {
+	Name            string `json:"name"`
+	QuarantinedDate string `json:"quarantined_date"`
+	Reason          string `json:"reason"`
+}
+
+// testHistory is the nightly results of a test, most recent day first.
+// Days when the test didn't run or was skipped are left out.
+type testHistory []bool
+
+// flips returns how many times the test went from passing to failing or back.
+func (h testHistory) flips() int {
+	flips := 0
+	for i := 1; i < len(h); i++ {
+		if h[i] != h[i-1] {
+			flips++
+		}
+	}
+	return flips
+}
+
+// passStreak returns the number of most recent runs that passed in a row.
+func (h testHistory) passStreak() int {
+	streak := 0
+	for _, passed := range h {
+		if !passed {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+// updateTestQuarantineCmd represents the updateTestQuarantine command
+var updateTestQuarantineCmd = &cobra.Command{
+	Use:   "update-test-quarantine",
+	Short: "Updates the list of quarantined flaky tests",
+	Long: `This command updates the list of quarantined flaky tests based on nightly test status.
+
+	It performs the following operations:
+	1. Reads the nightly test status of GA and Beta for the lookback window.
+	2. Quarantines tests that alternated between passing and failing at least --min-flips times.
+	   Tests that fail consistently are broken rather than flaky, and are left to test failure tickets.
+	3. Removes tests from quarantine once their most recent runs passed --unquarantine-streak times in a row,
+	   or when they didn't run at all within the lookback window.
+	4. Uploads the updated list to GCS, where test-terraform-vcr reads it.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gcs := cloudstorage.NewClient()
+
+		loc, err := time.LoadLocation("America/Los_Angeles")
+		if err != nil {
+			return fmt.Errorf("Error loading location: %s", err)
+		}
+		date := time.Now().In(loc)
+
+		return execUpdateTestQuarantine(date, gcs)
+	},
+}
+
+func execUpdateTestQuarantine(now time.Time, gcs CloudstorageClient) error {
+	histories := make(map[provider.Version]map[string]testHistory)
+	for _, pVersion := range []provider.Version{provider.GA, provider.Beta} {
+		histories[pVersion] = lastNDaysTestHistory(pVersion, quarantineLookbackDays, now, gcs)
+	}
+
+	quarantine, err := loadQuarantine(gcs)
+	if cloudstorage.IsNotExist(err) {
+		// The first run has no list to start from.
+		fmt.Printf("Starting from an empty quarantine list: %s\n", err)
+	} else if err != nil {
+		// Uploading an empty list would unquarantine every test.
+		return fmt.Errorf("error loading quarantine list: %w", err)
+	}
+	updated := updateQuarantine(quarantine, histories, now, quarantineMinFlips, unquarantinePassStreakDays)
+
+	for _, test := range updated {
+		fmt.Printf("Quarantined: %s (%s)\n", test.Name, test.Reason)
+	}
+	if quarantineDryRun {
+		return nil
+	}
+	return storeQuarantine(gcs, updated)
+}
+
+// lastNDaysTestHistory returns the nightly results of each test over the last
+// n days. Missing days are skipped.
+func lastNDaysTestHistory(pVersion provider.Version, n int, now time.Time, gcs CloudstorageClient) map[string]testHistory {
+	histories := make(map[string]testHistory)
+	for i := 0; i < n; i++ {
+		date := now.AddDate(0, 0, -i)
+		testInfoList, err := getTestInfoList(pVersion, date, gcs)
+		if err != nil {
+			fmt.Printf("Skipping %s test status for %s: %s\n", pVersion, date.Format("2006-01-02"), err)
+			continue
+		}
+		for _, testInfo := range testInfoList {
+			switch testInfo.Status {
+			case "SUCCESS":
+				histories[testInfo.Name] = append(histories[testInfo.Name], true)
+			case "FAILURE":
+				histories[testInfo.Name] = append(histories[testInfo.Name], false)
+			}
+		}
+	}
+	return histories
+}
+
+// updateQuarantine returns the quarantine list with newly flaky tests added
+// and tests that pass consistently again or no longer run removed.
+func updateQuarantine(quarantine []QuarantinedTest, histories map[provider.Version]map[string]testHistory, now time.Time, minFlips, passStreak int) []QuarantinedTest {
+	// Without any nightly results there is nothing to judge the list by.
+	hasResults := false
+	for _, versionHistories := range histories {
+		if len(versionHistories) > 0 {
+			hasResults = true
+		}
+	}
+
+	updated := make(map[string]QuarantinedTest)
+	for _, test := range quarantine {
+		// A test stays quarantined until it has passed enough times in a row
+		// in every version it ran in. Tests that didn't run at all in the
+		// lookback window were removed or renamed, and are dropped.
+		recovered, ran := false, false
+		for _, versionHistories := range histories {
+			history, ok := versionHistories[test.Name]
+			if !ok {
+				continue
+			}
+			ran = true
+			if history.passStreak() < passStreak {
+				recovered = false
+				break
+			}
+			recovered = true
+		}
+		if !ran && hasResults {
+			continue
+		}
+		if !recovered {
+			updated[test.Name] = test
+		}
+	}
+
+	for _, pVersion := range []provider.Version{provider.GA, provider.Beta} {
+		for name, history := range histories[pVersion] {
+			if _, ok := updated[name]; ok {
+				continue
+			}
+			if flips := history.flips(); flips >= minFlips && history.passStreak() < passStreak {
+				updated[name] = QuarantinedTest{
+					Name:            name,
+					QuarantinedDate: now.Format("2006-01-02"),
+					Reason:          fmt.Sprintf("alternated between passing and failing %d times in the last %d %s nightly runs", flips, len(history), pVersion),
+				}
+			}
+		}
+	}
+
+	result := make([]QuarantinedTest, 0, len(updated))
+	for _, test := range updated {
+		result = append(result, test)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func loadQuarantine(gcs CloudstorageClient) ([]QuarantinedTest, error) {
+	var quarantine []QuarantinedTest
+	if err := downloadJson(gcs, NightlyDataBucket, quarantineObjectName, &quarantine); err != nil {
+		return nil, err
+	}
+	return quarantine, nil
+}
+
+func storeQuarantine(gcs CloudstorageClient, quarantine []QuarantinedTest) error {
+	if err := utils.WriteToJson(quarantine, quarantineFileName); err != nil {
+		return err
+	}
+	return gcs.WriteToGCSBucket(NightlyDataBucket, quarantineObjectName, quarantineFileName)
+}
+
+// quarantinedTestNames returns the names of quarantined tests, or an empty set
+// if the list can't be read. Tests are never skipped because of a missing list.
+func quarantinedTestNames(gcs CloudstorageClient) map[string]struct{} {
+	names := make(map[string]struct{})
+	quarantine, err := loadQuarantine(gcs)
+	if err != nil {
+		fmt.Printf("🟡 Could not load quarantined tests: %s\n", err)
+		return names
+	}
+	for _, test := range quarantine {
+		names[test.Name] = struct{}{}
+	}
+	return names
+}
+
+func init() {
+	rootCmd.AddCommand(updateTestQuarantineCmd)
+	updateTestQuarantineCmd.Flags().IntVar(&quarantineLookbackDays, "lookback-days", 14, "Number of days of nightly test status to consider")
+	updateTestQuarantineCmd.Flags().IntVar(&quarantineMinFlips, "min-flips", 3, "Number of pass/fail alternations within the lookback window that quarantine a test")
+	updateTestQuarantineCmd.Flags().IntVar(&unquarantinePassStreakDays, "unquarantine-streak", 7, "Number of most recent nightly runs a quarantined test must pass in a row to leave quarantine")
+	updateTestQuarantineCmd.Flags().BoolVar(&quarantineDryRun, "dry-run", false, "Only print the updated list instead of uploading it")
+}
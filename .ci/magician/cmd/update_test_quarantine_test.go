@@ -0,0 +1,174 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"magician/cloudstorage"
+	"magician/provider"
+	"magician/vcr"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTestHistory(t *testing.T) {
+	cases := map[string]struct {
+		history    testHistory
+		flips      int
+		passStreak int
+	}{
+		"empty": {
+			history: testHistory{},
+		},
+		"always passing": {
+			history:    testHistory{true, true, true},
+			passStreak: 3,
+		},
+		"always failing": {
+			history: testHistory{false, false, false},
+		},
+		"alternating": {
+			history:    testHistory{true, false, true, true, false},
+			flips:      3,
+			passStreak: 1,
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			if got := tc.history.flips(); got != tc.flips {
+				t.Errorf("flips() = %d, want %d", got, tc.flips)
+			}
+			if got := tc.history.passStreak(); got != tc.passStreak {
+				t.Errorf("passStreak() = %d, want %d", got, tc.passStreak)
+			}
+		})
+	}
+}
+
+func TestUpdateQuarantine(t *testing.T) {
+	now := time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC)
+	quarantine := []QuarantinedTest{
+		{Name: "TestAccRecovered", QuarantinedDate: "2025-02-01", Reason: "old"},
+		{Name: "TestAccStillFlaky", QuarantinedDate: "2025-02-01", Reason: "old"},
+		{Name: "TestAccRecoveredInGAOnly", QuarantinedDate: "2025-02-01", Reason: "old"},
+		{Name: "TestAccNotRun", QuarantinedDate: "2025-02-01", Reason: "old"},
+	}
+	histories := map[provider.Version]map[string]testHistory{
+		provider.GA: {
+			"TestAccRecovered":         {true, true, true},
+			"TestAccStillFlaky":        {true, true, false, true},
+			"TestAccRecoveredInGAOnly": {true, true, true},
+			"TestAccNewlyFlaky":        {false, true, false, true},
+			"TestAccBroken":            {false, false, false, false},
+			"TestAccPassing":           {true, true, true, true},
+		},
+		provider.Beta: {
+			"TestAccRecoveredInGAOnly": {true, false, true},
+			"TestAccNewlyFlakyInBeta":  {true, false, true, false},
+		},
+	}
+	want := []QuarantinedTest{
+		{Name: "TestAccNewlyFlaky", QuarantinedDate: "2025-03-10", Reason: "alternated between passing and failing 3 times in the last 4 ga nightly runs"},
+		{Name: "TestAccNewlyFlakyInBeta", QuarantinedDate: "2025-03-10", Reason: "alternated between passing and failing 3 times in the last 4 beta nightly runs"},
+		{Name: "TestAccRecoveredInGAOnly", QuarantinedDate: "2025-02-01", Reason: "old"},
+		{Name: "TestAccStillFlaky", QuarantinedDate: "2025-02-01", Reason: "old"},
+	}
+	got := updateQuarantine(quarantine, histories, now, 3, 3)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("updateQuarantine() returned unexpected difference (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateQuarantineWithoutResults(t *testing.T) {
+	now := time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC)
+	quarantine := []QuarantinedTest{
+		{Name: "TestAccNotRun", QuarantinedDate: "2025-02-01", Reason: "old"},
+	}
+	histories := map[provider.Version]map[string]testHistory{
+		provider.GA:   {},
+		provider.Beta: {},
+	}
+	got := updateQuarantine(quarantine, histories, now, 3, 3)
+	if diff := cmp.Diff(quarantine, got); diff != "" {
+		t.Errorf("updateQuarantine() returned unexpected difference (-want +got):\n%s", diff)
+	}
+}
+
+func TestQuarantinedFailures(t *testing.T) {
+	quarantined := map[string]struct{}{"TestAccFlaky": {}, "TestAccCompound": {}}
+	blocking, quarantinedTests := splitQuarantinedTests([]string{"TestAccBroken", "TestAccCompound__sub", "TestAccFlaky"}, quarantined)
+	if diff := cmp.Diff([]string{"TestAccBroken"}, blocking); diff != "" {
+		t.Errorf("splitQuarantinedTests() returned unexpected blocking tests (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"TestAccCompound__sub", "TestAccFlaky"}, quarantinedTests); diff != "" {
+		t.Errorf("splitQuarantinedTests() returned unexpected quarantined tests (-want +got):\n%s", diff)
+	}
+
+	cases := map[string]struct {
+		result vcr.Result
+		want   bool
+	}{
+		"no failures": {
+			result: vcr.Result{PassedTests: []string{"TestAccFlaky"}},
+		},
+		"only quarantined failures": {
+			result: vcr.Result{FailedTests: []string{"TestAccFlaky", "TestAccCompound"}},
+			want:   true,
+		},
+		"blocking failure": {
+			result: vcr.Result{FailedTests: []string{"TestAccFlaky", "TestAccBroken"}},
+		},
+		"panic": {
+			result: vcr.Result{FailedTests: []string{"TestAccFlaky"}, Panics: []string{"panic: oops"}},
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			if got := onlyQuarantinedFailures(tc.result, quarantined); got != tc.want {
+				t.Errorf("onlyQuarantinedFailures() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecUpdateTestQuarantineLoadErrors(t *testing.T) {
+	now := time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC)
+	defer func(dryRun bool, lookbackDays int) {
+		quarantineDryRun, quarantineLookbackDays = dryRun, lookbackDays
+	}(quarantineDryRun, quarantineLookbackDays)
+	quarantineDryRun, quarantineLookbackDays = true, 1
+
+	// The first run has no list yet.
+	gcs := cloudstorage.NewLocalClient(t.TempDir())
+	if err := execUpdateTestQuarantine(now, gcs); err != nil {
+		t.Errorf("execUpdateTestQuarantine() without a quarantine list = %s, want nil", err)
+	}
+
+	// Any other error must not replace the list with an empty one.
+	path := gcs.ObjectPath(NightlyDataBucket, quarantineObjectName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := execUpdateTestQuarantine(now, gcs); err == nil {
+		t.Errorf("execUpdateTestQuarantine() with an unreadable quarantine list = nil, want an error")
+	}
+}
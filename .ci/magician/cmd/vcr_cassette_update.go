@@ -173,9 +173,10 @@ func execVCRCassetteUpdate(buildID, today string, rnr ExecRunner, ctlr *source.C
 		fmt.Println("running tests in RECORDING mode now")
 
 		recordingResult, recordingErr := vt.RunParallel(vcr.RunOptions{
-			Mode:    vcr.Recording,
-			Version: provider.Beta,
-			Tests:   replayingResult.FailedTests,
+			Mode:     vcr.Recording,
+			Version:  provider.Beta,
+			Tests:    replayingResult.FailedTests,
+			Packages: replayingResult.TestPackages(),
 		})
 
 		// upload build and test logs first to preserve debugging logs in case
@@ -190,11 +191,25 @@ func execVCRCassetteUpdate(buildID, today string, rnr ExecRunner, ctlr *source.C
 			fmt.Printf("Warning: error uploading recording build log: %s\n", err)
 		}
 
+		var scrubErr error
 		if len(recordingResult.PassedTests) > 0 {
-			cassettesPath := vt.CassettePath(provider.Beta)
-			if _, err := uploadCassettesToGCS(cassettesPath+"/*", "gs://ci-vcr-cassettes/beta/fixtures/", rnr); err != nil {
-				// There could be cases that the tests do not generate any cassettes.
-				fmt.Printf("Warning: error uploading cassettes: %s\n", err)
+			// The recorder scrubs cassettes as it saves them, so anything found
+			// here slipped past it and must not reach the shared main cassettes.
+			findings, err := vt.ScrubCassettes(provider.Beta, false)
+			if err != nil {
+				scrubErr = fmt.Errorf("error checking cassettes for secrets: %w", err)
+			} else if len(findings) > 0 {
+				printScrubFindings(findings)
+				scrubErr = fmt.Errorf("found unredacted secrets in %d recorded cassettes, not uploading cassettes", len(findings))
+			}
+			if scrubErr == nil {
+				// Only upload the re-recorded cassettes, the others are unchanged
+				// from the main cassettes.
+				cassettesPath := vt.RecordedCassettePath(provider.Beta)
+				if _, err := uploadCassettesToGCS(cassettesPath+"/*", "gs://ci-vcr-cassettes/beta/fixtures/", rnr); err != nil {
+					// There could be cases that the tests do not generate any cassettes.
+					fmt.Printf("Warning: error uploading cassettes: %s\n", err)
+				}
 			}
 		} else {
 			fmt.Println("No tests passed in recording mode, not uploading cassettes.")
@@ -217,6 +232,9 @@ func execVCRCassetteUpdate(buildID, today string, rnr ExecRunner, ctlr *source.C
 		if len(recordingResult.Panics) != 0 {
 			return fmt.Errorf("provider crashed while running the VCR tests in RECORDING mode: %v", recordingResult.Panics)
 		}
+		if scrubErr != nil {
+			return scrubErr
+		}
 	}
 	return nil
 }
@@ -46,6 +46,8 @@ type PullRequest struct {
 	Labels         []Label `json:"labels"`
 	MergeCommitSha string  `json:"merge_commit_sha"`
 	Merged         bool    `json:"merged"`
+	// Users whose review is requested and who haven't reviewed since.
+	RequestedReviewers []User `json:"requested_reviewers,omitempty"`
 }
 
 type PullRequestComment struct {
@@ -70,13 +72,14 @@ func (c *Client) GetPullRequest(prNumber string) (PullRequest, error) {
 	return convertGHPullRequest(pr), nil
 }
 
-// GetPullRequests fetches multiple pull requests
+// GetPullRequests fetches the first 100 pull requests in the given order
 func (c *Client) GetPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
 	opts := &gh.PullRequestListOptions{
-		State:     state,
-		Base:      base,
-		Sort:      sort,
-		Direction: direction,
+		State:       state,
+		Base:        base,
+		Sort:        sort,
+		Direction:   direction,
+		ListOptions: gh.ListOptions{PerPage: 100},
 	}
 
 	prs, _, err := c.gh.PullRequests.List(c.ctx, defaultOwner, defaultRepo, opts)
@@ -92,6 +95,41 @@ func (c *Client) GetPullRequests(state, base, sort, direction string) ([]PullReq
 	return result, nil
 }
 
+// GetAllPullRequests fetches every pull request in the given order, handling pagination
+func (c *Client) GetAllPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
+	var allPRs []*gh.PullRequest
+	opts := &gh.PullRequestListOptions{
+		State:       state,
+		Base:        base,
+		Sort:        sort,
+		Direction:   direction,
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+
+	for {
+		prs, resp, err := c.gh.PullRequests.List(c.ctx, defaultOwner, defaultRepo, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allPRs = append(allPRs, prs...)
+
+		if resp.NextPage == 0 {
+			break // No more pages
+		}
+
+		// Set up for the next page
+		opts.Page = resp.NextPage
+	}
+
+	result := make([]PullRequest, len(allPRs))
+	for i, pr := range allPRs {
+		result[i] = convertGHPullRequest(pr)
+	}
+
+	return result, nil
+}
+
 // GetPullRequestRequestedReviewers gets requested reviewers for a PR
 func (c *Client) GetPullRequestRequestedReviewers(prNumber string) ([]User, error) {
 	num, err := strconv.Atoi(prNumber)
@@ -85,6 +85,17 @@ func TestIntegrationGetPullRequests(t *testing.T) {
 	t.Logf("Found %d PRs", len(prs))
 }
 
+func TestIntegrationGetAllPullRequests(t *testing.T) {
+	client := skipIfNoToken(t)
+
+	prs, err := client.GetAllPullRequests("open", "main", "created", "desc")
+	if err != nil {
+		t.Fatalf("GetAllPullRequests failed: %v", err)
+	}
+
+	t.Logf("Found %d PRs", len(prs))
+}
+
 func TestIntegrationGetCommitMessage(t *testing.T) {
 	client := skipIfNoToken(t)
 
@@ -20,14 +20,15 @@ func convertGHPullRequest(pr *gh.PullRequest) PullRequest {
 	}
 
 	return PullRequest{
-		HTMLUrl:        pr.GetHTMLURL(),
-		Number:         pr.GetNumber(),
-		Title:          pr.GetTitle(),
-		User:           User{Login: pr.GetUser().GetLogin()},
-		Body:           pr.GetBody(),
-		Labels:         labels,
-		MergeCommitSha: pr.GetMergeCommitSHA(),
-		Merged:         pr.GetMerged(),
+		HTMLUrl:            pr.GetHTMLURL(),
+		Number:             pr.GetNumber(),
+		Title:              pr.GetTitle(),
+		User:               User{Login: pr.GetUser().GetLogin()},
+		Body:               pr.GetBody(),
+		Labels:             labels,
+		MergeCommitSha:     pr.GetMergeCommitSHA(),
+		Merged:             pr.GetMerged(),
+		RequestedReviewers: convertGHUsers(pr.RequestedReviewers),
 	}
 }
 
@@ -0,0 +1,520 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v68/github"
+)
+
+// LocalUser is the user that comments and issues are posted as by LocalClient.
+const LocalUser = "modular-magician"
+
+// LocalState is the content of the JSON file that backs LocalClient. Fill in
+// the pull requests, users and teams a command reads; everything the command
+// posts is recorded in the same file.
+type LocalState struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	// Keyed by pull request number.
+	RequestedReviewers map[string][]User               `json:"requested_reviewers,omitempty"`
+	PreviousReviewers  map[string][]User               `json:"previous_reviewers,omitempty"`
+	Comments           map[string][]PullRequestComment `json:"comments,omitempty"`
+	// Keyed by commit SHA.
+	CommitMessages map[string]string `json:"commit_messages,omitempty"`
+	// Keyed by "organization/team".
+	TeamMembers map[string][]User `json:"team_members,omitempty"`
+	// Users that are reported as Googlers. Core contributors are read from
+	// the membership data like in Client.
+	Googlers []string `json:"googlers,omitempty"`
+
+	Issues []*gh.Issue `json:"issues,omitempty"`
+	// Keyed by issue number.
+	IssueComments map[int][]*gh.IssueComment `json:"issue_comments,omitempty"`
+
+	BuildStatuses      []LocalBuildStatus      `json:"build_statuses,omitempty"`
+	Merges             []LocalMerge            `json:"merges,omitempty"`
+	WorkflowDispatches []LocalWorkflowDispatch `json:"workflow_dispatches,omitempty"`
+}
+
+type LocalBuildStatus struct {
+	PullRequest string `json:"pull_request"`
+	Title       string `json:"title"`
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url"`
+	CommitSha   string `json:"commit_sha"`
+}
+
+type LocalMerge struct {
+	Owner       string `json:"owner"`
+	Repo        string `json:"repo"`
+	PullRequest string `json:"pull_request"`
+	CommitSha   string `json:"commit_sha"`
+}
+
+type LocalWorkflowDispatch struct {
+	WorkflowFileName string         `json:"workflow_file_name"`
+	Inputs           map[string]any `json:"inputs"`
+}
+
+// LocalClient is a GitHub client backed by a JSON file, so that commands can
+// run offline and their effects can be inspected. Every change is written
+// back to the file immediately.
+type LocalClient struct {
+	path  string
+	mu    sync.Mutex
+	state LocalState
+
+	// Issues mirrors the issues API of the go-github client.
+	Issues *LocalIssuesService
+}
+
+// LocalIssuesService records issues in the state of a LocalClient.
+type LocalIssuesService struct {
+	c *LocalClient
+}
+
+// NewLocalClient reads the state from path. A missing file is treated as an
+// empty state and is created on the first change.
+func NewLocalClient(path string) (*LocalClient, error) {
+	c := &LocalClient{path: path}
+	c.Issues = &LocalIssuesService{c: c}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.state); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// State returns the current state. It must not be modified.
+func (c *LocalClient) State() LocalState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// update applies f to the state and writes it back to the file.
+func (c *LocalClient) update(f func(s *LocalState) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := f(&c.state); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+func (s *LocalState) pullRequest(prNumber string) (*PullRequest, error) {
+	num, err := strconv.Atoi(prNumber)
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.PullRequests {
+		if s.PullRequests[i].Number == num {
+			return &s.PullRequests[i], nil
+		}
+	}
+	return nil, fmt.Errorf("pull request %s not found", prNumber)
+}
+
+func (c *LocalClient) GetPullRequest(prNumber string) (PullRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pr, err := c.state.pullRequest(prNumber)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("%w in %s", err, c.path)
+	}
+	return *pr, nil
+}
+
+// GetPullRequests returns all pull requests in the state. The state has no
+// branches or dates, so only "closed" and "open" filter on whether the pull
+// request was merged.
+func (c *LocalClient) GetPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var prs []PullRequest
+	for _, pr := range c.state.PullRequests {
+		if (state == "open" && pr.Merged) || (state == "closed" && !pr.Merged) {
+			continue
+		}
+		if requested, ok := c.state.RequestedReviewers[strconv.Itoa(pr.Number)]; ok {
+			pr.RequestedReviewers = requested
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// GetAllPullRequests is the same as GetPullRequests, since the state isn't
+// paginated.
+func (c *LocalClient) GetAllPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
+	return c.GetPullRequests(state, base, sort, direction)
+}
+
+func (c *LocalClient) GetPullRequestRequestedReviewers(prNumber string) ([]User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.RequestedReviewers[prNumber], nil
+}
+
+func (c *LocalClient) GetPullRequestPreviousReviewers(prNumber string) ([]User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.PreviousReviewers[prNumber], nil
+}
+
+func (c *LocalClient) GetPullRequestComments(prNumber string) ([]PullRequestComment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.Comments[prNumber], nil
+}
+
+func (c *LocalClient) GetCommitMessage(owner, repo, sha string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	message, ok := c.state.CommitMessages[sha]
+	if !ok {
+		return "", fmt.Errorf("commit %s not found in %s", sha, c.path)
+	}
+	return message, nil
+}
+
+func (c *LocalClient) GetUserType(user string) UserType {
+	if IsCoreContributor(user) {
+		return CoreContributorUserType
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slices.Contains(c.state.Googlers, user) {
+		return GooglerUserType
+	}
+	return CommunityUserType
+}
+
+func (c *LocalClient) GetTeamMembers(organization, team string) ([]User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.TeamMembers[organization+"/"+team], nil
+}
+
+func (c *LocalClient) MergePullRequest(owner, repo, prNumber, commitSha string) error {
+	err := c.update(func(s *LocalState) error {
+		pr, err := s.pullRequest(prNumber)
+		if err != nil {
+			return err
+		}
+		pr.Merged = true
+		pr.MergeCommitSha = commitSha
+		s.Merges = append(s.Merges, LocalMerge{Owner: owner, Repo: repo, PullRequest: prNumber, CommitSha: commitSha})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded merge of pull request %s in %s\n", prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) PostBuildStatus(prNumber, title, state, targetURL, commitSha string) error {
+	err := c.update(func(s *LocalState) error {
+		s.BuildStatuses = append(s.BuildStatuses, LocalBuildStatus{
+			PullRequest: prNumber,
+			Title:       title,
+			State:       state,
+			TargetURL:   targetURL,
+			CommitSha:   commitSha,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded build status %q (%s) for pull request %s in %s\n", title, state, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) PostComment(prNumber, comment string) error {
+	err := c.update(func(s *LocalState) error {
+		if s.Comments == nil {
+			s.Comments = make(map[string][]PullRequestComment)
+		}
+		s.Comments[prNumber] = append(s.Comments[prNumber], PullRequestComment{
+			User:      User{Login: LocalUser},
+			Body:      comment,
+			ID:        s.nextCommentID(),
+			CreatedAt: time.Now().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded comment on pull request %s in %s\n", prNumber, c.path)
+	return nil
+}
+
+func (s *LocalState) nextCommentID() int {
+	id := 0
+	for _, comments := range s.Comments {
+		for _, comment := range comments {
+			id = max(id, comment.ID)
+		}
+	}
+	return id + 1
+}
+
+func (c *LocalClient) UpdateComment(prNumber, comment string, id int) error {
+	err := c.update(func(s *LocalState) error {
+		comments := s.Comments[prNumber]
+		for i := range comments {
+			if comments[i].ID == id {
+				comments[i].Body = comment
+				return nil
+			}
+		}
+		return fmt.Errorf("comment %d not found in pull request %s", id, prNumber)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded update of comment %d in pull request %s in %s\n", id, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) RequestPullRequestReviewers(prNumber string, reviewers []string) error {
+	err := c.update(func(s *LocalState) error {
+		if s.RequestedReviewers == nil {
+			s.RequestedReviewers = make(map[string][]User)
+		}
+		for _, reviewer := range reviewers {
+			if !slices.Contains(s.RequestedReviewers[prNumber], User{Login: reviewer}) {
+				s.RequestedReviewers[prNumber] = append(s.RequestedReviewers[prNumber], User{Login: reviewer})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded review request of %v on pull request %s in %s\n", reviewers, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) RemovePullRequestReviewers(prNumber string, reviewers []string) error {
+	err := c.update(func(s *LocalState) error {
+		if _, ok := s.RequestedReviewers[prNumber]; !ok {
+			return nil
+		}
+		s.RequestedReviewers[prNumber] = slices.DeleteFunc(s.RequestedReviewers[prNumber], func(u User) bool {
+			return slices.Contains(reviewers, u.Login)
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded removal of reviewers %v from pull request %s in %s\n", reviewers, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) AddLabels(prNumber string, labels []string) error {
+	err := c.update(func(s *LocalState) error {
+		pr, err := s.pullRequest(prNumber)
+		if err != nil {
+			return err
+		}
+		for _, label := range labels {
+			if !slices.Contains(pr.Labels, Label{Name: label}) {
+				pr.Labels = append(pr.Labels, Label{Name: label})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded labels %v on pull request %s in %s\n", labels, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) RemoveLabel(prNumber, label string) error {
+	err := c.update(func(s *LocalState) error {
+		pr, err := s.pullRequest(prNumber)
+		if err != nil {
+			return err
+		}
+		pr.Labels = slices.DeleteFunc(pr.Labels, func(l Label) bool { return l.Name == label })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded removal of label %s from pull request %s in %s\n", label, prNumber, c.path)
+	return nil
+}
+
+func (c *LocalClient) CreateWorkflowDispatchEvent(workflowFileName string, inputs map[string]any) error {
+	err := c.update(func(s *LocalState) error {
+		s.WorkflowDispatches = append(s.WorkflowDispatches, LocalWorkflowDispatch{WorkflowFileName: workflowFileName, Inputs: inputs})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded dispatch of workflow %s in %s\n", workflowFileName, c.path)
+	return nil
+}
+
+// ListByRepo returns the issues matching the state, labels and since options.
+// All issues are returned in a single page.
+func (s *LocalIssuesService) ListByRepo(ctx context.Context, owner, repo string, opts *gh.IssueListByRepoOptions) ([]*gh.Issue, *gh.Response, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	var issues []*gh.Issue
+	for _, issue := range s.c.state.Issues {
+		if opts != nil && !issueMatches(issue, opts) {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, &gh.Response{}, nil
+}
+
+func issueMatches(issue *gh.Issue, opts *gh.IssueListByRepoOptions) bool {
+	if opts.State != "" && opts.State != "all" && opts.State != issue.GetState() {
+		return false
+	}
+	for _, label := range opts.Labels {
+		if !slices.ContainsFunc(issue.Labels, func(l *gh.Label) bool { return l.GetName() == label }) {
+			return false
+		}
+	}
+	return opts.Since.IsZero() || !issue.GetUpdatedAt().Time.Before(opts.Since)
+}
+
+func (s *LocalIssuesService) Create(ctx context.Context, owner, repo string, request *gh.IssueRequest) (*gh.Issue, *gh.Response, error) {
+	var issue *gh.Issue
+	err := s.c.update(func(state *LocalState) error {
+		number := 1
+		for _, i := range state.Issues {
+			number = max(number, i.GetNumber()+1)
+		}
+		for _, pr := range state.PullRequests {
+			number = max(number, pr.Number+1)
+		}
+		now := gh.Timestamp{Time: time.Now().UTC()}
+		issue = &gh.Issue{
+			Number:    gh.Ptr(number),
+			State:     gh.Ptr("open"),
+			User:      &gh.User{Login: gh.Ptr(LocalUser)},
+			CreatedAt: &now,
+			UpdatedAt: &now,
+		}
+		applyIssueRequest(issue, request)
+		state.Issues = append(state.Issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Recorded issue %d %q in %s\n", issue.GetNumber(), issue.GetTitle(), s.c.path)
+	return issue, &gh.Response{}, nil
+}
+
+func (s *LocalIssuesService) Edit(ctx context.Context, owner, repo string, number int, request *gh.IssueRequest) (*gh.Issue, *gh.Response, error) {
+	var issue *gh.Issue
+	err := s.c.update(func(state *LocalState) error {
+		for _, i := range state.Issues {
+			if i.GetNumber() == number {
+				issue = i
+				break
+			}
+		}
+		if issue == nil {
+			return fmt.Errorf("issue %d not found", number)
+		}
+		applyIssueRequest(issue, request)
+		issue.UpdatedAt = &gh.Timestamp{Time: time.Now().UTC()}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Recorded edit of issue %d in %s\n", number, s.c.path)
+	return issue, &gh.Response{}, nil
+}
+
+func applyIssueRequest(issue *gh.Issue, request *gh.IssueRequest) {
+	if request.Title != nil {
+		issue.Title = request.Title
+	}
+	if request.Body != nil {
+		issue.Body = request.Body
+	}
+	if request.State != nil {
+		issue.State = request.State
+	}
+	if request.Labels != nil {
+		issue.Labels = nil
+		for _, label := range *request.Labels {
+			issue.Labels = append(issue.Labels, &gh.Label{Name: gh.Ptr(label)})
+		}
+	}
+	if request.Milestone != nil {
+		issue.Milestone = &gh.Milestone{Number: request.Milestone}
+	}
+}
+
+func (s *LocalIssuesService) CreateComment(ctx context.Context, owner, repo string, number int, comment *gh.IssueComment) (*gh.IssueComment, *gh.Response, error) {
+	var created *gh.IssueComment
+	err := s.c.update(func(state *LocalState) error {
+		if state.IssueComments == nil {
+			state.IssueComments = make(map[int][]*gh.IssueComment)
+		}
+		now := gh.Timestamp{Time: time.Now().UTC()}
+		created = &gh.IssueComment{
+			ID:        gh.Ptr(int64(len(state.IssueComments[number]) + 1)),
+			Body:      comment.Body,
+			User:      &gh.User{Login: gh.Ptr(LocalUser)},
+			CreatedAt: &now,
+		}
+		state.IssueComments[number] = append(state.IssueComments[number], created)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Recorded comment on issue %d in %s\n", number, s.c.path)
+	return created, &gh.Response{}, nil
+}
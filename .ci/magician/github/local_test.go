@@ -0,0 +1,130 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package github
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v68/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalClientPullRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github.json")
+	err := os.WriteFile(path, []byte(`{
+  "pull_requests": [{"number": 123, "title": "Add google_foo_bar", "user": {"login": "contributor"}, "labels": [{"name": "service/foo"}]}],
+  "commit_messages": {"abc123": "Merge abc into def"},
+  "googlers": ["googler"]
+}`), 0644)
+	require.NoError(t, err)
+
+	c, err := NewLocalClient(path)
+	require.NoError(t, err)
+
+	pr, err := c.GetPullRequest("123")
+	require.NoError(t, err)
+	assert.Equal(t, "Add google_foo_bar", pr.Title)
+	_, err = c.GetPullRequest("456")
+	assert.Error(t, err)
+
+	message, err := c.GetCommitMessage(defaultOwner, defaultRepo, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "Merge abc into def", message)
+	assert.Equal(t, GooglerUserType, c.GetUserType("googler"))
+	assert.Equal(t, CommunityUserType, c.GetUserType("contributor"))
+
+	require.NoError(t, c.PostComment("123", "first"))
+	require.NoError(t, c.PostComment("123", "second"))
+	require.NoError(t, c.UpdateComment("123", "second, updated", 2))
+	assert.Error(t, c.UpdateComment("123", "missing", 3))
+	require.NoError(t, c.AddLabels("123", []string{"service/foo", "awaiting-approval"}))
+	require.NoError(t, c.RemoveLabel("123", "service/foo"))
+	require.NoError(t, c.RequestPullRequestReviewers("123", []string{"reviewer1", "reviewer2"}))
+	require.NoError(t, c.RemovePullRequestReviewers("123", []string{"reviewer1"}))
+	require.NoError(t, c.PostBuildStatus("123", "terraform-provider-google-beta-test", "success", "https://example.com", "abc123"))
+
+	// Everything that was posted is read back from the file.
+	c, err = NewLocalClient(path)
+	require.NoError(t, err)
+	comments, err := c.GetPullRequestComments("123")
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, LocalUser, comments[0].User.Login)
+	assert.Equal(t, "second, updated", comments[1].Body)
+	pr, err = c.GetPullRequest("123")
+	require.NoError(t, err)
+	assert.Equal(t, []Label{{Name: "awaiting-approval"}}, pr.Labels)
+	reviewers, err := c.GetPullRequestRequestedReviewers("123")
+	require.NoError(t, err)
+	assert.Equal(t, []User{{Login: "reviewer2"}}, reviewers)
+	assert.Equal(t, []LocalBuildStatus{{
+		PullRequest: "123",
+		Title:       "terraform-provider-google-beta-test",
+		State:       "success",
+		TargetURL:   "https://example.com",
+		CommitSha:   "abc123",
+	}}, c.State().BuildStatuses)
+}
+
+func TestLocalIssuesService(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "github.json")
+	c, err := NewLocalClient(path)
+	require.NoError(t, err)
+
+	labels := []string{"test-failure", "test-failure-100"}
+	issue, _, err := c.Issues.Create(ctx, defaultOwner, defaultRepo, &gh.IssueRequest{
+		Title:     gh.Ptr("Failing test(s): TestAccFoo"),
+		Body:      gh.Ptr("body"),
+		Labels:    &labels,
+		Milestone: gh.Ptr(11),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, issue.GetNumber())
+	_, _, err = c.Issues.Create(ctx, defaultOwner, defaultRepo, &gh.IssueRequest{Title: gh.Ptr("other")})
+	require.NoError(t, err)
+	_, _, err = c.Issues.Edit(ctx, defaultOwner, defaultRepo, 1, &gh.IssueRequest{Body: gh.Ptr("new body")})
+	require.NoError(t, err)
+	_, _, err = c.Issues.Edit(ctx, defaultOwner, defaultRepo, 3, &gh.IssueRequest{Body: gh.Ptr("new body")})
+	assert.Error(t, err)
+	_, _, err = c.Issues.CreateComment(ctx, defaultOwner, defaultRepo, 1, &gh.IssueComment{Body: gh.Ptr("more tests")})
+	require.NoError(t, err)
+
+	c, err = NewLocalClient(path)
+	require.NoError(t, err)
+	issues, resp, err := c.Issues.ListByRepo(ctx, defaultOwner, defaultRepo, &gh.IssueListByRepoOptions{
+		State:  "open",
+		Labels: []string{"test-failure"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.NextPage)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "new body", issues[0].GetBody())
+	assert.Equal(t, 11, issues[0].GetMilestone().GetNumber())
+	assert.Equal(t, "more tests", c.State().IssueComments[1][0].GetBody())
+
+	issues, _, err = c.Issues.ListByRepo(ctx, defaultOwner, defaultRepo, &gh.IssueListByRepoOptions{State: "closed"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	issues, _, err = c.Issues.ListByRepo(ctx, defaultOwner, defaultRepo, &gh.IssueListByRepoOptions{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
@@ -23,8 +23,28 @@ import (
 	"time"
 
 	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
+
+	_ "embed"
+)
+
+var (
+	//go:embed reviewer_availability.yaml
+	reviewerAvailabilityYaml []byte
+
+	// reviewerAvailability is the availability of reviewers in and outside of
+	// the rotation, keyed by login.
+	reviewerAvailability = mustParseReviewerAvailability(reviewerAvailabilityYaml)
 )
 
+func init() {
+	for reviewer, config := range reviewerAvailability {
+		if _, ok := reviewerRotation[reviewer]; ok {
+			reviewerRotation[reviewer] = config
+		}
+	}
+}
+
 type UserType int64
 
 const (
@@ -102,12 +122,74 @@ func available(nowTime time.Time, reviewerRotation map[string]ReviewerConfig, ex
 func onVacation(nowTime time.Time, reviewerRotation map[string]ReviewerConfig) []string {
 	var onVacationList []string
 	for reviewer, config := range reviewerRotation {
-		for _, v := range config.vacations {
-			if nowTime.Before(v.GetStart(config.timezone)) || nowTime.After(v.GetEnd(config.timezone)) {
-				continue
-			}
+		if config.onVacation(nowTime) {
 			onVacationList = append(onVacationList, reviewer)
 		}
 	}
 	return onVacationList
 }
+
+func (config ReviewerConfig) onVacation(nowTime time.Time) bool {
+	for _, v := range config.vacations {
+		if nowTime.Before(v.GetStart(config.timezone)) || nowTime.After(v.GetEnd(config.timezone)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+type reviewerAvailabilityEntry struct {
+	Timezone  string `yaml:"timezone"`
+	Vacations []struct {
+		Start string `yaml:"start"`
+		End   string `yaml:"end"`
+	} `yaml:"vacations"`
+	MaxOpenReviews int `yaml:"max_open_reviews"`
+}
+
+// parseReviewerAvailability parses the format of reviewer_availability.yaml.
+func parseReviewerAvailability(data []byte) (map[string]ReviewerConfig, error) {
+	var entries map[string]reviewerAvailabilityEntry
+	if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+		return nil, err
+	}
+	configs := make(map[string]ReviewerConfig)
+	for reviewer, entry := range entries {
+		config := ReviewerConfig{maxOpenReviews: entry.MaxOpenReviews}
+		if entry.Timezone != "" {
+			timezone, err := time.LoadLocation(entry.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone for %s: %w", reviewer, err)
+			}
+			config.timezone = timezone
+		}
+		for _, v := range entry.Vacations {
+			start, err := time.Parse("2006-01-02", v.Start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vacation start date for %s: %w", reviewer, err)
+			}
+			end, err := time.Parse("2006-01-02", v.End)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vacation end date for %s: %w", reviewer, err)
+			}
+			if end.Before(start) {
+				return nil, fmt.Errorf("vacation of %s ends on %s before it starts on %s", reviewer, v.End, v.Start)
+			}
+			config.vacations = append(config.vacations, Vacation{
+				startDate: newDate(start.Year(), int(start.Month()), start.Day()),
+				endDate:   newDate(end.Year(), int(end.Month()), end.Day()),
+			})
+		}
+		configs[reviewer] = config
+	}
+	return configs, nil
+}
+
+func mustParseReviewerAvailability(data []byte) map[string]ReviewerConfig {
+	configs, err := parseReviewerAvailability(data)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse reviewer_availability.yaml: %s", err))
+	}
+	return configs
+}
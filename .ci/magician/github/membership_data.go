@@ -43,14 +43,11 @@ type ReviewerConfig struct {
 	// vacations allows specifying times when new reviews should not be requested of the reviewer.
 	// Existing PRs will still have reviews re-requested.
 	// Both startDate and endDate are inclusive.
-	// Example: taking vacation from 2024-03-28 to 2024-04-02.
-	// {
-	// 	 vacations:        []Vacation{
-	//     startDate: newDate(2024, 3, 28),
-	// 	   endDate:   newDate(2024, 4, 2),
-	//   },
-	// },
 	vacations []Vacation
+
+	// maxOpenReviews is the number of open review requests at which the reviewer stops being
+	// chosen for new reviews, unless every candidate has reached their limit. 0 means no limit.
+	maxOpenReviews int
 }
 
 var (
@@ -59,82 +56,22 @@ var (
 	usEastern, _ = time.LoadLocation("US/Eastern")
 	london, _    = time.LoadLocation("Europe/London")
 
-	// This is for the random-assignee rotation.
+	// This is for the random-assignee rotation. Vacations and other availability
+	// settings are in reviewer_availability.yaml.
 	reviewerRotation = map[string]ReviewerConfig{
-		"BBBmau": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 4, 7),
-					endDate:   newDate(2025, 4, 11),
-				},
-			},
-		},
-		"c2thorn": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 4, 9),
-					endDate:   newDate(2025, 4, 15),
-				},
-			},
-		},
-		"hao-nan-li": {
-			vacations: []Vacation{},
-		},
-		"melinath": {
-			vacations: []Vacation{},
-		},
-		"NickElliot": {
-			vacations: []Vacation{},
-		},
-		"rileykarson": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 2, 25),
-					endDate:   newDate(2025, 3, 10),
-				},
-			},
-		},
-		"roaks3": {
-			vacations: []Vacation{},
-		},
-		"ScottSuarez": {
-			vacations: []Vacation{},
-		},
-		"shuyama1": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 5, 23),
-					endDate:   newDate(2025, 5, 30),
-				},
-			},
-		},
-		"SirGitsalot": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 1, 18),
-					endDate:   newDate(2025, 1, 25),
-				},
-			},
-		},
-		"slevenick": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 5, 22),
-					endDate:   newDate(2025, 6, 7),
-				},
-			},
-		},
-		"trodge": {
-			vacations: []Vacation{},
-		},
-		"zli82016": {
-			vacations: []Vacation{
-				{
-					startDate: newDate(2025, 1, 15),
-					endDate:   newDate(2025, 2, 9),
-				},
-			},
-		},
+		"BBBmau":      {},
+		"c2thorn":     {},
+		"hao-nan-li":  {},
+		"melinath":    {},
+		"NickElliot":  {},
+		"rileykarson": {},
+		"roaks3":      {},
+		"ScottSuarez": {},
+		"shuyama1":    {},
+		"SirGitsalot": {},
+		"slevenick":   {},
+		"trodge":      {},
+		"zli82016":    {},
 	}
 
 	// This is for new team members who are onboarding
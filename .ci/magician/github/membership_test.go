@@ -253,3 +253,48 @@ func TestAvailable(t *testing.T) {
 	}
 
 }
+
+func TestReviewerAvailability(t *testing.T) {
+	configs, err := parseReviewerAvailability(reviewerAvailabilityYaml)
+	if err != nil {
+		t.Fatalf("reviewer_availability.yaml is invalid: %s", err)
+	}
+	for reviewer, config := range configs {
+		if len(config.vacations) == 0 && config.timezone == nil && config.maxOpenReviews == 0 {
+			t.Errorf("reviewer_availability.yaml has no settings for %s", reviewer)
+		}
+	}
+}
+
+func TestParseReviewerAvailability(t *testing.T) {
+	configs, err := parseReviewerAvailability([]byte(`
+id1:
+  timezone: US/Eastern
+  vacations:
+    - start: 2024-03-28
+      end: 2024-04-02
+  max_open_reviews: 10
+`))
+	if err != nil {
+		t.Fatalf("parseReviewerAvailability() returned error: %s", err)
+	}
+	want := ReviewerConfig{
+		timezone:       usEastern,
+		vacations:      []Vacation{{startDate: newDate(2024, 3, 28), endDate: newDate(2024, 4, 2)}},
+		maxOpenReviews: 10,
+	}
+	if diff := cmp.Diff(want, configs["id1"], cmp.AllowUnexported(ReviewerConfig{}, Vacation{}, date{}), cmp.Comparer(func(a, b *time.Location) bool { return a.String() == b.String() })); diff != "" {
+		t.Errorf("parseReviewerAvailability() returned unexpected difference (-want +got):\n%s", diff)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field":    "id1:\n  vacation: []\n",
+		"invalid timezone": "id1:\n  timezone: Nowhere/Nowhere\n",
+		"invalid date":     "id1:\n  vacations:\n    - start: 2024-13-01\n      end: 2024-13-02\n",
+		"end before start": "id1:\n  vacations:\n    - start: 2024-04-02\n      end: 2024-03-28\n",
+	} {
+		if _, err := parseReviewerAvailability([]byte(data)); err == nil {
+			t.Errorf("parseReviewerAvailability() with %s returned no error", name)
+		}
+	}
+}
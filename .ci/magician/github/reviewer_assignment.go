@@ -17,9 +17,14 @@ package github
 
 import (
 	"fmt"
+	"math/rand"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	_ "embed"
 )
@@ -29,10 +34,189 @@ var (
 	reviewerAssignmentComment string
 )
 
+const (
+	// Candidates with at most this many more open review requests than the
+	// least busy candidate are preferred if they reviewed the touched services
+	// before.
+	reviewerWorkloadSlack = 2
+
+	// Maximum number of recently closed pull requests whose reviewers are
+	// fetched to find reviewers with history on the touched services.
+	maxServiceHistoryPullRequests = 20
+)
+
+// ReviewerWorkload is the current load and recent history of reviewers.
+type ReviewerWorkload struct {
+	// Number of open pull requests each reviewer is requested to review.
+	OpenReviews map[string]int
+	// Number of recently closed pull requests each reviewer reviewed, by
+	// service label. Only the service labels of the pull request being
+	// assigned are included.
+	ServiceReviews map[string]map[string]int
+}
+
+// ReviewerAssignment is a reviewer chosen for a pull request and the data the
+// choice was based on.
+type ReviewerAssignment struct {
+	Reviewer string
+	// Number of available reviewers the reviewer was chosen from.
+	Candidates int
+	// Number of open pull requests the reviewer is requested to review.
+	OpenReviews int
+	// Service labels of the pull request that the reviewer recently reviewed
+	// pull requests for.
+	ServiceLabels []string
+}
+
+type pullRequestReader interface {
+	GetPullRequests(state, base, sort, direction string) ([]PullRequest, error)
+	GetAllPullRequests(state, base, sort, direction string) ([]PullRequest, error)
+	GetPullRequestPreviousReviewers(prNumber string) ([]User, error)
+}
+
+// ServiceLabels returns the service labels of a pull request.
+func ServiceLabels(pr PullRequest) []string {
+	var labels []string
+	for _, label := range pr.Labels {
+		if strings.HasPrefix(label.Name, "service/") && label.Name != "service/terraform" {
+			labels = append(labels, label.Name)
+		}
+	}
+	return labels
+}
+
+// GetReviewerWorkload counts the review requests of all open pull requests,
+// and the reviewers of recently closed pull requests with any of
+// serviceLabels.
+func GetReviewerWorkload(gh pullRequestReader, serviceLabels []string) (ReviewerWorkload, error) {
+	workload := ReviewerWorkload{
+		OpenReviews:    make(map[string]int),
+		ServiceReviews: make(map[string]map[string]int),
+	}
+	openPRs, err := gh.GetAllPullRequests("open", "main", "updated", "desc")
+	if err != nil {
+		return workload, err
+	}
+	for _, pr := range openPRs {
+		for _, reviewer := range pr.RequestedReviewers {
+			workload.OpenReviews[reviewer.Login]++
+		}
+	}
+
+	if len(serviceLabels) == 0 {
+		return workload, nil
+	}
+	closedPRs, err := gh.GetPullRequests("closed", "main", "updated", "desc")
+	if err != nil {
+		return workload, err
+	}
+	fetched := 0
+	for _, pr := range closedPRs {
+		var labels []string
+		for _, label := range ServiceLabels(pr) {
+			if slices.Contains(serviceLabels, label) {
+				labels = append(labels, label)
+			}
+		}
+		if len(labels) == 0 {
+			continue
+		}
+		if fetched == maxServiceHistoryPullRequests {
+			break
+		}
+		fetched++
+		reviewers, err := gh.GetPullRequestPreviousReviewers(strconv.Itoa(pr.Number))
+		if err != nil {
+			return workload, err
+		}
+		for _, reviewer := range reviewers {
+			if reviewer.Login == pr.User.Login {
+				continue
+			}
+			if workload.ServiceReviews[reviewer.Login] == nil {
+				workload.ServiceReviews[reviewer.Login] = make(map[string]int)
+			}
+			for _, label := range labels {
+				workload.ServiceReviews[reviewer.Login][label]++
+			}
+		}
+	}
+	return workload, nil
+}
+
+// ChooseReviewer chooses a reviewer among candidates. Reviewers on vacation
+// are skipped, and so are reviewers at their open review limit unless all
+// candidates are. Among the reviewers with the fewest open review requests
+// (within reviewerWorkloadSlack), the ones who reviewed the most pull requests
+// for the touched services are preferred, and ties are broken randomly.
+func ChooseReviewer(candidates []string, workload ReviewerWorkload) ReviewerAssignment {
+	return chooseReviewer(time.Now(), reviewerAvailability, candidates, workload)
+}
+
+func chooseReviewer(nowTime time.Time, availability map[string]ReviewerConfig, candidates []string, workload ReviewerWorkload) ReviewerAssignment {
+	var available, belowLimit []string
+	for _, candidate := range candidates {
+		config := availability[candidate]
+		if config.onVacation(nowTime) {
+			continue
+		}
+		available = append(available, candidate)
+		if config.maxOpenReviews == 0 || workload.OpenReviews[candidate] < config.maxOpenReviews {
+			belowLimit = append(belowLimit, candidate)
+		}
+	}
+	if len(belowLimit) > 0 {
+		available = belowLimit
+	}
+	if len(available) == 0 {
+		return ReviewerAssignment{}
+	}
+
+	leastOpenReviews := workload.OpenReviews[available[0]]
+	for _, candidate := range available {
+		leastOpenReviews = min(leastOpenReviews, workload.OpenReviews[candidate])
+	}
+	var best []string
+	bestServiceReviews := -1
+	for _, candidate := range available {
+		if workload.OpenReviews[candidate] > leastOpenReviews+reviewerWorkloadSlack {
+			continue
+		}
+		serviceReviews := 0
+		for _, n := range workload.ServiceReviews[candidate] {
+			serviceReviews += n
+		}
+		// Without history, prefer the least busy candidates.
+		if serviceReviews == 0 && workload.OpenReviews[candidate] > leastOpenReviews {
+			continue
+		}
+		switch {
+		case serviceReviews > bestServiceReviews:
+			best = []string{candidate}
+			bestServiceReviews = serviceReviews
+		case serviceReviews == bestServiceReviews:
+			best = append(best, candidate)
+		}
+	}
+
+	reviewer := best[rand.Intn(len(best))]
+	var serviceLabels []string
+	for label := range workload.ServiceReviews[reviewer] {
+		serviceLabels = append(serviceLabels, label)
+	}
+	sort.Strings(serviceLabels)
+	return ReviewerAssignment{
+		Reviewer:      reviewer,
+		Candidates:    len(available),
+		OpenReviews:   workload.OpenReviews[reviewer],
+		ServiceLabels: serviceLabels,
+	}
+}
+
 // Returns a list of users to request review from, as well as a new primary reviewer if this is the first run.
-func ChooseCoreReviewers(requestedReviewers, previousReviewers []User) (reviewersToRequest []string, newPrimaryReviewer string) {
+// The new primary reviewer is chosen from the available core reviewers based on workload.
+func ChooseCoreReviewers(requestedReviewers, previousReviewers []User, workload ReviewerWorkload) (reviewersToRequest []string, newPrimaryReviewer ReviewerAssignment) {
 	hasPrimaryReviewer := false
-	newPrimaryReviewer = ""
 
 	for _, reviewer := range requestedReviewers {
 		if IsCoreReviewer(reviewer.Login) {
@@ -49,22 +233,26 @@ func ChooseCoreReviewers(requestedReviewers, previousReviewers []User) (reviewer
 	}
 
 	if !hasPrimaryReviewer {
-		newPrimaryReviewer = GetRandomReviewer(nil)
-		reviewersToRequest = append(reviewersToRequest, newPrimaryReviewer)
+		newPrimaryReviewer = ChooseReviewer(AvailableReviewers(nil), workload)
+		if newPrimaryReviewer.Reviewer != "" {
+			reviewersToRequest = append(reviewersToRequest, newPrimaryReviewer.Reviewer)
+		}
 	}
 
 	return reviewersToRequest, newPrimaryReviewer
 }
 
-func FormatReviewerComment(newPrimaryReviewer string) string {
-	tmpl, err := template.New("REVIEWER_ASSIGNMENT_COMMENT.md").Parse(reviewerAssignmentComment)
+// FormatReviewerComment formats the comment announcing the primary reviewer.
+// The choice is explained if the reviewer was chosen from candidates.
+func FormatReviewerComment(newPrimaryReviewer ReviewerAssignment) string {
+	tmpl, err := template.New("REVIEWER_ASSIGNMENT_COMMENT.md").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(reviewerAssignmentComment)
 	if err != nil {
 		panic(fmt.Sprintf("Unable to parse REVIEWER_ASSIGNMENT_COMMENT.md: %s", err))
 	}
 	sb := new(strings.Builder)
-	tmpl.Execute(sb, map[string]any{
-		"reviewer": newPrimaryReviewer,
-	})
+	tmpl.Execute(sb, newPrimaryReviewer)
 	return sb.String()
 }
 
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/exp/slices"
 )
 
@@ -82,12 +83,12 @@ func TestChooseCoreReviewers(t *testing.T) {
 		tc := tc
 		t.Run(tn, func(t *testing.T) {
 			t.Parallel()
-			reviewers, primaryReviewer := ChooseCoreReviewers(tc.RequestedReviewers, tc.PreviousReviewers)
-			if tc.ExpectPrimaryReviewer && primaryReviewer == "" {
+			reviewers, primaryReviewer := ChooseCoreReviewers(tc.RequestedReviewers, tc.PreviousReviewers, ReviewerWorkload{})
+			if tc.ExpectPrimaryReviewer && primaryReviewer.Reviewer == "" {
 				t.Error("wanted primary reviewer to be returned; got none")
 			}
-			if !tc.ExpectPrimaryReviewer && primaryReviewer != "" {
-				t.Errorf("wanted no primary reviewer; got %s", primaryReviewer)
+			if !tc.ExpectPrimaryReviewer && primaryReviewer.Reviewer != "" {
+				t.Errorf("wanted no primary reviewer; got %s", primaryReviewer.Reviewer)
 			}
 			if len(tc.ExpectReviewersFromList) > 0 {
 				for _, reviewer := range reviewers {
@@ -132,7 +133,7 @@ func TestFormatReviewerComment(t *testing.T) {
 		tc := tc
 		t.Run(tn, func(t *testing.T) {
 			t.Parallel()
-			comment := FormatReviewerComment(tc.Reviewer)
+			comment := FormatReviewerComment(ReviewerAssignment{Reviewer: tc.Reviewer})
 			t.Log(comment)
 			if !strings.Contains(comment, fmt.Sprintf("@%s", tc.Reviewer)) {
 				t.Errorf("wanted comment to contain @%s; does not.", tc.Reviewer)
@@ -163,7 +164,7 @@ func TestFindReviewerComment(t *testing.T) {
 		"reviewer comment": {
 			Comments: []PullRequestComment{
 				{
-					Body: FormatReviewerComment("trodge"),
+					Body: FormatReviewerComment(ReviewerAssignment{Reviewer: "trodge"}),
 					ID:   1234,
 				},
 			},
@@ -173,17 +174,17 @@ func TestFindReviewerComment(t *testing.T) {
 		"multiple reviewer comments": {
 			Comments: []PullRequestComment{
 				{
-					Body:      FormatReviewerComment("trodge"),
+					Body:      FormatReviewerComment(ReviewerAssignment{Reviewer: "trodge"}),
 					ID:        1234,
 					CreatedAt: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
 				},
 				{
-					Body:      FormatReviewerComment("c2thorn"),
+					Body:      FormatReviewerComment(ReviewerAssignment{Reviewer: "c2thorn"}),
 					ID:        5678,
 					CreatedAt: time.Date(2023, 12, 3, 0, 0, 0, 0, time.UTC),
 				},
 				{
-					Body:      FormatReviewerComment("melinath"),
+					Body:      FormatReviewerComment(ReviewerAssignment{Reviewer: "melinath"}),
 					ID:        91011,
 					CreatedAt: time.Date(2023, 12, 2, 0, 0, 0, 0, time.UTC),
 				},
@@ -207,3 +208,187 @@ func TestFindReviewerComment(t *testing.T) {
 		})
 	}
 }
+
+func TestChooseReviewer(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	availability := map[string]ReviewerConfig{
+		"vacationing": {
+			timezone:  time.UTC,
+			vacations: []Vacation{{startDate: newDate(2024, 3, 29), endDate: newDate(2024, 4, 2)}},
+		},
+		"limited": {maxOpenReviews: 2},
+	}
+	cases := map[string]struct {
+		candidates []string
+		workload   ReviewerWorkload
+		want       []string
+	}{
+		"no candidates": {},
+		"all candidates on vacation": {
+			candidates: []string{"vacationing"},
+		},
+		"least busy reviewer": {
+			candidates: []string{"busy", "idle", "vacationing"},
+			workload: ReviewerWorkload{
+				OpenReviews: map[string]int{"busy": 3, "idle": 1},
+			},
+			want: []string{"idle"},
+		},
+		"ties are broken randomly": {
+			candidates: []string{"a", "b", "c"},
+			workload: ReviewerWorkload{
+				OpenReviews: map[string]int{"a": 1, "b": 1, "c": 2},
+			},
+			want: []string{"a", "b"},
+		},
+		"reviewer with service history within slack": {
+			candidates: []string{"idle", "expert"},
+			workload: ReviewerWorkload{
+				OpenReviews:    map[string]int{"expert": 2},
+				ServiceReviews: map[string]map[string]int{"expert": {"service/compute": 1}},
+			},
+			want: []string{"expert"},
+		},
+		"most service history": {
+			candidates: []string{"expert", "novice"},
+			workload: ReviewerWorkload{
+				ServiceReviews: map[string]map[string]int{"expert": {"service/compute": 3}, "novice": {"service/compute": 1}},
+			},
+			want: []string{"expert"},
+		},
+		"reviewer with service history beyond slack": {
+			candidates: []string{"idle", "expert"},
+			workload: ReviewerWorkload{
+				OpenReviews:    map[string]int{"expert": 3},
+				ServiceReviews: map[string]map[string]int{"expert": {"service/compute": 5}},
+			},
+			want: []string{"idle"},
+		},
+		"reviewer at limit": {
+			candidates: []string{"limited", "busy"},
+			workload: ReviewerWorkload{
+				OpenReviews: map[string]int{"limited": 2, "busy": 5},
+			},
+			want: []string{"busy"},
+		},
+		"everyone at limit": {
+			candidates: []string{"limited"},
+			workload: ReviewerWorkload{
+				OpenReviews: map[string]int{"limited": 2},
+			},
+			want: []string{"limited"},
+		},
+	}
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			got := chooseReviewer(now, availability, tc.candidates, tc.workload)
+			if len(tc.want) == 0 {
+				if got.Reviewer != "" {
+					t.Errorf("chooseReviewer() = %s, want no reviewer", got.Reviewer)
+				}
+				return
+			}
+			if !slices.Contains(tc.want, got.Reviewer) {
+				t.Errorf("chooseReviewer() = %s, want one of %v", got.Reviewer, tc.want)
+			}
+		})
+	}
+}
+
+func TestChooseReviewerAssignment(t *testing.T) {
+	workload := ReviewerWorkload{
+		OpenReviews:    map[string]int{"expert": 1, "busy": 4},
+		ServiceReviews: map[string]map[string]int{"expert": {"service/compute": 2, "service/cloudrun": 1}},
+	}
+	want := ReviewerAssignment{
+		Reviewer:      "expert",
+		Candidates:    3,
+		OpenReviews:   1,
+		ServiceLabels: []string{"service/cloudrun", "service/compute"},
+	}
+	got := chooseReviewer(time.Now(), nil, []string{"busy", "expert", "idle"}, workload)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("chooseReviewer() returned unexpected difference (-want +got):\n%s", diff)
+	}
+}
+
+type fakePullRequestReader struct {
+	pullRequests      map[string][]PullRequest
+	previousReviewers map[string][]User
+	fetched           []string
+}
+
+func (f *fakePullRequestReader) GetPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
+	if state == "open" {
+		// Open pull requests must all be counted, not just the first page.
+		return nil, fmt.Errorf("open pull requests listed without pagination")
+	}
+	return f.pullRequests[state], nil
+}
+
+func (f *fakePullRequestReader) GetAllPullRequests(state, base, sort, direction string) ([]PullRequest, error) {
+	return f.pullRequests[state], nil
+}
+
+func (f *fakePullRequestReader) GetPullRequestPreviousReviewers(prNumber string) ([]User, error) {
+	f.fetched = append(f.fetched, prNumber)
+	return f.previousReviewers[prNumber], nil
+}
+
+func TestGetReviewerWorkload(t *testing.T) {
+	gh := &fakePullRequestReader{
+		pullRequests: map[string][]PullRequest{
+			"open": {
+				{Number: 10, RequestedReviewers: []User{{Login: "alice"}, {Login: "bob"}}},
+				{Number: 11, RequestedReviewers: []User{{Login: "alice"}}},
+				{Number: 12},
+			},
+			"closed": {
+				{Number: 1, User: User{Login: "author"}, Labels: []Label{{Name: "service/compute"}, {Name: "service/storage"}}},
+				{Number: 2, User: User{Login: "author"}, Labels: []Label{{Name: "service/sql"}}},
+				{Number: 3, User: User{Login: "bob"}, Labels: []Label{{Name: "service/storage"}}},
+			},
+		},
+		previousReviewers: map[string][]User{
+			"1": {{Login: "alice"}},
+			"2": {{Login: "carol"}},
+			"3": {{Login: "alice"}, {Login: "bob"}},
+		},
+	}
+	want := ReviewerWorkload{
+		OpenReviews: map[string]int{"alice": 2, "bob": 1},
+		ServiceReviews: map[string]map[string]int{
+			"alice": {"service/compute": 1, "service/storage": 2},
+		},
+	}
+	got, err := GetReviewerWorkload(gh, []string{"service/compute", "service/storage"})
+	if err != nil {
+		t.Fatalf("GetReviewerWorkload() returned error: %s", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetReviewerWorkload() returned unexpected difference (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"1", "3"}, gh.fetched); diff != "" {
+		t.Errorf("GetReviewerWorkload() fetched reviewers of unexpected pull requests (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatReviewerCommentExplanation(t *testing.T) {
+	comment := FormatReviewerComment(ReviewerAssignment{
+		Reviewer:      "foobar",
+		Candidates:    5,
+		OpenReviews:   2,
+		ServiceLabels: []string{"service/cloudrun", "service/compute"},
+	})
+	want := "@foobar was chosen from 5 available maintainers based on current review load (2 open review requests) and recent reviews of `service/cloudrun`, `service/compute` changes."
+	if !strings.Contains(comment, want) {
+		t.Errorf("wanted comment to contain %q; got %s", want, comment)
+	}
+	if _, reviewer := FindReviewerComment([]PullRequestComment{{Body: comment}}); reviewer != "foobar" {
+		t.Errorf("wanted FindReviewerComment to find foobar; got %q", reviewer)
+	}
+
+	if comment := FormatReviewerComment(ReviewerAssignment{Reviewer: "foobar"}); strings.Contains(comment, "was chosen") {
+		t.Errorf("wanted comment for a given reviewer to have no explanation; got %s", comment)
+	}
+}
@@ -0,0 +1,256 @@
+package vcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Maximum length of request and response bodies shown in a diff.
+const maxDiffBodyLength = 1000
+
+// Cassette is a go-vcr (v1 format) cassette.
+type Cassette struct {
+	Version      int           `yaml:"version"`
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+type Interaction struct {
+	Request  CassetteRequest  `yaml:"request"`
+	Response CassetteResponse `yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Body    string              `yaml:"body"`
+	Form    map[string][]string `yaml:"form"`
+	Headers map[string][]string `yaml:"headers"`
+	URL     string              `yaml:"url"`
+	Method  string              `yaml:"method"`
+}
+
+type CassetteResponse struct {
+	Body     string              `yaml:"body"`
+	Headers  map[string][]string `yaml:"headers"`
+	Status   string              `yaml:"status"`
+	Code     int                 `yaml:"code"`
+	Duration string              `yaml:"duration"`
+}
+
+// CassetteDiff is the difference between the base cassette of a test and the
+// cassette recorded for a PR. API calls are identified by method and URL,
+// after random names and server-generated IDs are normalized.
+type CassetteDiff struct {
+	Name          string // cassette name, usually the test name
+	NewCassette   bool   // the base branch has no cassette with this name
+	AddedCalls    []string
+	RemovedCalls  []string
+	ChangedBodies []BodyChange
+	NewErrors     []ErrorResponse
+}
+
+// BodyChange is a request body that differs between the base cassette and
+// the recorded cassette.
+type BodyChange struct {
+	Call     string
+	Base     string
+	Recorded string
+}
+
+// ErrorResponse is an error response in the recorded cassette that the same
+// call didn't get in the base cassette.
+type ErrorResponse struct {
+	Call   string
+	Status string
+	Body   string
+}
+
+// Empty returns whether the recorded cassette makes the same API calls as the
+// base cassette.
+func (d CassetteDiff) Empty() bool {
+	return !d.NewCassette && len(d.AddedCalls) == 0 && len(d.RemovedCalls) == 0 && len(d.ChangedBodies) == 0 && len(d.NewErrors) == 0
+}
+
+// Replacements applied in order to URLs and bodies, so that recordings of the
+// same test in different runs compare equal.
+var cassetteNormalizers = []struct {
+	regexp      *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\btf[-_]test[-_\w]*`), "tf-test-<random>"},
+	{regexp.MustCompile(`\boperations/[^/\s"'?&]+`), "operations/<operation>"},
+	{regexp.MustCompile(`\boperation-[\w-]+`), "operation-<operation>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})?`), "<timestamp>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{10,}\b`), "<number>"},
+}
+
+func normalizeCassetteText(text string) string {
+	for _, n := range cassetteNormalizers {
+		text = n.regexp.ReplaceAllString(text, n.replacement)
+	}
+	return text
+}
+
+// normalizeBody normalizes random values in a body and, for JSON bodies,
+// formatting and key order.
+func normalizeBody(body string) string {
+	body = normalizeCassetteText(body)
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return strings.TrimSpace(body)
+	}
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return body
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func truncateBody(body string) string {
+	if len(body) > maxDiffBodyLength {
+		return body[:maxDiffBodyLength] + "..."
+	}
+	return body
+}
+
+// ParseCassette parses a go-vcr cassette.
+func ParseCassette(data []byte) (Cassette, error) {
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return Cassette{}, err
+	}
+	return c, nil
+}
+
+// Marshal formats the cassette the way go-vcr saves it.
+func (c Cassette) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("---\n"), data...), nil
+}
+
+type cassetteCall struct {
+	key      string
+	body     string
+	code     int
+	status   string
+	response string
+}
+
+// calls returns the normalized API calls of the cassette. Repeated calls, such
+// as polling an operation, are counted once since how often they happen
+// depends on timing.
+func (c Cassette) calls() []cassetteCall {
+	var calls []cassetteCall
+	for _, i := range c.Interactions {
+		call := cassetteCall{
+			key:      i.Request.Method + " " + normalizeCassetteText(i.Request.URL),
+			body:     normalizeBody(i.Request.Body),
+			code:     i.Response.Code,
+			status:   i.Response.Status,
+			response: normalizeBody(i.Response.Body),
+		}
+		if len(calls) > 0 {
+			last := calls[len(calls)-1]
+			if last.key == call.key && last.body == call.body && last.code == call.code {
+				continue
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// DiffCassettes compares the API calls in a recorded cassette with the ones in
+// the base cassette. Calls are paired up in order for each method and URL.
+func DiffCassettes(name string, base, recorded Cassette) CassetteDiff {
+	diff := CassetteDiff{Name: name}
+	baseCalls := base.calls()
+	unpaired := make(map[string][]int)
+	for i, call := range baseCalls {
+		unpaired[call.key] = append(unpaired[call.key], i)
+	}
+	paired := make(map[int]bool)
+	for _, call := range recorded.calls() {
+		indices := unpaired[call.key]
+		if len(indices) == 0 {
+			diff.AddedCalls = append(diff.AddedCalls, call.key)
+			if call.code >= 400 {
+				diff.NewErrors = append(diff.NewErrors, ErrorResponse{Call: call.key, Status: call.status, Body: truncateBody(call.response)})
+			}
+			continue
+		}
+		baseCall := baseCalls[indices[0]]
+		unpaired[call.key] = indices[1:]
+		paired[indices[0]] = true
+		if call.body != baseCall.body {
+			diff.ChangedBodies = append(diff.ChangedBodies, BodyChange{
+				Call:     call.key,
+				Base:     truncateBody(baseCall.body),
+				Recorded: truncateBody(call.body),
+			})
+		}
+		if call.code >= 400 && call.code != baseCall.code {
+			diff.NewErrors = append(diff.NewErrors, ErrorResponse{Call: call.key, Status: call.status, Body: truncateBody(call.response)})
+		}
+	}
+	for i, call := range baseCalls {
+		if !paired[i] {
+			diff.RemovedCalls = append(diff.RemovedCalls, call.key)
+		}
+	}
+	return diff
+}
+
+// DiffCassetteDirs compares each cassette in recordedDir with the cassette of
+// the same name in baseDir, and returns the non-empty diffs sorted by name.
+func DiffCassetteDirs(rnr ExecRunner, baseDir, recordedDir string) ([]CassetteDiff, error) {
+	var diffs []CassetteDiff
+	err := rnr.Walk(recordedDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		data, err := rnr.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		recorded, err := ParseCassette([]byte(data))
+		if err != nil {
+			return fmt.Errorf("error parsing cassette %s: %w", path, err)
+		}
+		baseData, err := rnr.ReadFile(filepath.Join(baseDir, filepath.Base(path)))
+		if err != nil {
+			diffs = append(diffs, CassetteDiff{Name: name, NewCassette: true})
+			return nil
+		}
+		base, err := ParseCassette([]byte(baseData))
+		if err != nil {
+			return fmt.Errorf("error parsing base cassette for %s: %w", name, err)
+		}
+		if diff := DiffCassettes(name, base, recorded); !diff.Empty() {
+			diffs = append(diffs, diff)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs, nil
+}
@@ -0,0 +1,186 @@
+package vcr
+
+import (
+	"magician/exec"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const baseCassette = `---
+version: 1
+interactions:
+- request:
+    body: |
+      {"name":"projects/my-project/topics/tf-test-topicabc123def0","labels":{"foo":"bar"}}
+    form: {}
+    headers:
+      Content-Type:
+      - application/json
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicabc123def0?alt=json
+    method: PUT
+  response:
+    body: '{"name":"projects/my-project/topics/tf-test-topicabc123def0"}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+- request:
+    body: ""
+    form: {}
+    headers: {}
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicabc123def0?alt=json
+    method: GET
+  response:
+    body: '{"name":"projects/my-project/topics/tf-test-topicabc123def0"}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+- request:
+    body: ""
+    form: {}
+    headers: {}
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicabc123def0?alt=json
+    method: DELETE
+  response:
+    body: '{}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+`
+
+const recordedCassette = `---
+version: 1
+interactions:
+- request:
+    body: |
+      {"labels":{"foo":"baz"},"name":"projects/my-project/topics/tf-test-topicxyz789uvw1"}
+    form: {}
+    headers:
+      Content-Type:
+      - application/json
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicxyz789uvw1?alt=json
+    method: PUT
+  response:
+    body: '{"name":"projects/my-project/topics/tf-test-topicxyz789uvw1"}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+- request:
+    body: ""
+    form: {}
+    headers: {}
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicxyz789uvw1?alt=json
+    method: GET
+  response:
+    body: '{"name":"projects/my-project/topics/tf-test-topicxyz789uvw1"}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+- request:
+    body: ""
+    form: {}
+    headers: {}
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicxyz789uvw1?alt=json
+    method: GET
+  response:
+    body: '{"name":"projects/my-project/topics/tf-test-topicxyz789uvw1"}'
+    headers: {}
+    status: 200 OK
+    code: 200
+    duration: ""
+- request:
+    body: '{"updateMask":"labels"}'
+    form: {}
+    headers: {}
+    url: https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-topicxyz789uvw1?alt=json
+    method: PATCH
+  response:
+    body: '{"error":{"code":400,"message":"Invalid update mask"}}'
+    headers: {}
+    status: 400 Bad Request
+    code: 400
+    duration: ""
+`
+
+func TestDiffCassettes(t *testing.T) {
+	base, err := ParseCassette([]byte(baseCassette))
+	if err != nil {
+		t.Fatalf("ParseCassette() error: %s", err)
+	}
+	recorded, err := ParseCassette([]byte(recordedCassette))
+	if err != nil {
+		t.Fatalf("ParseCassette() error: %s", err)
+	}
+
+	topicURL := "https://pubsub.googleapis.com/v1/projects/my-project/topics/tf-test-<random>?alt=json"
+	want := CassetteDiff{
+		Name:         "TestAccPubsubTopic_update",
+		AddedCalls:   []string{"PATCH " + topicURL},
+		RemovedCalls: []string{"DELETE " + topicURL},
+		ChangedBodies: []BodyChange{{
+			Call:     "PUT " + topicURL,
+			Base:     `{"labels":{"foo":"bar"},"name":"projects/my-project/topics/tf-test-<random>"}`,
+			Recorded: `{"labels":{"foo":"baz"},"name":"projects/my-project/topics/tf-test-<random>"}`,
+		}},
+		NewErrors: []ErrorResponse{{
+			Call:   "PATCH " + topicURL,
+			Status: "400 Bad Request",
+			Body:   `{"error":{"code":400,"message":"Invalid update mask"}}`,
+		}},
+	}
+	if diff := cmp.Diff(want, DiffCassettes("TestAccPubsubTopic_update", base, recorded)); diff != "" {
+		t.Errorf("DiffCassettes() returned unexpected difference (-want +got):\n%s", diff)
+	}
+
+	if got := DiffCassettes("TestAccPubsubTopic_update", base, base); !got.Empty() {
+		t.Errorf("DiffCassettes() of the same cassette = %+v, want empty", got)
+	}
+}
+
+func TestDiffCassetteDirs(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "base")
+	recordedDir := filepath.Join(dir, "recorded")
+	for path, content := range map[string]string{
+		filepath.Join(baseDir, "TestAccPubsubTopic_update.yaml"):      baseCassette,
+		filepath.Join(baseDir, "TestAccPubsubTopic_basic.yaml"):       baseCassette,
+		filepath.Join(recordedDir, "TestAccPubsubTopic_update.yaml"):  recordedCassette,
+		filepath.Join(recordedDir, "TestAccPubsubTopic_update.seed"):  "123",
+		filepath.Join(recordedDir, "TestAccPubsubTopic_basic.yaml"):   baseCassette,
+		filepath.Join(recordedDir, "TestAccPubsubTopic_new.yaml"):     baseCassette,
+		filepath.Join(recordedDir, "TestAccPubsubTopic_new_sub.yaml"): baseCassette,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rnr, err := exec.NewRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := DiffCassetteDirs(rnr, baseDir, recordedDir)
+	if err != nil {
+		t.Fatalf("DiffCassetteDirs() error: %s", err)
+	}
+	var names []string
+	for _, d := range diffs {
+		names = append(names, d.Name)
+	}
+	if diff := cmp.Diff([]string{"TestAccPubsubTopic_new", "TestAccPubsubTopic_new_sub", "TestAccPubsubTopic_update"}, names); diff != "" {
+		t.Errorf("DiffCassetteDirs() returned unexpected cassettes (-want +got):\n%s", diff)
+	}
+	if !diffs[0].NewCassette {
+		t.Errorf("DiffCassetteDirs() didn't report %s as a new cassette", diffs[0].Name)
+	}
+}
@@ -0,0 +1,305 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secret values in scrubbed cassettes.
+const Redacted = "REDACTED"
+
+// ProjectNumberPlaceholder replaces the test project number in scrubbed
+// cassettes. The provider's VCR setup swaps it back for GOOGLE_PROJECT_NUMBER
+// when replaying, so that requests still match.
+const ProjectNumberPlaceholder = "000000000000"
+
+// ScrubRules configures what is redacted from cassettes. The provider's
+// recorder (acctest/vcr_utils.go) applies the same default rules and reads
+// the same format from VCR_SCRUB_RULES. TestDefaultScrubRulesMatchProvider
+// keeps the two copies of the defaults in sync, and TestScrubMatchesProvider
+// checks that both scrub a cassette the same way.
+type ScrubRules struct {
+	// Request and response headers whose values are redacted.
+	Headers []string `json:"headers"`
+	// Response body JSON fields whose values are redacted, as dot-separated
+	// paths from the top-level object, such as "keys.privateKeyData". Arrays
+	// are traversed transparently. A leading ".." matches at any depth.
+	BodyPaths []string `json:"body_paths"`
+	// Regular expressions whose matches are redacted from response bodies
+	// and from headers.
+	Patterns []string `json:"patterns"`
+}
+
+var DefaultScrubRules = ScrubRules{
+	Headers: []string{"Authorization", "Cookie", "Set-Cookie", "X-Goog-Api-Key"},
+	BodyPaths: []string{
+		"..privateKeyData",
+		"..privateKey",
+		"..private_key",
+		"..accessToken",
+		"..access_token",
+		"..refreshToken",
+		"..refresh_token",
+		"..idToken",
+		"..id_token",
+		"..clientSecret",
+		"..client_secret",
+	},
+	Patterns: []string{
+		// OAuth access tokens
+		`ya29\.[\w.-]+`,
+		// API keys
+		`AIza[\w-]{35}`,
+		// PEM private keys, such as the ones in service account key files
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	},
+}
+
+// LoadScrubRules returns the default rules extended with the rules in the
+// JSON file at path.
+func LoadScrubRules(path string) (ScrubRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScrubRules{}, err
+	}
+	var extra ScrubRules
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return ScrubRules{}, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return ScrubRules{
+		Headers:   append(append([]string{}, DefaultScrubRules.Headers...), extra.Headers...),
+		BodyPaths: append(append([]string{}, DefaultScrubRules.BodyPaths...), extra.BodyPaths...),
+		Patterns:  append(append([]string{}, DefaultScrubRules.Patterns...), extra.Patterns...),
+	}, nil
+}
+
+// Finding is a secret found in a cassette.
+type Finding struct {
+	Interaction int // index of the interaction in the cassette
+	Location    string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("interaction %d: %s", f.Interaction, f.Location)
+}
+
+type jsonPath struct {
+	raw      string
+	anyDepth bool
+	keys     []string
+}
+
+// Scrubber redacts secrets and the test project number from cassettes.
+type Scrubber struct {
+	headers       map[string]bool
+	paths         []jsonPath
+	patterns      []*regexp.Regexp
+	projectNumber *regexp.Regexp
+}
+
+// NewScrubber compiles the rules. If projectNumber is set, it is replaced with
+// ProjectNumberPlaceholder everywhere in the cassette.
+func NewScrubber(rules ScrubRules, projectNumber string) (*Scrubber, error) {
+	s := &Scrubber{headers: make(map[string]bool)}
+	for _, h := range rules.Headers {
+		s.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, p := range rules.BodyPaths {
+		path := jsonPath{raw: p}
+		if rest, ok := strings.CutPrefix(p, ".."); ok {
+			path.anyDepth = true
+			p = rest
+		}
+		if p == "" {
+			return nil, fmt.Errorf("empty body path %q", path.raw)
+		}
+		path.keys = strings.Split(p, ".")
+		s.paths = append(s.paths, path)
+	}
+	for _, p := range rules.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling pattern %q: %w", p, err)
+		}
+		s.patterns = append(s.patterns, re)
+	}
+	if projectNumber != "" && projectNumber != ProjectNumberPlaceholder {
+		s.projectNumber = regexp.MustCompile(`\b` + regexp.QuoteMeta(projectNumber) + `\b`)
+	}
+	return s, nil
+}
+
+// Scrub redacts secrets in the cassette and returns where they were found.
+// Request URLs and bodies are only changed for the project number, since
+// replaying matches requests on them.
+func (s *Scrubber) Scrub(c *Cassette) []Finding {
+	var findings []Finding
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		found := func(location string) {
+			findings = append(findings, Finding{Interaction: i, Location: location})
+		}
+		interaction.Request.URL = s.replaceProjectNumber(interaction.Request.URL, "request url", found)
+		interaction.Request.Body = s.replaceProjectNumber(interaction.Request.Body, "request body", found)
+		for key, values := range interaction.Request.Form {
+			for j := range values {
+				values[j] = s.replaceProjectNumber(values[j], "request form "+key, found)
+			}
+		}
+		s.scrubHeaders(interaction.Request.Headers, "request", found)
+		s.scrubHeaders(interaction.Response.Headers, "response", found)
+		interaction.Response.Body = s.scrubBody(interaction.Response.Body, found)
+	}
+	return findings
+}
+
+func (s *Scrubber) replaceProjectNumber(text, location string, found func(string)) string {
+	if s.projectNumber == nil || !s.projectNumber.MatchString(text) {
+		return text
+	}
+	found(location + " contains the project number")
+	return s.projectNumber.ReplaceAllString(text, ProjectNumberPlaceholder)
+}
+
+func (s *Scrubber) redactPatterns(text, location string, found func(string)) string {
+	for _, re := range s.patterns {
+		if re.MatchString(text) {
+			found(fmt.Sprintf("%s matches %q", location, re.String()))
+			text = re.ReplaceAllString(text, Redacted)
+		}
+	}
+	return s.replaceProjectNumber(text, location, found)
+}
+
+func (s *Scrubber) scrubHeaders(headers map[string][]string, kind string, found func(string)) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := headers[key]
+		location := fmt.Sprintf("%s header %s", kind, key)
+		for j, v := range values {
+			if s.headers[http.CanonicalHeaderKey(key)] {
+				if v != Redacted {
+					found(location)
+					values[j] = Redacted
+				}
+				continue
+			}
+			values[j] = s.redactPatterns(v, location, found)
+		}
+	}
+}
+
+func (s *Scrubber) scrubBody(body string, found func(string)) string {
+	if len(s.paths) > 0 {
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err == nil && !dec.More() {
+			changed := false
+			for _, path := range s.paths {
+				path.redact(v, func() {
+					changed = true
+					found("response body " + path.raw)
+				})
+			}
+			if changed {
+				var buf bytes.Buffer
+				enc := json.NewEncoder(&buf)
+				enc.SetEscapeHTML(false)
+				if err := enc.Encode(v); err == nil {
+					body = strings.TrimSuffix(buf.String(), "\n")
+				}
+			}
+		}
+	}
+	return s.redactPatterns(body, "response body", found)
+}
+
+func (p jsonPath) redact(v any, found func()) {
+	redactKeys(v, p.keys, found)
+	if !p.anyDepth {
+		return
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for _, child := range v {
+			p.redact(child, found)
+		}
+	case []any:
+		for _, child := range v {
+			p.redact(child, found)
+		}
+	}
+}
+
+func redactKeys(v any, keys []string, found func()) {
+	switch v := v.(type) {
+	case map[string]any:
+		child, ok := v[keys[0]]
+		if !ok {
+			return
+		}
+		if len(keys) == 1 {
+			if child != Redacted {
+				v[keys[0]] = Redacted
+				found()
+			}
+			return
+		}
+		redactKeys(child, keys[1:], found)
+	case []any:
+		for _, child := range v {
+			redactKeys(child, keys, found)
+		}
+	}
+}
+
+// ScrubCassetteDir scrubs every cassette in dir and returns the findings by
+// cassette file name. Cassettes are only rewritten if write is set.
+func ScrubCassetteDir(rnr ExecRunner, dir string, s *Scrubber, write bool) (map[string][]Finding, error) {
+	findings := make(map[string][]Finding)
+	err := rnr.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		data, err := rnr.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c, err := ParseCassette([]byte(data))
+		if err != nil {
+			return fmt.Errorf("error parsing cassette %s: %w", path, err)
+		}
+		f := s.Scrub(&c)
+		if len(f) == 0 {
+			return nil
+		}
+		findings[filepath.Base(path)] = f
+		if !write {
+			return nil
+		}
+		scrubbed, err := c.Marshal()
+		if err != nil {
+			return fmt.Errorf("error formatting cassette %s: %w", path, err)
+		}
+		return rnr.WriteFile(path, string(scrubbed))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
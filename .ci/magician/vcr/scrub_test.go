@@ -0,0 +1,265 @@
+package vcr
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"magician/exec"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const secretCassette = `---
+version: 1
+interactions:
+- request:
+    body: '{"privateKeyType":"TYPE_GOOGLE_CREDENTIALS_FILE"}'
+    form: {}
+    headers:
+      Authorization:
+      - Bearer ya29.a0AfB_byC-secret
+      Content-Type:
+      - application/json
+    url: https://iam.googleapis.com/v1/projects/123456789012/serviceAccounts/sa@my-project.iam.gserviceaccount.com/keys?alt=json
+    method: POST
+  response:
+    body: '{"keys":[{"name":"projects/123456789012/serviceAccounts/sa/keys/abc","privateKeyData":"ZXlKaGJHY2lPaUpTVXpJMU5pSjk="}],"token":"ya29.c.b0Aaekm1K-secret"}'
+    headers:
+      Content-Type:
+      - application/json; charset=UTF-8
+      Set-Cookie:
+      - NID=secret
+    status: 200 OK
+    code: 200
+    duration: ""
+`
+
+func TestScrub(t *testing.T) {
+	c, err := ParseCassette([]byte(secretCassette))
+	if err != nil {
+		t.Fatalf("ParseCassette() error: %s", err)
+	}
+	s, err := NewScrubber(DefaultScrubRules, "123456789012")
+	if err != nil {
+		t.Fatalf("NewScrubber() error: %s", err)
+	}
+
+	var got []string
+	for _, f := range s.Scrub(&c) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"interaction 0: request url contains the project number",
+		"interaction 0: request header Authorization",
+		"interaction 0: response header Set-Cookie",
+		"interaction 0: response body ..privateKeyData",
+		`interaction 0: response body matches "ya29\\.[\\w.-]+"`,
+		"interaction 0: response body contains the project number",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Scrub() returned unexpected findings (-want +got):\n%s", diff)
+	}
+
+	i := c.Interactions[0]
+	if want := "https://iam.googleapis.com/v1/projects/000000000000/serviceAccounts/sa@my-project.iam.gserviceaccount.com/keys?alt=json"; i.Request.URL != want {
+		t.Errorf("Scrub() request url = %q, want %q", i.Request.URL, want)
+	}
+	if want := `{"privateKeyType":"TYPE_GOOGLE_CREDENTIALS_FILE"}`; i.Request.Body != want {
+		t.Errorf("Scrub() request body = %q, want %q", i.Request.Body, want)
+	}
+	if want := `{"keys":[{"name":"projects/000000000000/serviceAccounts/sa/keys/abc","privateKeyData":"REDACTED"}],"token":"REDACTED"}`; i.Response.Body != want {
+		t.Errorf("Scrub() response body = %q, want %q", i.Response.Body, want)
+	}
+	if diff := cmp.Diff([]string{"application/json; charset=UTF-8"}, i.Response.Headers["Content-Type"]); diff != "" {
+		t.Errorf("Scrub() changed an unrelated header (-want +got):\n%s", diff)
+	}
+
+	// Scrubbing is idempotent, so checking a scrubbed cassette finds nothing.
+	if findings := s.Scrub(&c); len(findings) != 0 {
+		t.Errorf("Scrub() of a scrubbed cassette returned findings: %v", findings)
+	}
+}
+
+func TestScrubBodyPaths(t *testing.T) {
+	s, err := NewScrubber(ScrubRules{BodyPaths: []string{"credentials.password", "..secret"}}, "")
+	if err != nil {
+		t.Fatalf("NewScrubber() error: %s", err)
+	}
+	c := Cassette{Interactions: []Interaction{{Response: CassetteResponse{
+		Body: `{"credentials":[{"password":"p1","user":"u1"}],"nested":{"credentials":{"password":"p2"},"list":[{"secret":"s1"}]},"size":12345678901234567890}`,
+	}}}}
+	s.Scrub(&c)
+	want := `{"credentials":[{"password":"REDACTED","user":"u1"}],"nested":{"credentials":{"password":"p2"},"list":[{"secret":"REDACTED"}]},"size":12345678901234567890}`
+	if got := c.Interactions[0].Response.Body; got != want {
+		t.Errorf("Scrub() response body = %q, want %q", got, want)
+	}
+}
+
+func TestLoadScrubRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{"headers":["X-Custom-Token"],"patterns":["sk-[a-z0-9]+"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadScrubRules(path)
+	if err != nil {
+		t.Fatalf("LoadScrubRules() error: %s", err)
+	}
+	if got, want := rules.Headers[len(rules.Headers)-1], "X-Custom-Token"; got != want {
+		t.Errorf("LoadScrubRules() last header = %q, want %q", got, want)
+	}
+	if len(rules.BodyPaths) != len(DefaultScrubRules.BodyPaths) {
+		t.Errorf("LoadScrubRules() body paths = %v, want the defaults", rules.BodyPaths)
+	}
+	if _, err := NewScrubber(ScrubRules{Patterns: []string{"("}}, ""); err == nil {
+		t.Errorf("NewScrubber() with an invalid pattern didn't return an error")
+	}
+}
+
+func TestScrubCassetteDir(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "TestAccServiceAccountKey_basic.yaml")
+	if err := os.WriteFile(secretPath, []byte(secretCassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "TestAccPubsubTopic_update.yaml"), []byte(baseCassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rnr, err := exec.NewRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScrubber(DefaultScrubRules, "123456789012")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := ScrubCassetteDir(rnr, dir, s, false)
+	if err != nil {
+		t.Fatalf("ScrubCassetteDir() error: %s", err)
+	}
+	if len(findings) != 1 || len(findings["TestAccServiceAccountKey_basic.yaml"]) == 0 {
+		t.Errorf("ScrubCassetteDir() findings = %v, want findings for TestAccServiceAccountKey_basic.yaml only", findings)
+	}
+	if data, _ := os.ReadFile(secretPath); string(data) != secretCassette {
+		t.Errorf("ScrubCassetteDir() without write changed the cassette")
+	}
+
+	if _, err := ScrubCassetteDir(rnr, dir, s, true); err != nil {
+		t.Fatalf("ScrubCassetteDir() error: %s", err)
+	}
+	data, err := os.ReadFile(secretPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"ya29.", "ZXlKaGJHY2lPaUpTVXpJMU5pSjk=", "NID=secret", "123456789012"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("ScrubCassetteDir() left %q in the cassette:\n%s", secret, data)
+		}
+	}
+	if findings, err := ScrubCassetteDir(rnr, dir, s, false); err != nil || len(findings) != 0 {
+		t.Errorf("ScrubCassetteDir() after scrubbing = %v, %v, want no findings", findings, err)
+	}
+}
+
+// providerScrubRulesFile is the provider's copy of the default scrub rules,
+// which is generated into a separate module and can't be imported here.
+const providerScrubRulesFile = "../../../mmv1/third_party/terraform/acctest/vcr_scrub.go"
+
+// TestDefaultScrubRulesMatchProvider checks that cassettes are checked with the
+// same default rules that the provider's recorder redacts them with.
+func TestDefaultScrubRulesMatchProvider(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), providerScrubRulesFile, nil, 0)
+	if err != nil {
+		t.Fatalf("error parsing %s: %s", providerScrubRulesFile, err)
+	}
+	var providerRules *ScrubRules
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "DefaultVcrScrubRules" || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			t.Fatalf("DefaultVcrScrubRules in %s isn't a composite literal", providerScrubRulesFile)
+		}
+		providerRules = &ScrubRules{}
+		for _, elt := range lit.Elts {
+			kv := elt.(*ast.KeyValueExpr)
+			values := stringLiterals(t, kv.Value)
+			switch key := kv.Key.(*ast.Ident).Name; key {
+			case "Headers":
+				providerRules.Headers = values
+			case "BodyPaths":
+				providerRules.BodyPaths = values
+			case "Patterns":
+				providerRules.Patterns = values
+			default:
+				t.Errorf("unexpected field %s in DefaultVcrScrubRules", key)
+			}
+		}
+		return false
+	})
+	if providerRules == nil {
+		t.Fatalf("DefaultVcrScrubRules not found in %s", providerScrubRulesFile)
+	}
+	if diff := cmp.Diff(DefaultScrubRules, *providerRules); diff != "" {
+		t.Errorf("DefaultScrubRules differ from the provider's DefaultVcrScrubRules (-magician +provider):\n%s", diff)
+	}
+}
+
+// providerScrubTestdata holds a cassette and the result of scrubbing it with
+// project number 123456789012. The provider's TestScrubCassetteGolden checks
+// its scrubber against the same files.
+const providerScrubTestdata = "../../../mmv1/third_party/terraform/acctest/testdata/scrub"
+
+// TestScrubMatchesProvider checks that the magician scrubs a cassette the same
+// way as the provider's recorder.
+func TestScrubMatchesProvider(t *testing.T) {
+	readCassette := func(name string) Cassette {
+		data, err := os.ReadFile(filepath.Join(providerScrubTestdata, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ParseCassette(data)
+		if err != nil {
+			t.Fatalf("ParseCassette(%s) error: %s", name, err)
+		}
+		return c
+	}
+	c := readCassette("cassette.yaml")
+	s, err := NewScrubber(DefaultScrubRules, "123456789012")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Scrub(&c)
+	if diff := cmp.Diff(readCassette("scrubbed.yaml"), c); diff != "" {
+		t.Errorf("Scrub() differs from the provider's scrubbed cassette (-provider +magician):\n%s", diff)
+	}
+}
+
+// stringLiterals returns the values of a []string composite literal.
+func stringLiterals(t *testing.T, expr ast.Expr) []string {
+	t.Helper()
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		t.Fatalf("expected a []string literal, got %T", expr)
+	}
+	var values []string
+	for _, elt := range lit.Elts {
+		basic, ok := elt.(*ast.BasicLit)
+		if !ok || basic.Kind != token.STRING {
+			t.Fatalf("expected a string literal, got %T", elt)
+		}
+		value, err := strconv.Unquote(basic.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,131 @@
+package vcr
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Number of output lines kept for each failing test.
+const failureExcerptLines = 30
+
+// A test2json event, as printed by go test -json.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+type testState struct {
+	pkg         string
+	action      string
+	elapsed     float64
+	output      []string
+	hasSubtests bool
+}
+
+// collectResult builds a Result from the output of go test -json. Lines that
+// aren't test2json events, such as build errors or the error wrapping the
+// output, are kept as plain text. It also returns the output as it would have
+// been printed by go test -v, for logs.
+func collectResult(output string) (Result, string) {
+	tests := make(map[string]*testState)
+	var order []string
+	var panics []string
+	var text strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		var event testEvent
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &event) != nil {
+			if line != "" {
+				text.WriteString(line + "\n")
+			}
+			if strings.HasPrefix(line, "panic: ") {
+				panics = append(panics, line)
+			}
+			continue
+		}
+		text.WriteString(event.Output)
+		if strings.HasPrefix(event.Output, "panic: ") {
+			panics = append(panics, strings.TrimSuffix(event.Output, "\n"))
+		}
+		if event.Test == "" {
+			continue
+		}
+		state, ok := tests[event.Test]
+		if !ok {
+			state = &testState{pkg: event.Package}
+			tests[event.Test] = state
+			order = append(order, event.Test)
+		}
+		switch event.Action {
+		case "output":
+			if !strings.HasPrefix(event.Output, "=== ") {
+				state.output = append(state.output, strings.TrimSuffix(event.Output, "\n"))
+			}
+		case "pass", "fail", "skip":
+			state.action = event.Action
+			state.elapsed = event.Elapsed
+		}
+	}
+	for _, name := range order {
+		for parent := name; strings.Contains(parent, "/"); {
+			parent = parent[:strings.LastIndex(parent, "/")]
+			if state, ok := tests[parent]; ok {
+				state.hasSubtests = true
+			}
+		}
+	}
+
+	results := make(map[string][]string, 3)
+	subtestResults := make(map[string][]string, 3)
+	var details map[string]TestDetail
+	for name, state := range tests {
+		if !strings.HasPrefix(name, "TestAcc") || state.action == "" {
+			continue
+		}
+		resultName := name
+		if strings.Contains(name, "/") {
+			if state.hasSubtests {
+				// Only report the innermost subtests.
+				continue
+			}
+			resultName = strings.ReplaceAll(name, "/", "__")
+			subtestResults[state.action] = append(subtestResults[state.action], resultName)
+		} else {
+			results[state.action] = append(results[state.action], resultName)
+		}
+		detail := TestDetail{
+			Package: state.pkg,
+			Elapsed: time.Duration(state.elapsed * float64(time.Second)),
+		}
+		if state.action == "fail" {
+			excerpt := state.output
+			if len(excerpt) > failureExcerptLines {
+				excerpt = excerpt[len(excerpt)-failureExcerptLines:]
+			}
+			detail.Output = strings.Join(excerpt, "\n")
+		}
+		if details == nil {
+			details = make(map[string]TestDetail)
+		}
+		details[resultName] = detail
+	}
+	for _, kind := range []string{"fail", "pass", "skip"} {
+		sort.Strings(results[kind])
+		sort.Strings(subtestResults[kind])
+	}
+	sort.Strings(panics)
+	return Result{
+		FailedTests:     results["fail"],
+		PassedTests:     results["pass"],
+		SkippedTests:    results["skip"],
+		FailedSubtests:  subtestResults["fail"],
+		PassedSubtests:  subtestResults["pass"],
+		SkippedSubtests: subtestResults["skip"],
+		Panics:          panics,
+		Details:         details,
+	}, text.String()
+}
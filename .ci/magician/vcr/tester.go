@@ -5,11 +5,11 @@ import (
 	"io/fs"
 	"magician/provider"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Result struct {
@@ -20,6 +20,26 @@ type Result struct {
 	SkippedSubtests []string
 	FailedSubtests  []string
 	Panics          []string
+	// Details of each test and subtest above, keyed by the same name.
+	Details map[string]TestDetail
+}
+
+// TestPackages returns the package of each test and subtest with details.
+func (r Result) TestPackages() map[string]string {
+	packages := make(map[string]string, len(r.Details))
+	for test, detail := range r.Details {
+		if detail.Package != "" {
+			packages[test] = detail.Package
+		}
+	}
+	return packages
+}
+
+type TestDetail struct {
+	Package string
+	Elapsed time.Duration
+	// The last lines of output of failing tests.
+	Output string
 }
 
 type Mode int
@@ -58,6 +78,7 @@ type Tester struct {
 	baseDir        string                      // the directory in which this tester was created
 	saKeyPath      string                      // where sa_key.json is relative to baseDir
 	cassettePaths  map[provider.Version]string // where cassettes are relative to baseDir by version
+	recordedPaths  map[provider.Version]string // where the cassettes of the last recording run are by version
 	logPaths       map[logKey]string           // where logs are relative to baseDir by version and mode
 	repoPaths      map[provider.Version]string // relative paths of already cloned repos by version
 }
@@ -67,12 +88,6 @@ const parallelJobs = 16
 
 const replayingTimeout = "240m"
 
-var testResultsExpression = regexp.MustCompile(`(?m:^--- (PASS|FAIL|SKIP): (TestAcc\w+))`)
-
-var subtestResultsExpression = regexp.MustCompile(`(?m:^    --- (PASS|FAIL|SKIP): (TestAcc\w+)/(\w+))`)
-
-var testPanicExpression = regexp.MustCompile(`^panic: .*`)
-
 var safeToLog = map[string]bool{
 	"ACCTEST_PARALLELISM":                        true,
 	"COMMIT_SHA":                                 true,
@@ -111,6 +126,7 @@ var safeToLog = map[string]bool{
 	"USER":                                       true,
 	"VCR_MODE":                                   true,
 	"VCR_PATH":                                   true,
+	"VCR_SCRUB_RULES":                            true,
 } // true if shown, false if hidden (default false)
 
 // Create a new tester in the current working directory and write the service account key file.
@@ -130,6 +146,7 @@ func NewTester(env map[string]string, cassetteBucket, logBucket string, rnr Exec
 		baseDir:        rnr.GetCWD(),
 		saKeyPath:      saKeyPath,
 		cassettePaths:  make(map[provider.Version]string, provider.NumVersions),
+		recordedPaths:  make(map[provider.Version]string, provider.NumVersions),
 		logPaths:       make(map[logKey]string, provider.NumVersions*numModes),
 		repoPaths:      make(map[provider.Version]string, provider.NumVersions),
 	}, nil
@@ -147,6 +164,19 @@ func (vt *Tester) FetchCassettes(version provider.Version, baseBranch, head stri
 	}
 	cassettePath := filepath.Join(vt.baseDir, "cassettes", version.String())
 	vt.rnr.Mkdir(cassettePath)
+	vt.fetchBaseCassettes(version, baseBranch, cassettePath)
+	if head != "" {
+		bucketPath := fmt.Sprintf("gs://%s/%srefs/heads/%s/fixtures/*", vt.cassetteBucket, version.BucketPath(), head)
+		if err := vt.fetchBucketPath(bucketPath, cassettePath); err != nil {
+			fmt.Println("Error fetching cassettes: ", err)
+		}
+	}
+	vt.cassettePaths[version] = cassettePath
+	return nil
+}
+
+// fetchBaseCassettes fetches the cassettes of the base branch into cassettePath.
+func (vt *Tester) fetchBaseCassettes(version provider.Version, baseBranch, cassettePath string) {
 	if baseBranch != "FEATURE-BRANCH-major-release-6.0.0" {
 		// pull main cassettes (major release uses branch specific casssettes as primary ones)
 		bucketPath := fmt.Sprintf("gs://%s/%sfixtures/*", vt.cassetteBucket, version.BucketPath())
@@ -160,14 +190,67 @@ func (vt *Tester) FetchCassettes(version provider.Version, baseBranch, head stri
 			fmt.Println("Error fetching cassettes: ", err)
 		}
 	}
-	if head != "" {
-		bucketPath := fmt.Sprintf("gs://%s/%srefs/heads/%s/fixtures/*", vt.cassetteBucket, version.BucketPath(), head)
-		if err := vt.fetchBucketPath(bucketPath, cassettePath); err != nil {
+}
+
+// DiffCassettes fetches the base branch cassettes of the tests from the last
+// recording run into a separate directory and compares the recorded cassettes
+// with them.
+func (vt *Tester) DiffCassettes(version provider.Version, baseBranch string) ([]CassetteDiff, error) {
+	recordedPath, ok := vt.recordedPaths[version]
+	if !ok {
+		return nil, fmt.Errorf("no cassettes recorded for version %s", version)
+	}
+	basePath := filepath.Join(vt.baseDir, "base_cassettes", version.String())
+	if err := vt.rnr.Mkdir(basePath); err != nil {
+		return nil, fmt.Errorf("error creating base cassette dir: %v", err)
+	}
+	names, err := cassetteFileNames(vt.rnr, recordedPath)
+	if err != nil {
+		return nil, fmt.Errorf("error listing recorded cassettes: %v", err)
+	}
+	if len(names) > 0 {
+		vt.fetchNamedBaseCassettes(version, baseBranch, basePath, names)
+	}
+	return DiffCassetteDirs(vt.rnr, basePath, recordedPath)
+}
+
+// fetchNamedBaseCassettes fetches only the named cassettes of the base branch
+// into cassettePath. Cassettes of new tests don't exist in the bucket yet.
+func (vt *Tester) fetchNamedBaseCassettes(version provider.Version, baseBranch, cassettePath string, names []string) {
+	var prefixes []string
+	if baseBranch != "FEATURE-BRANCH-major-release-6.0.0" {
+		prefixes = append(prefixes, fmt.Sprintf("gs://%s/%sfixtures/", vt.cassetteBucket, version.BucketPath()))
+	}
+	if baseBranch != "main" {
+		prefixes = append(prefixes, fmt.Sprintf("gs://%s/%srefs/branches/%s/fixtures/", vt.cassetteBucket, version.BucketPath(), baseBranch))
+	}
+	for _, prefix := range prefixes {
+		args := []string{"-m", "-q", "cp", "-c"}
+		for _, name := range names {
+			args = append(args, prefix+name)
+		}
+		args = append(args, cassettePath)
+		fmt.Println("Fetching cassettes:\n", "gsutil", strings.Join(args, " "))
+		if _, err := vt.rnr.Run("gsutil", args, nil); err != nil {
 			fmt.Println("Error fetching cassettes: ", err)
 		}
 	}
-	vt.cassettePaths[version] = cassettePath
-	return nil
+}
+
+// cassetteFileNames returns the sorted file names of the cassettes in dir.
+func cassetteFileNames(rnr ExecRunner, dir string) ([]string, error) {
+	var names []string
+	err := rnr.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".yaml" {
+			names = append(names, filepath.Base(path))
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
 }
 
 func (vt *Tester) fetchBucketPath(bucketPath, cassettePath string) error {
@@ -180,11 +263,19 @@ func (vt *Tester) fetchBucketPath(bucketPath, cassettePath string) error {
 	return nil
 }
 
-// CassettePath returns the local cassette path.
+// CassettePath returns the local cassette path. After re-recording some tests,
+// it holds the fetched cassettes merged with the re-recorded ones.
 func (vt *Tester) CassettePath(version provider.Version) string {
 	return vt.cassettePaths[version]
 }
 
+// RecordedCassettePath returns the local path of the cassettes of the last
+// recording run. When only some tests are re-recorded, it holds just their
+// cassettes.
+func (vt *Tester) RecordedCassettePath(version provider.Version) string {
+	return vt.recordedPaths[version]
+}
+
 // LogPath returns the local log path.
 func (vt *Tester) LogPath(mode Mode, version provider.Version) string {
 	lgky := logKey{mode, version}
@@ -196,6 +287,63 @@ type RunOptions struct {
 	Version  provider.Version
 	TestDirs []string
 	Tests    []string
+	// Packages of the tests, such as from Result.TestPackages. RunParallel
+	// only runs a test with a known package in that package, instead of in
+	// every test directory.
+	Packages map[string]string
+}
+
+// selectiveRecording returns whether the run re-records some tests on top of
+// fetched cassettes. Their cassettes are recorded into a separate directory
+// and merged into the fetched ones afterwards, so that the cassettes of the
+// other tests are kept.
+func (vt *Tester) selectiveRecording(opt RunOptions) bool {
+	if opt.Mode != Recording || len(opt.Tests) == 0 {
+		return false
+	}
+	_, fetched := vt.cassettePaths[opt.Version]
+	return fetched
+}
+
+// prepareCassettePath returns the directory to replay or record cassettes in.
+func (vt *Tester) prepareCassettePath(opt RunOptions) (string, error) {
+	cassettePath := filepath.Join(vt.baseDir, "cassettes", opt.Version.String())
+	switch opt.Mode {
+	case Replaying:
+		cassettePath, ok := vt.cassettePaths[opt.Version]
+		if !ok {
+			return "", fmt.Errorf("cassettes not fetched for version %s", opt.Version)
+		}
+		return cassettePath, nil
+	case Recording:
+		selective := vt.selectiveRecording(opt)
+		if selective {
+			cassettePath = filepath.Join(vt.baseDir, "recorded_cassettes", opt.Version.String())
+		}
+		if err := vt.rnr.RemoveAll(cassettePath); err != nil {
+			return "", fmt.Errorf("error removing cassettes: %v", err)
+		}
+		if err := vt.rnr.Mkdir(cassettePath); err != nil {
+			return "", fmt.Errorf("error creating cassette dir: %v", err)
+		}
+		if !selective {
+			vt.cassettePaths[opt.Version] = cassettePath
+		}
+		vt.recordedPaths[opt.Version] = cassettePath
+	}
+	return cassettePath, nil
+}
+
+// mergeRecordedCassettes copies the cassettes of a selective recording run over
+// the fetched cassettes, so that later runs replay the combined set.
+func (vt *Tester) mergeRecordedCassettes(opt RunOptions) error {
+	if !vt.selectiveRecording(opt) {
+		return nil
+	}
+	if err := vt.rnr.Copy(vt.recordedPaths[opt.Version], vt.cassettePaths[opt.Version]); err != nil {
+		return fmt.Errorf("error merging recorded cassettes: %v", err)
+	}
+	return nil
 }
 
 // Run the vcr tests in the given mode and provider version and return the result.
@@ -222,21 +370,9 @@ func (vt *Tester) Run(opt RunOptions) (Result, error) {
 
 	}
 
-	cassettePath := filepath.Join(vt.baseDir, "cassettes", opt.Version.String())
-	switch opt.Mode {
-	case Replaying:
-		cassettePath, ok = vt.cassettePaths[opt.Version]
-		if !ok {
-			return Result{}, fmt.Errorf("cassettes not fetched for version %s", opt.Version)
-		}
-	case Recording:
-		if err := vt.rnr.RemoveAll(cassettePath); err != nil {
-			return Result{}, fmt.Errorf("error removing cassettes: %v", err)
-		}
-		if err := vt.rnr.Mkdir(cassettePath); err != nil {
-			return Result{}, fmt.Errorf("error creating cassette dir: %v", err)
-		}
-		vt.cassettePaths[opt.Version] = cassettePath
+	cassettePath, err := vt.prepareCassettePath(opt)
+	if err != nil {
+		return Result{}, err
 	}
 
 	args := []string{"test"}
@@ -244,8 +380,8 @@ func (vt *Tester) Run(opt RunOptions) (Result, error) {
 	args = append(args,
 		"-parallel",
 		strconv.Itoa(accTestParallelism),
-		"-v",
-		"-run=TestAcc",
+		"-json",
+		runTestsFlag(opt.Tests),
 		"-timeout",
 		replayingTimeout,
 		"-ldflags=-X=github.com/hashicorp/terraform-provider-google-beta/version.ProviderVersion=acc",
@@ -287,10 +423,14 @@ func (vt *Tester) Run(opt RunOptions) (Result, error) {
 		// Use error as output for log.
 		output = fmt.Sprintf("Error %s tests:\n%v", opt.Mode.Lower(), testErr)
 	}
+	result, textOutput := collectResult(output)
 	// Leave repo directory.
 	if err := vt.rnr.PopDir(); err != nil {
 		return Result{}, err
 	}
+	if err := vt.mergeRecordedCassettes(opt); err != nil {
+		return Result{}, err
+	}
 
 	logFileName := filepath.Join(vt.baseDir, "testlogs", fmt.Sprintf("%s_test.log", opt.Mode.Lower()))
 	// Write output (or error) to test log.
@@ -299,11 +439,11 @@ func (vt *Tester) Run(opt RunOptions) (Result, error) {
 	if allOutput != "" {
 		allOutput += "\n"
 	}
-	allOutput += output
+	allOutput += textOutput
 	if err := vt.rnr.WriteFile(logFileName, allOutput); err != nil {
 		return Result{}, fmt.Errorf("error writing log: %v, test output: %v", err, allOutput)
 	}
-	return collectResult(output), testErr
+	return result, testErr
 }
 
 func (vt *Tester) RunParallel(opt RunOptions) (Result, error) {
@@ -321,7 +461,7 @@ func (vt *Tester) RunParallel(opt RunOptions) (Result, error) {
 	if err := vt.rnr.PushDir(repoPath); err != nil {
 		return Result{}, err
 	}
-	if len(opt.TestDirs) == 0 {
+	if len(opt.TestDirs) == 0 && !allPackagesKnown(opt) {
 		var err error
 		opt.TestDirs, err = vt.googleTestDirectory()
 		if err != nil {
@@ -329,33 +469,20 @@ func (vt *Tester) RunParallel(opt RunOptions) (Result, error) {
 		}
 	}
 
-	cassettePath := filepath.Join(vt.baseDir, "cassettes", opt.Version.String())
-	switch opt.Mode {
-	case Replaying:
-		cassettePath, ok = vt.cassettePaths[opt.Version]
-		if !ok {
-			return Result{}, fmt.Errorf("cassettes not fetched for version %s", opt.Version)
-		}
-	case Recording:
-		if err := vt.rnr.RemoveAll(cassettePath); err != nil {
-			return Result{}, fmt.Errorf("error removing cassettes: %v", err)
-		}
-		if err := vt.rnr.Mkdir(cassettePath); err != nil {
-			return Result{}, fmt.Errorf("error creating cassette dir: %v", err)
-		}
-		vt.cassettePaths[opt.Version] = cassettePath
+	cassettePath, err := vt.prepareCassettePath(opt)
+	if err != nil {
+		return Result{}, err
 	}
 
+	jobs := testJobs(opt)
 	running := make(chan struct{}, parallelJobs)
-	outputs := make(chan string, len(opt.TestDirs)*len(opt.Tests))
+	outputs := make(chan string, len(jobs))
 	wg := &sync.WaitGroup{}
-	wg.Add(len(opt.TestDirs) * len(opt.Tests))
-	errs := make(chan error, len(opt.TestDirs)*len(opt.Tests)*2)
-	for _, testDir := range opt.TestDirs {
-		for _, test := range opt.Tests {
-			running <- struct{}{}
-			go vt.runInParallel(opt.Mode, opt.Version, testDir, test, logPath, cassettePath, running, wg, outputs, errs)
-		}
+	wg.Add(len(jobs))
+	errs := make(chan error, len(jobs)*2)
+	for _, job := range jobs {
+		running <- struct{}{}
+		go vt.runInParallel(opt.Mode, opt.Version, job.testDir, job.test, logPath, cassettePath, running, wg, outputs, errs)
 	}
 
 	wg.Wait()
@@ -367,12 +494,16 @@ func (vt *Tester) RunParallel(opt RunOptions) (Result, error) {
 	if err := vt.rnr.PopDir(); err != nil {
 		return Result{}, err
 	}
+	if err := vt.mergeRecordedCassettes(opt); err != nil {
+		return Result{}, err
+	}
 	var output string
 	for otpt := range outputs {
 		output += otpt
 	}
+	result, textOutput := collectResult(output)
 	logFileName := filepath.Join(vt.baseDir, "testlogs", fmt.Sprintf("%s_test.log", opt.Mode.Lower()))
-	if err := vt.rnr.WriteFile(logFileName, output); err != nil {
+	if err := vt.rnr.WriteFile(logFileName, textOutput); err != nil {
 		return Result{}, err
 	}
 	var testErr error
@@ -382,7 +513,55 @@ func (vt *Tester) RunParallel(opt RunOptions) (Result, error) {
 			break
 		}
 	}
-	return collectResult(output), testErr
+	return result, testErr
+}
+
+type testJob struct {
+	testDir string
+	test    string
+}
+
+// testJobs returns the directories to run each test in. A test with a known
+// package runs once, in the test directory of that package, or in the package
+// itself if no test directory matches. Other tests run in every test directory.
+func testJobs(opt RunOptions) []testJob {
+	var jobs []testJob
+	for _, test := range opt.Tests {
+		pkg, ok := opt.Packages[test]
+		if !ok {
+			for _, testDir := range opt.TestDirs {
+				jobs = append(jobs, testJob{testDir: testDir, test: test})
+			}
+			continue
+		}
+		job := testJob{testDir: pkg, test: test}
+		for _, testDir := range opt.TestDirs {
+			if strings.HasSuffix(pkg, strings.TrimPrefix(testDir, ".")) {
+				job.testDir = testDir
+				break
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func allPackagesKnown(opt RunOptions) bool {
+	for _, test := range opt.Tests {
+		if _, ok := opt.Packages[test]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// runTestsFlag returns the -run flag for go test that runs the given tests, or
+// all acceptance tests if there are none.
+func runTestsFlag(tests []string) string {
+	if len(tests) == 0 {
+		return "-run=TestAcc"
+	}
+	return "-run=^(" + strings.Join(tests, "|") + ")$"
 }
 
 func (vt *Tester) runInParallel(mode Mode, version provider.Version, testDir, test, logPath, cassettePath string, running <-chan struct{}, wg *sync.WaitGroup, outputs chan<- string, errs chan<- error) {
@@ -391,7 +570,7 @@ func (vt *Tester) runInParallel(mode Mode, version provider.Version, testDir, te
 		testDir,
 		"-parallel",
 		"1",
-		"-v",
+		"-json",
 		"-run=" + test + "$",
 		"-timeout",
 		replayingTimeout,
@@ -423,6 +602,7 @@ func (vt *Tester) runInParallel(mode Mode, version provider.Version, testDir, te
 		output = fmt.Sprintf("Error %s tests:\n%v", mode.Lower(), testErr)
 		errs <- testErr
 	}
+	_, output = collectResult(output)
 	logFileName := filepath.Join(vt.baseDir, "testlogs", mode.Lower()+"_build", fmt.Sprintf("%s_%s_test.log", test, mode.Lower()))
 	// Write output (or error) to test log.
 	// Append to existing log file.
@@ -528,10 +708,14 @@ func (vt *Tester) UploadLogs(opts UploadLogsOptions) error {
 	return nil
 }
 
+// UploadCassettes scrubs and uploads the cassettes of the last recording run to
+// the head branch. Only re-recorded cassettes are uploaded: FetchCassettes
+// overlays them on the base branch cassettes, so later runs replay the
+// combined set.
 func (vt *Tester) UploadCassettes(head string, version provider.Version) error {
-	cassettePath, ok := vt.cassettePaths[version]
+	cassettePath, ok := vt.recordedPaths[version]
 	if !ok {
-		return fmt.Errorf("no cassettes found for version %s", version)
+		return fmt.Errorf("no cassettes recorded for version %s", version)
 	}
 	args := []string{
 		"-m",
@@ -540,6 +724,9 @@ func (vt *Tester) UploadCassettes(head string, version provider.Version) error {
 		filepath.Join(cassettePath, "*"),
 		fmt.Sprintf("gs://%s/%s/refs/heads/%s/fixtures/", vt.cassetteBucket, version, head),
 	}
+	if _, err := vt.ScrubCassettes(version, true); err != nil {
+		return fmt.Errorf("error scrubbing cassettes: %w", err)
+	}
 	fmt.Println("Uploading cassettes:\n", "gsutil", strings.Join(args, " "))
 	if _, err := vt.rnr.Run("gsutil", args, nil); err != nil {
 		fmt.Println("Error uploading cassettes: ", err)
@@ -547,6 +734,29 @@ func (vt *Tester) UploadCassettes(head string, version provider.Version) error {
 	return nil
 }
 
+// ScrubCassettes redacts secrets and the test project number from the
+// cassettes of the last recording run, using the default rules extended with
+// the ones in VCR_SCRUB_RULES, and returns the findings by cassette. Cassettes
+// are only rewritten if write is set.
+func (vt *Tester) ScrubCassettes(version provider.Version, write bool) (map[string][]Finding, error) {
+	cassettePath, ok := vt.recordedPaths[version]
+	if !ok {
+		return nil, fmt.Errorf("no cassettes recorded for version %s", version)
+	}
+	rules := DefaultScrubRules
+	if rulesPath := vt.env["VCR_SCRUB_RULES"]; rulesPath != "" {
+		var err error
+		if rules, err = LoadScrubRules(rulesPath); err != nil {
+			return nil, err
+		}
+	}
+	s, err := NewScrubber(rules, vt.env["GOOGLE_PROJECT_NUMBER"])
+	if err != nil {
+		return nil, err
+	}
+	return ScrubCassetteDir(vt.rnr, cassettePath, s, write)
+}
+
 // Deletes the service account key.
 func (vt *Tester) Cleanup() error {
 	if vt.saKeyPath == "" {
@@ -594,53 +804,3 @@ func (vt *Tester) printLogs(logPath string) {
 		return nil
 	})
 }
-
-func collectResult(output string) Result {
-	matches := testResultsExpression.FindAllStringSubmatch(output, -1)
-	resultSets := make(map[string]map[string]struct{}, 4)
-	for _, submatches := range matches {
-		if len(submatches) != 3 {
-			fmt.Printf("Warning: unexpected regex match found in test output: %v", submatches)
-			continue
-		}
-		if _, ok := resultSets[submatches[1]]; !ok {
-			resultSets[submatches[1]] = make(map[string]struct{})
-		}
-		resultSets[submatches[1]][submatches[2]] = struct{}{}
-	}
-	matches = subtestResultsExpression.FindAllStringSubmatch(output, -1)
-	subtestResultSets := make(map[string]map[string]struct{}, 4)
-	for _, submatches := range matches {
-		if len(submatches) != 4 {
-			fmt.Printf("Warning: unexpected regex match found in test output: %v", submatches)
-			continue
-		}
-		if _, ok := subtestResultSets[submatches[1]]; !ok {
-			subtestResultSets[submatches[1]] = make(map[string]struct{})
-		}
-		subtestResultSets[submatches[1]][fmt.Sprintf("%s__%s", submatches[2], submatches[3])] = struct{}{}
-	}
-	results := make(map[string][]string, 4)
-	results["PANIC"] = testPanicExpression.FindAllString(output, -1)
-	sort.Strings(results["PANIC"])
-	subtestResults := make(map[string][]string, 3)
-	for _, kind := range []string{"FAIL", "PASS", "SKIP"} {
-		for test := range resultSets[kind] {
-			results[kind] = append(results[kind], test)
-		}
-		sort.Strings(results[kind])
-		for subtest := range subtestResultSets[kind] {
-			subtestResults[kind] = append(subtestResults[kind], subtest)
-		}
-		sort.Strings(subtestResults[kind])
-	}
-	return Result{
-		FailedTests:     results["FAIL"],
-		PassedTests:     results["PASS"],
-		SkippedTests:    results["SKIP"],
-		FailedSubtests:  subtestResults["FAIL"],
-		PassedSubtests:  subtestResults["PASS"],
-		SkippedSubtests: subtestResults["SKIP"],
-		Panics:          results["PANIC"],
-	}
-}
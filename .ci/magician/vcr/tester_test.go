@@ -1,58 +1,266 @@
 package vcr
 
 import (
+	"magician/exec"
+	"magician/provider"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
 
 func TestCollectResults(t *testing.T) {
 	for _, test := range []struct {
-		name     string
-		output   string
-		expected Result
+		name         string
+		output       string
+		expected     Result
+		expectedText string
 	}{
 		{
 			name: "no compound tests",
-			output: `--- FAIL: TestAccServiceOneResourceOne (100.00s)
---- PASS: TestAccServiceOneResourceTwo (100.00s)
---- PASS: TestAccServiceTwoResourceOne (100.00s)
---- PASS: TestAccServiceTwoResourceTwo (100.00s)
+			output: `{"Action":"run","Package":"google/services/one","Test":"TestAccServiceOneResourceOne"}
+{"Action":"output","Package":"google/services/one","Test":"TestAccServiceOneResourceOne","Output":"=== RUN   TestAccServiceOneResourceOne\n"}
+{"Action":"run","Package":"google/services/one","Test":"TestAccServiceOneResourceTwo"}
+{"Action":"output","Package":"google/services/one","Test":"TestAccServiceOneResourceTwo","Output":"=== RUN   TestAccServiceOneResourceTwo\n"}
+{"Action":"output","Package":"google/services/one","Test":"TestAccServiceOneResourceOne","Output":"    resource_test.go:10: Step 1/2 error: bad request\n"}
+{"Action":"output","Package":"google/services/one","Test":"TestAccServiceOneResourceOne","Output":"--- FAIL: TestAccServiceOneResourceOne (100.00s)\n"}
+{"Action":"fail","Package":"google/services/one","Test":"TestAccServiceOneResourceOne","Elapsed":100}
+{"Action":"output","Package":"google/services/one","Test":"TestAccServiceOneResourceTwo","Output":"--- PASS: TestAccServiceOneResourceTwo (50.50s)\n"}
+{"Action":"pass","Package":"google/services/one","Test":"TestAccServiceOneResourceTwo","Elapsed":50.5}
+{"Action":"output","Package":"google/services/one","Output":"FAIL\n"}
+{"Action":"fail","Package":"google/services/one","Elapsed":150.6}
+{"Action":"pass","Package":"google/services/two","Test":"TestAccServiceTwoResourceOne","Elapsed":1}
+{"Action":"skip","Package":"google/services/two","Test":"TestAccServiceTwoResourceTwo","Elapsed":0}
+{"Action":"pass","Package":"google/services/two","Test":"TestServiceTwoUnit","Elapsed":0}
 `,
 			expected: Result{
-				PassedTests: []string{"TestAccServiceOneResourceTwo", "TestAccServiceTwoResourceOne", "TestAccServiceTwoResourceTwo"},
-				FailedTests: []string{"TestAccServiceOneResourceOne"},
+				PassedTests:  []string{"TestAccServiceOneResourceTwo", "TestAccServiceTwoResourceOne"},
+				SkippedTests: []string{"TestAccServiceTwoResourceTwo"},
+				FailedTests:  []string{"TestAccServiceOneResourceOne"},
+				Details: map[string]TestDetail{
+					"TestAccServiceOneResourceOne": {
+						Package: "google/services/one",
+						Elapsed: 100 * time.Second,
+						Output:  "    resource_test.go:10: Step 1/2 error: bad request\n--- FAIL: TestAccServiceOneResourceOne (100.00s)",
+					},
+					"TestAccServiceOneResourceTwo": {Package: "google/services/one", Elapsed: 50500 * time.Millisecond},
+					"TestAccServiceTwoResourceOne": {Package: "google/services/two", Elapsed: time.Second},
+					"TestAccServiceTwoResourceTwo": {Package: "google/services/two"},
+				},
 			},
+			expectedText: `=== RUN   TestAccServiceOneResourceOne
+=== RUN   TestAccServiceOneResourceTwo
+    resource_test.go:10: Step 1/2 error: bad request
+--- FAIL: TestAccServiceOneResourceOne (100.00s)
+--- PASS: TestAccServiceOneResourceTwo (50.50s)
+FAIL
+`,
 		},
 		{
 			name: "compound tests",
-			output: `--- FAIL: TestAccServiceOneResourceOne (100.00s)
---- FAIL: TestAccServiceOneResourceTwo (100.00s)
-    --- PASS: TestAccServiceOneResourceTwo/test_one (100.00s)
-    --- FAIL: TestAccServiceOneResourceTwo/test_two (100.00s)
---- PASS: TestAccServiceTwoResourceOne (100.00s)
-    --- PASS: TestAccServiceTwoResourceOne/test_one (100.00s)
-    --- PASS: TestAccServiceTwoResourceOne/test_two (100.00s)
---- PASS: TestAccServiceTwoResourceTwo (100.00s)
+			output: `{"Action":"fail","Package":"p","Test":"TestAccServiceOneResourceOne","Elapsed":100}
+{"Action":"pass","Package":"p","Test":"TestAccServiceOneResourceTwo/test_one","Elapsed":40}
+{"Action":"fail","Package":"p","Test":"TestAccServiceOneResourceTwo/test_two","Elapsed":60}
+{"Action":"fail","Package":"p","Test":"TestAccServiceOneResourceTwo","Elapsed":100}
+{"Action":"pass","Package":"p","Test":"TestAccServiceTwoResourceOne/test_one/nested_one","Elapsed":10}
+{"Action":"pass","Package":"p","Test":"TestAccServiceTwoResourceOne/test_one","Elapsed":10}
+{"Action":"pass","Package":"p","Test":"TestAccServiceTwoResourceOne/test_two","Elapsed":90}
+{"Action":"pass","Package":"p","Test":"TestAccServiceTwoResourceOne","Elapsed":100}
 `,
 			expected: Result{
-				PassedTests: []string{
-					"TestAccServiceTwoResourceOne",
-					"TestAccServiceTwoResourceTwo",
-				},
+				PassedTests: []string{"TestAccServiceTwoResourceOne"},
 				FailedTests: []string{"TestAccServiceOneResourceOne", "TestAccServiceOneResourceTwo"},
 				PassedSubtests: []string{
 					"TestAccServiceOneResourceTwo__test_one",
-					"TestAccServiceTwoResourceOne__test_one",
+					"TestAccServiceTwoResourceOne__test_one__nested_one",
 					"TestAccServiceTwoResourceOne__test_two",
 				},
 				FailedSubtests: []string{"TestAccServiceOneResourceTwo__test_two"},
+				Details: map[string]TestDetail{
+					"TestAccServiceOneResourceOne":                       {Package: "p", Elapsed: 100 * time.Second},
+					"TestAccServiceOneResourceTwo":                       {Package: "p", Elapsed: 100 * time.Second},
+					"TestAccServiceOneResourceTwo__test_one":             {Package: "p", Elapsed: 40 * time.Second},
+					"TestAccServiceOneResourceTwo__test_two":             {Package: "p", Elapsed: 60 * time.Second},
+					"TestAccServiceTwoResourceOne":                       {Package: "p", Elapsed: 100 * time.Second},
+					"TestAccServiceTwoResourceOne__test_one__nested_one": {Package: "p", Elapsed: 10 * time.Second},
+					"TestAccServiceTwoResourceOne__test_two":             {Package: "p", Elapsed: 90 * time.Second},
+				},
+			},
+		},
+		{
+			name: "panic and wrapped error",
+			output: `Error replaying tests:
+error running go: exit status 1
+stdout:
+{"Action":"output","Package":"p","Test":"TestAccServiceOneResourceOne","Output":"panic: runtime error: invalid memory address or nil pointer dereference\n"}
+{"Action":"fail","Package":"p","Test":"TestAccServiceOneResourceOne","Elapsed":1}
+stderr:
+`,
+			expected: Result{
+				FailedTests: []string{"TestAccServiceOneResourceOne"},
+				Panics:      []string{"panic: runtime error: invalid memory address or nil pointer dereference"},
+				Details: map[string]TestDetail{
+					"TestAccServiceOneResourceOne": {
+						Package: "p",
+						Elapsed: time.Second,
+						Output:  "panic: runtime error: invalid memory address or nil pointer dereference",
+					},
+				},
 			},
+			expectedText: `Error replaying tests:
+error running go: exit status 1
+stdout:
+panic: runtime error: invalid memory address or nil pointer dereference
+stderr:
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, text := collectResult(test.output)
+			if diff := cmp.Diff(test.expected, result); diff != "" {
+				t.Errorf("collectResult(%q) got unexpected diff (-want +got):\n%s", test.output, diff)
+			}
+			if test.expectedText != "" && text != test.expectedText {
+				t.Errorf("collectResult(%q) got text %q, want %q", test.output, text, test.expectedText)
+			}
+		})
+	}
+}
+
+func TestTestJobs(t *testing.T) {
+	opt := RunOptions{
+		TestDirs: []string{"./google-beta/services/compute", "./google-beta/services/container"},
+		Tests:    []string{"TestAccComputeInstance_basic", "TestAccContainerCluster_basic", "TestAccUnknown_basic", "TestAccStorageBucket_basic"},
+		Packages: map[string]string{
+			"TestAccComputeInstance_basic":  "github.com/hashicorp/terraform-provider-google-beta/google-beta/services/compute",
+			"TestAccContainerCluster_basic": "github.com/hashicorp/terraform-provider-google-beta/google-beta/services/container",
+			"TestAccStorageBucket_basic":    "github.com/hashicorp/terraform-provider-google-beta/google-beta/services/storage",
 		},
+	}
+	want := []testJob{
+		{testDir: "./google-beta/services/compute", test: "TestAccComputeInstance_basic"},
+		{testDir: "./google-beta/services/container", test: "TestAccContainerCluster_basic"},
+		{testDir: "./google-beta/services/compute", test: "TestAccUnknown_basic"},
+		{testDir: "./google-beta/services/container", test: "TestAccUnknown_basic"},
+		{testDir: "github.com/hashicorp/terraform-provider-google-beta/google-beta/services/storage", test: "TestAccStorageBucket_basic"},
+	}
+	if diff := cmp.Diff(want, testJobs(opt), cmp.AllowUnexported(testJob{})); diff != "" {
+		t.Errorf("testJobs() returned unexpected difference (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunTestsFlag(t *testing.T) {
+	if got, want := runTestsFlag(nil), "-run=TestAcc"; got != want {
+		t.Errorf("runTestsFlag(nil) = %q, want %q", got, want)
+	}
+	if got, want := runTestsFlag([]string{"TestAccOne", "TestAccTwo"}), "-run=^(TestAccOne|TestAccTwo)$"; got != want {
+		t.Errorf("runTestsFlag() = %q, want %q", got, want)
+	}
+}
+
+func TestSelectiveRecordingMergesCassettes(t *testing.T) {
+	dir := t.TempDir()
+	rnr, err := exec.NewRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vt, err := NewTester(map[string]string{}, "ci-vcr-cassettes", "", rnr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vt.baseDir = dir
+	fetchedPath := filepath.Join(dir, "cassettes", "beta")
+	if err := rnr.Mkdir(fetchedPath); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"TestAccOne.yaml": "fetched one",
+		"TestAccTwo.yaml": "fetched two",
+	} {
+		if err := rnr.WriteFile(filepath.Join(fetchedPath, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vt.cassettePaths[provider.Beta] = fetchedPath
+
+	opt := RunOptions{Mode: Recording, Version: provider.Beta, Tests: []string{"TestAccTwo"}}
+	recordedPath, err := vt.prepareCassettePath(opt)
+	if err != nil {
+		t.Fatalf("prepareCassettePath() error: %s", err)
+	}
+	if recordedPath == fetchedPath {
+		t.Fatalf("prepareCassettePath() = %s, want a separate directory for re-recorded cassettes", recordedPath)
+	}
+	if err := rnr.WriteFile(filepath.Join(recordedPath, "TestAccTwo.yaml"), "recorded two"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vt.mergeRecordedCassettes(opt); err != nil {
+		t.Fatalf("mergeRecordedCassettes() error: %s", err)
+	}
+
+	for name, want := range map[string]string{
+		"TestAccOne.yaml": "fetched one",
+		"TestAccTwo.yaml": "recorded two",
+	} {
+		if got, err := rnr.ReadFile(filepath.Join(vt.CassettePath(provider.Beta), name)); err != nil || got != want {
+			t.Errorf("merged cassette %s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if got := vt.recordedPaths[provider.Beta]; got != recordedPath {
+		t.Errorf("recorded cassette path = %s, want %s", got, recordedPath)
+	}
+}
+
+// gsutilRecorder records gsutil calls instead of running them.
+type gsutilRecorder struct {
+	ExecRunner
+	calls [][]string
+}
+
+func (r *gsutilRecorder) Run(name string, args []string, env map[string]string) (string, error) {
+	if name == "gsutil" {
+		r.calls = append(r.calls, args)
+		return "", nil
+	}
+	return r.ExecRunner.Run(name, args, env)
+}
+
+func TestDiffCassettesFetchesRecordedCassettesOnly(t *testing.T) {
+	dir := t.TempDir()
+	execRunner, err := exec.NewRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnr := &gsutilRecorder{ExecRunner: execRunner}
+	vt, err := NewTester(map[string]string{}, "ci-vcr-cassettes", "", rnr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vt.baseDir = dir
+	recordedPath := filepath.Join(dir, "recorded_cassettes", "beta")
+	if err := rnr.Mkdir(recordedPath); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"TestAccTwo.yaml": "version: 1\n",
+		"TestAccOne.yaml": "version: 1\n",
+		"TestAccOne.seed": "123",
 	} {
-		if diff := cmp.Diff(test.expected, collectResult(test.output)); diff != "" {
-			t.Errorf("collectResult(%q) got unexpected diff (-want +got):\n%s", test.output, diff)
+		if err := rnr.WriteFile(filepath.Join(recordedPath, name), content); err != nil {
+			t.Fatal(err)
 		}
 	}
+	vt.recordedPaths[provider.Beta] = recordedPath
 
+	if _, err := vt.DiffCassettes(provider.Beta, "main"); err != nil {
+		t.Fatalf("DiffCassettes() error: %s", err)
+	}
+	basePath := filepath.Join(dir, "base_cassettes", "beta")
+	want := [][]string{
+		{"-m", "-q", "cp", "-c", "gs://ci-vcr-cassettes/beta/fixtures/TestAccOne.yaml", "gs://ci-vcr-cassettes/beta/fixtures/TestAccTwo.yaml", basePath},
+	}
+	if diff := cmp.Diff(want, rnr.calls); diff != "" {
+		t.Errorf("DiffCassettes() made unexpected gsutil calls (-want +got):\n%s", diff)
+	}
 }
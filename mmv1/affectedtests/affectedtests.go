@@ -0,0 +1,333 @@
+// Copyright 2025 Google Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package affectedtests determines which acceptance tests are affected by
+// files changed in Magic Modules, so that a PR can select the VCR tests to run
+// before the downstream providers are generated.
+//
+// Changed files are mapped to the resources they generate:
+//   - products/<product>/<Resource>.yaml maps to the resource it defines
+//     (matched on SourceYamlFile), and product.yaml to every resource of the
+//     product.
+//   - Example configs under templates/terraform/examples map to the example
+//     tests that use them.
+//   - Other templates map to the resources whose YAML references them, or to
+//     every generated resource if no resource references them.
+//   - Handwritten resources under third_party/terraform/services map to the
+//     resource named in their resource_*_meta.yaml file, and test files to the
+//     tests they declare.
+//
+// Resources are then mapped to their generated example tests and to the
+// handwritten tests whose configs include them. Like the affectedtests script
+// in the providers, handwritten tests that use configs from other files aren't
+// picked up.
+package affectedtests
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api"
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api/product"
+)
+
+const (
+	productsDir    = "products"
+	templatesDir   = "templates"
+	examplesDir    = "templates/terraform/examples"
+	baseConfigsDir = "templates/terraform/examples/base_configs"
+	thirdPartyDir  = "third_party/terraform"
+	servicesDir    = "third_party/terraform/services"
+)
+
+// metaResourcePattern matches the resource name in a resource_*_meta.yaml
+// file. The files may be templates, so they aren't parsed as YAML.
+var metaResourcePattern = regexp.MustCompile(`(?m)^resource:\s*['"]?(\w+)`)
+
+// Index holds the resources generated by Magic Modules and the tests that
+// exercise them.
+type Index struct {
+	resources []*resourceInfo
+
+	// handwrittenResources maps handwritten resource files, without their
+	// extensions, to the resource they implement.
+	handwrittenResources map[string]string
+
+	// Handwritten tests, keyed by the resource they include, by the service
+	// directory and by the file that declares them.
+	resourceTests map[string][]string
+	serviceTests  map[string][]string
+	fileTests     map[string][]string
+}
+
+type resourceInfo struct {
+	name       string
+	sourceFile string
+	productDir string
+	service    string
+	// yaml is the content of the resource's YAML file, used to find the
+	// templates it references.
+	yaml string
+	// exampleTests maps each example config to the test generated from it.
+	exampleTests map[string][]string
+}
+
+// LoadIndex loads the resources of every product at the given version or
+// lower, and the handwritten tests. Like the generator, it must run from the
+// mmv1 directory since example configs are read relative to it.
+func LoadIndex(version string) (*Index, error) {
+	if !slices.Contains(product.ORDER, version) {
+		return nil, fmt.Errorf("unknown version %q, must be one of %s", version, strings.Join(product.ORDER, ", "))
+	}
+	productFiles, err := filepath.Glob(filepath.Join(productsDir, "*", "product.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{
+		handwrittenResources: make(map[string]string),
+		resourceTests:        make(map[string][]string),
+		serviceTests:         make(map[string][]string),
+		fileTests:            make(map[string][]string),
+	}
+	for _, productFile := range productFiles {
+		resources, err := loadProductResources(productFile, version)
+		if err != nil {
+			return nil, err
+		}
+		index.resources = append(index.resources, resources...)
+	}
+	if err := index.loadHandwritten(); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func loadProductResources(productFile, version string) ([]*resourceInfo, error) {
+	productApi := &api.Product{}
+	api.Compile(productFile, productApi, "")
+	if !productApi.ExistsAtVersionOrLower(version) {
+		return nil, nil
+	}
+	productDir := filepath.Dir(productFile)
+	versionObj := productApi.VersionObjOrClosest(version)
+	resourceFiles, err := filepath.Glob(filepath.Join(productDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var resources []*resourceInfo
+	for _, resourceFile := range resourceFiles {
+		if filepath.Base(resourceFile) == "product.yaml" {
+			continue
+		}
+		content, err := os.ReadFile(resourceFile)
+		if err != nil {
+			return nil, err
+		}
+		resource := &api.Resource{}
+		api.Compile(resourceFile, resource, "")
+		resource.SourceYamlFile = resourceFile
+		resource.TargetVersionName = version
+		resource.SetDefault(productApi)
+		if resource.IsExcluded() || resource.NotInVersion(versionObj) {
+			continue
+		}
+		info := &resourceInfo{
+			name:         resource.TerraformName(),
+			sourceFile:   filepath.ToSlash(resource.SourceYamlFile),
+			productDir:   filepath.ToSlash(productDir),
+			service:      productApi.ApiName,
+			yaml:         string(content),
+			exampleTests: make(map[string][]string),
+		}
+		for _, example := range resource.TestExamples() {
+			testName := "TestAcc" + example.TestSlug(productApi.Name, resource.Name)
+			configPath := filepath.ToSlash(example.ConfigPath)
+			info.exampleTests[configPath] = append(info.exampleTests[configPath], testName)
+		}
+		resources = append(resources, info)
+	}
+	return resources, nil
+}
+
+// loadHandwritten reads the handwritten resources and tests in the service
+// directories.
+func (index *Index) loadHandwritten() error {
+	return filepath.WalkDir(servicesDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		path = filepath.ToSlash(path)
+		name := trimExtensions(d.Name())
+		service := filepath.Base(filepath.Dir(path))
+		if strings.HasPrefix(name, "resource_") && strings.HasSuffix(name, "_meta") {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if match := metaResourcePattern.FindSubmatch(content); match != nil {
+				resourceFile := strings.TrimSuffix(trimExtensions(path), "_meta")
+				index.handwrittenResources[resourceFile] = string(match[1])
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, "_test") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tests := readTestFile(path, string(content))
+		for test, resources := range tests {
+			index.fileTests[path] = append(index.fileTests[path], test)
+			index.serviceTests[service] = append(index.serviceTests[service], test)
+			for resource := range resources {
+				index.resourceTests[resource] = append(index.resourceTests[resource], test)
+			}
+		}
+		return nil
+	})
+}
+
+// AffectedTests returns the sorted names of the tests affected by the
+// changed files. Paths may be relative to the root of the Magic Modules
+// repository or to the mmv1 directory.
+func (index *Index) AffectedTests(changedFiles []string) []string {
+	tests := make(map[string]struct{})
+	for _, file := range changedFiles {
+		file = strings.TrimPrefix(filepath.ToSlash(file), "mmv1/")
+		resources, fileTests := index.affected(file)
+		for _, resource := range resources {
+			fileTests = append(fileTests, index.testsForResource(resource)...)
+		}
+		for _, test := range fileTests {
+			tests[test] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(tests))
+	for name := range tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// affected returns the resources whose generated code or behavior depends on
+// a changed file, and the tests it affects directly.
+func (index *Index) affected(file string) ([]*resourceInfo, []string) {
+	switch {
+	case strings.HasPrefix(file, productsDir+"/"):
+		var resources []*resourceInfo
+		isProduct := filepath.Base(file) == "product.yaml"
+		for _, resource := range index.resources {
+			if resource.sourceFile == file || (isProduct && resource.productDir == filepath.Dir(file)) {
+				resources = append(resources, resource)
+			}
+		}
+		return resources, nil
+	case strings.HasPrefix(file, examplesDir+"/") && !strings.HasPrefix(file, baseConfigsDir+"/"):
+		// Only the tests generated from the example are affected.
+		var tests []string
+		for _, resource := range index.resources {
+			tests = append(tests, resource.exampleTests[file]...)
+		}
+		return nil, tests
+	case strings.HasPrefix(file, templatesDir+"/"):
+		var resources []*resourceInfo
+		for _, resource := range index.resources {
+			if strings.Contains(resource.yaml, file) {
+				resources = append(resources, resource)
+			}
+		}
+		if len(resources) == 0 {
+			// Templates that aren't referenced by a resource are used to
+			// generate every resource.
+			return index.resources, nil
+		}
+		return resources, nil
+	case strings.HasPrefix(file, servicesDir+"/"):
+		if tests, ok := index.fileTests[file]; ok {
+			return nil, tests
+		}
+		if name, ok := index.handwrittenResources[trimExtensions(file)]; ok {
+			return []*resourceInfo{{name: name}}, nil
+		}
+		// Code shared within a service may be used by any of its generated
+		// resources and tests.
+		service := strings.Split(strings.TrimPrefix(file, servicesDir+"/"), "/")[0]
+		var resources []*resourceInfo
+		for _, resource := range index.resources {
+			if resource.service == service {
+				resources = append(resources, resource)
+			}
+		}
+		return resources, index.serviceTests[service]
+	case strings.HasPrefix(file, thirdPartyDir+"/"):
+		// Code shared by the whole provider.
+		var tests []string
+		for _, serviceTests := range index.serviceTests {
+			tests = append(tests, serviceTests...)
+		}
+		return index.resources, tests
+	case strings.HasPrefix(file, "api/"), strings.HasPrefix(file, "provider/"), strings.HasPrefix(file, "google/"):
+		// Changes to the generator itself.
+		return index.resources, nil
+	}
+	return nil, nil
+}
+
+// testsForResource returns the example tests generated for a resource and
+// the handwritten tests that include it.
+func (index *Index) testsForResource(resource *resourceInfo) []string {
+	var tests []string
+	for _, exampleTests := range resource.exampleTests {
+		tests = append(tests, exampleTests...)
+	}
+	return append(tests, index.resourceTests[resource.name]...)
+}
+
+// ChangedFilesFromDiff returns the files added, modified or deleted in a git
+// diff. Both the old and the new path of a renamed file are returned.
+func ChangedFilesFromDiff(diff string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, prefix := range []string{"--- a/", "+++ b/", "rename from ", "rename to "} {
+			if file, ok := strings.CutPrefix(line, prefix); ok && !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// trimExtensions removes every extension from a file name, so that both
+// resource_foo.go and resource_foo.go.tmpl become resource_foo.
+func trimExtensions(name string) string {
+	dir, base := filepath.Split(name)
+	if i := strings.Index(base, "."); i >= 0 {
+		base = base[:i]
+	}
+	return dir + base
+}
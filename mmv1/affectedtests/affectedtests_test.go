@@ -0,0 +1,311 @@
+// Copyright 2025 Google Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package affectedtests
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func testIndex() *Index {
+	return &Index{
+		resources: []*resourceInfo{
+			{
+				name:       "google_pubsub_topic",
+				sourceFile: "products/pubsub/Topic.yaml",
+				productDir: "products/pubsub",
+				service:    "pubsub",
+				yaml:       "custom_code:\n  encoder: 'templates/terraform/encoders/no_message_storage_policy.go.tmpl'\n",
+				exampleTests: map[string][]string{
+					"templates/terraform/examples/pubsub_topic_basic.tf.tmpl": {"TestAccPubsubTopic_pubsubTopicBasicExample"},
+					"templates/terraform/examples/pubsub_topic_cmek.tf.tmpl":  {"TestAccPubsubTopic_pubsubTopicCmekExample"},
+				},
+			},
+			{
+				name:       "google_pubsub_subscription",
+				sourceFile: "products/pubsub/Subscription.yaml",
+				productDir: "products/pubsub",
+				service:    "pubsub",
+				exampleTests: map[string][]string{
+					"templates/terraform/examples/pubsub_subscription_push.tf.tmpl": {"TestAccPubsubSubscription_pubsubSubscriptionPushExample"},
+				},
+			},
+			{
+				name:       "google_dns_managed_zone",
+				sourceFile: "products/dns/ManagedZone.yaml",
+				productDir: "products/dns",
+				service:    "dns",
+				exampleTests: map[string][]string{
+					"templates/terraform/examples/dns_managed_zone_basic.tf.tmpl": {"TestAccDNSManagedZone_dnsManagedZoneBasicExample"},
+				},
+			},
+		},
+		handwrittenResources: map[string]string{
+			"third_party/terraform/services/compute/resource_compute_instance": "google_compute_instance",
+		},
+		resourceTests: map[string][]string{
+			"google_pubsub_topic":     {"TestAccPubsubTopic_update"},
+			"google_compute_instance": {"TestAccComputeInstance_basic"},
+		},
+		serviceTests: map[string][]string{
+			"pubsub":  {"TestAccPubsubTopic_update"},
+			"compute": {"TestAccComputeInstance_basic"},
+		},
+		fileTests: map[string][]string{
+			"third_party/terraform/services/pubsub/resource_pubsub_topic_test.go": {"TestAccPubsubTopic_update"},
+		},
+	}
+}
+
+func TestAffectedTests(t *testing.T) {
+	cases := []struct {
+		name         string
+		changedFiles []string
+		want         []string
+	}{
+		{
+			name:         "resource yaml",
+			changedFiles: []string{"mmv1/products/pubsub/Topic.yaml"},
+			want: []string{
+				"TestAccPubsubTopic_pubsubTopicBasicExample",
+				"TestAccPubsubTopic_pubsubTopicCmekExample",
+				"TestAccPubsubTopic_update",
+			},
+		},
+		{
+			name:         "product yaml",
+			changedFiles: []string{"products/pubsub/product.yaml"},
+			want: []string{
+				"TestAccPubsubSubscription_pubsubSubscriptionPushExample",
+				"TestAccPubsubTopic_pubsubTopicBasicExample",
+				"TestAccPubsubTopic_pubsubTopicCmekExample",
+				"TestAccPubsubTopic_update",
+			},
+		},
+		{
+			name:         "example config",
+			changedFiles: []string{"mmv1/templates/terraform/examples/pubsub_topic_cmek.tf.tmpl"},
+			want:         []string{"TestAccPubsubTopic_pubsubTopicCmekExample"},
+		},
+		{
+			name:         "referenced template",
+			changedFiles: []string{"mmv1/templates/terraform/encoders/no_message_storage_policy.go.tmpl"},
+			want: []string{
+				"TestAccPubsubTopic_pubsubTopicBasicExample",
+				"TestAccPubsubTopic_pubsubTopicCmekExample",
+				"TestAccPubsubTopic_update",
+			},
+		},
+		{
+			name:         "shared template",
+			changedFiles: []string{"mmv1/templates/terraform/resource.go.tmpl"},
+			want: []string{
+				"TestAccDNSManagedZone_dnsManagedZoneBasicExample",
+				"TestAccPubsubSubscription_pubsubSubscriptionPushExample",
+				"TestAccPubsubTopic_pubsubTopicBasicExample",
+				"TestAccPubsubTopic_pubsubTopicCmekExample",
+				"TestAccPubsubTopic_update",
+			},
+		},
+		{
+			name:         "handwritten resource",
+			changedFiles: []string{"mmv1/third_party/terraform/services/compute/resource_compute_instance.go.tmpl"},
+			want:         []string{"TestAccComputeInstance_basic"},
+		},
+		{
+			name:         "handwritten test",
+			changedFiles: []string{"mmv1/third_party/terraform/services/pubsub/resource_pubsub_topic_test.go"},
+			want:         []string{"TestAccPubsubTopic_update"},
+		},
+		{
+			name:         "service utils",
+			changedFiles: []string{"mmv1/third_party/terraform/services/dns/dns_utils.go"},
+			want:         []string{"TestAccDNSManagedZone_dnsManagedZoneBasicExample"},
+		},
+		{
+			name:         "provider code",
+			changedFiles: []string{"mmv1/third_party/terraform/transport/config.go.tmpl"},
+			want: []string{
+				"TestAccComputeInstance_basic",
+				"TestAccDNSManagedZone_dnsManagedZoneBasicExample",
+				"TestAccPubsubSubscription_pubsubSubscriptionPushExample",
+				"TestAccPubsubTopic_pubsubTopicBasicExample",
+				"TestAccPubsubTopic_pubsubTopicCmekExample",
+				"TestAccPubsubTopic_update",
+			},
+		},
+		{
+			name:         "unrelated files",
+			changedFiles: []string{"docs/content/_index.md", "tools/diff-processor/main.go"},
+			want:         []string{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := testIndex().AffectedTests(tc.changedFiles)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("AffectedTests(%v) = %v, want %v", tc.changedFiles, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadTestFile(t *testing.T) {
+	content := `package pubsub_test
+
+import (
+	"testing"
+)
+
+func TestAccPubsubTopic_update(t *testing.T) {
+	acctest.VcrTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPubsubTopic_update(topic),
+			},
+		},
+	})
+}
+
+func TestAccPubsubTopic_cmek(t *testing.T) {
+	acctest.VcrTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPubsubTopic_cmek(topic),
+			},
+		},
+	})
+}
+
+func testAccPubsubTopic_update(topic string) string {
+	return fmt.Sprintf(` + "`" + `
+resource "google_pubsub_topic" "foo" {
+  name = "%s"
+}
+` + "`" + `, topic)
+}
+
+func testAccPubsubTopic_cmek(topic string) string {
+	return testAccPubsubTopic_key() + fmt.Sprintf(` + "`" + `
+resource "google_pubsub_topic" "topic" {
+  name         = "%s"
+  kms_key_name = google_kms_crypto_key.key.id
+}
+` + "`" + `, topic)
+}
+
+func testAccPubsubTopic_key() string {
+{{- if ne $.TargetVersionName "ga" }}
+	return "resource \"google_kms_crypto_key\" \"key\" {}"
+{{- else }}
+	return ""
+{{- end }}
+}
+`
+	got := readTestFile("resource_pubsub_topic_test.go.tmpl", content)
+	want := map[string]map[string]struct{}{
+		"TestAccPubsubTopic_update": {"google_pubsub_topic": {}},
+		"TestAccPubsubTopic_cmek":   {"google_pubsub_topic": {}, "google_kms_crypto_key": {}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readTestFile() = %v, want %v", got, want)
+	}
+}
+
+func TestReadTestFileFallback(t *testing.T) {
+	content := `package pubsub_test
+
+{{ if ne $.TargetVersionName "ga" -}}
+func TestAccPubsubTopic_beta(t *testing.T) {
+	config := ` + "`" + `resource "google_pubsub_topic" "foo" {}` + "`" + `
+{{ end -}}
+`
+	got := readTestFile("resource_pubsub_topic_test.go.tmpl", content)
+	want := map[string]map[string]struct{}{
+		"TestAccPubsubTopic_beta": {"google_pubsub_topic": {}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readTestFile() = %v, want %v", got, want)
+	}
+}
+
+func TestChangedFilesFromDiff(t *testing.T) {
+	diff := `diff --git a/mmv1/products/pubsub/Topic.yaml b/mmv1/products/pubsub/Topic.yaml
+index 1111111..2222222 100644
+--- a/mmv1/products/pubsub/Topic.yaml
++++ b/mmv1/products/pubsub/Topic.yaml
+@@ -1 +1 @@
+-name: 'Topic'
++name: 'Topic'
+diff --git a/mmv1/products/pubsub/Schema.yaml b/mmv1/products/pubsub/Schema.yaml
+deleted file mode 100644
+--- a/mmv1/products/pubsub/Schema.yaml
++++ /dev/null
+diff --git a/mmv1/products/pubsub/Subscription.yaml b/mmv1/products/pubsub/Sub.yaml
+similarity index 90%
+rename from mmv1/products/pubsub/Subscription.yaml
+rename to mmv1/products/pubsub/Sub.yaml
+--- a/mmv1/products/pubsub/Subscription.yaml
++++ b/mmv1/products/pubsub/Sub.yaml
+diff --git a/mmv1/products/pubsub/Snapshot.yaml b/mmv1/products/pubsub/Snap.yaml
+similarity index 100%
+rename from mmv1/products/pubsub/Snapshot.yaml
+rename to mmv1/products/pubsub/Snap.yaml
+`
+	got := ChangedFilesFromDiff(diff)
+	want := []string{
+		"mmv1/products/pubsub/Topic.yaml",
+		"mmv1/products/pubsub/Schema.yaml",
+		"mmv1/products/pubsub/Subscription.yaml",
+		"mmv1/products/pubsub/Sub.yaml",
+		"mmv1/products/pubsub/Snapshot.yaml",
+		"mmv1/products/pubsub/Snap.yaml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFilesFromDiff() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadIndex(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	index, err := LoadIndex("beta")
+	if err != nil {
+		t.Fatalf("LoadIndex() error: %v", err)
+	}
+	got := index.AffectedTests([]string{"mmv1/products/pubsub/Topic.yaml"})
+	for _, want := range []string{"TestAccPubsubTopic_pubsubTopicBasicExample", "TestAccPubsubTopic_update"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("AffectedTests() = %v, want it to contain %s", got, want)
+		}
+	}
+	got = index.AffectedTests([]string{"mmv1/third_party/terraform/services/compute/resource_compute_instance.go.tmpl"})
+	if !slices.Contains(got, "TestAccComputeInstance_basic1") {
+		t.Errorf("AffectedTests() = %v, want it to contain TestAccComputeInstance_basic1", got)
+	}
+
+	if _, err := LoadIndex("alpha2"); err == nil {
+		t.Error("LoadIndex() with an unknown version succeeded, want error")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package affectedtests
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+var (
+	// resourcePattern matches the resource blocks in test configs.
+	resourcePattern = regexp.MustCompile(`resource\s+\\?"(google_\w+)\\?"`)
+	// testFuncPattern matches the declarations of tests.
+	testFuncPattern = regexp.MustCompile(`(?m)^func (Test\w+)\(`)
+	// templateLinePattern matches the lines of a .go.tmpl file that only
+	// hold template actions, such as {{- if ne $.TargetVersionName "ga" }}.
+	templateLinePattern = regexp.MustCompile(`(?m)^\s*{{.*}}\s*$`)
+)
+
+// readTestFile returns the tests declared in a test file, each with the
+// resources included in its configs. Configs are found in the test and in the
+// functions of the same file it calls, directly or through other functions.
+func readTestFile(path, content string) map[string]map[string]struct{} {
+	// Dropping the lines with template actions keeps both branches of
+	// version conditionals, which is usually valid Go.
+	src := templateLinePattern.ReplaceAllString(content, "")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return readTestFileFallback(content)
+	}
+
+	// The resources and calls of each top-level function.
+	resources := make(map[string]map[string]struct{})
+	calls := make(map[string]map[string]struct{})
+	for _, decl := range f.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil || funcDecl.Recv != nil {
+			continue
+		}
+		name := funcDecl.Name.Name
+		resources[name] = make(map[string]struct{})
+		calls[name] = make(map[string]struct{})
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BasicLit:
+				if n.Kind == token.STRING {
+					for _, match := range resourcePattern.FindAllStringSubmatch(n.Value, -1) {
+						resources[name][match[1]] = struct{}{}
+					}
+				}
+			case *ast.Ident:
+				calls[name][n.Name] = struct{}{}
+			}
+			return true
+		})
+	}
+
+	tests := make(map[string]map[string]struct{})
+	for name := range resources {
+		if !strings.HasPrefix(name, "Test") {
+			continue
+		}
+		testResources := make(map[string]struct{})
+		visited := make(map[string]struct{})
+		var visit func(string)
+		visit = func(funcName string) {
+			if _, ok := visited[funcName]; ok {
+				return
+			}
+			visited[funcName] = struct{}{}
+			for resource := range resources[funcName] {
+				testResources[resource] = struct{}{}
+			}
+			for call := range calls[funcName] {
+				if _, ok := resources[call]; ok {
+					visit(call)
+				}
+			}
+		}
+		visit(name)
+		tests[name] = testResources
+	}
+	return tests
+}
+
+// readTestFileFallback is used for files that can't be parsed. Every test in
+// the file is considered to include every resource in the file.
+func readTestFileFallback(content string) map[string]map[string]struct{} {
+	resources := make(map[string]struct{})
+	for _, match := range resourcePattern.FindAllStringSubmatch(content, -1) {
+		resources[match[1]] = struct{}{}
+	}
+	tests := make(map[string]map[string]struct{})
+	for _, match := range testFuncPattern.FindAllStringSubmatch(content, -1) {
+		tests[match[1]] = resources
+	}
+	return tests
+}
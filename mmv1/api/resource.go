@@ -2001,6 +2001,22 @@ func (r Resource) TGCTestIgnorePropertiesToStrings(e resource.Examples) []string
 	return props
 }
 
+// TGCRoundTripApiFields maps the leaf fields whose API name differs from their
+// terraform name to their API path, both in the metadata lineage format, so the
+// tgc round-trip test can look them up in the CAI asset data.
+func (r Resource) TGCRoundTripApiFields() map[string]string {
+	fields := make(map[string]string)
+	for _, p := range r.LeafProperties() {
+		if p.ProviderOnly() {
+			continue
+		}
+		if field, apiField := p.MetadataLineage(), p.MetadataApiLineage(); field != apiField {
+			fields[field] = apiField
+		}
+	}
+	return fields
+}
+
 // Filters out computed properties during cai2hcl
 func (r Resource) ReadPropertiesForTgc() []*Type {
 	return google.Reject(r.AllUserProperties(), func(v *Type) bool {
@@ -319,6 +319,45 @@ func TestLeafProperties(t *testing.T) {
 	}
 }
 
+func TestTGCRoundTripApiFields(t *testing.T) {
+	t.Parallel()
+
+	r := Resource{
+		BaseUrl: "test",
+		Properties: []*Type{
+			{
+				Name: "basic",
+				Type: "String",
+			},
+			{
+				Name:    "labelsMap",
+				ApiName: "labels",
+				Type:    "KeyValuePairs",
+			},
+			{
+				Name:    "root",
+				ApiName: "apiRoot",
+				Type:    "NestedObject",
+				Properties: []*Type{
+					{
+						Name: "fooBar",
+						Type: "String",
+					},
+				},
+			},
+		},
+	}
+	r.SetDefault(nil)
+
+	want := map[string]string{
+		"labels_map":   "labels",
+		"root.foo_bar": "api_root.foo_bar",
+	}
+	if got := r.TGCRoundTripApiFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TGCRoundTripApiFields() = %v, want %v", got, want)
+	}
+}
+
 // TestMagicianLocation verifies that the current package is being executed from within
 // the RELATIVE_MAGICIAN_LOCATION ("mmv1/") directory structure. This ensures that references
 // to files relative to this location will remain valid even if the repository structure
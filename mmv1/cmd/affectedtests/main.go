@@ -0,0 +1,64 @@
+// Copyright 2025 Google Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// affectedtests prints the acceptance tests affected by changes to Magic
+// Modules, one per line. It must be run from the mmv1 directory.
+//
+// Example usage: git diff main > /tmp/pr.diff && go run ./cmd/affectedtests --diff /tmp/pr.diff
+//
+// Changed files can also be passed as arguments, relative to the root of the
+// repository or to the mmv1 directory:
+//
+//	go run ./cmd/affectedtests products/pubsub/Topic.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/affectedtests"
+)
+
+var diffFile = flag.String("diff", "", "file containing a git diff to read the changed files from")
+
+var version = flag.String("version", "beta", "provider version to select tests for")
+
+func main() {
+	flag.Parse()
+
+	changedFiles := flag.Args()
+	if *diffFile != "" {
+		diff, err := os.ReadFile(*diffFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		changedFiles = append(changedFiles, affectedtests.ChangedFilesFromDiff(string(diff))...)
+	}
+	if len(changedFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "Either --diff or changed files must be given")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	index, err := affectedtests.LoadIndex(*version)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tests := index.AffectedTests(changedFiles)
+	log.Printf("%d changed files affect %d tests", len(changedFiles), len(tests))
+	for _, test := range tests {
+		fmt.Println(test)
+	}
+}
@@ -359,6 +359,8 @@ func (tgc TerraformGoogleConversion) CopyCommonFiles(outputFolder string, genera
 		"converters/google/resources/cai.go":                                                    "third_party/tgc/cai.go",
 		"converters/google/resources/cai/cai.go":                                                "third_party/tgc/cai/cai.go",
 		"converters/google/resources/cai/cai_test.go":                                           "third_party/tgc/cai/cai_test.go",
+		"converters/google/resources/cai/cai_export.go":                                         "third_party/tgc/cai/cai_export.go",
+		"converters/google/resources/cai/cai_export_test.go":                                    "third_party/tgc/cai/cai_export_test.go",
 		"converters/google/resources/services/resourcemanager/org_policy_policy.go":             "third_party/tgc/services/resourcemanager/org_policy_policy.go",
 		"converters/google/resources/getconfig.go":                                              "third_party/tgc/getconfig.go",
 		"converters/google/resources/services/resourcemanager/folder.go":                        "third_party/tgc/services/resourcemanager/folder.go",
@@ -414,6 +416,8 @@ func (tgc TerraformGoogleConversion) CopyCommonFiles(outputFolder string, genera
 		"ancestrymanager/ancestrymanager_test.go":                                               "third_party/tgc/ancestrymanager/ancestrymanager_test.go",
 		"ancestrymanager/ancestryutil.go":                                                       "third_party/tgc/ancestrymanager/ancestryutil.go",
 		"ancestrymanager/ancestryutil_test.go":                                                  "third_party/tgc/ancestrymanager/ancestryutil_test.go",
+		"ancestrymanager/cai_export.go":                                                         "third_party/tgc/ancestrymanager/cai_export.go",
+		"ancestrymanager/cai_export_test.go":                                                    "third_party/tgc/ancestrymanager/cai_export_test.go",
 		"converters/google/convert.go":                                                          "third_party/tgc/convert.go",
 		"converters/google/convert_test.go":                                                     "third_party/tgc/convert_test.go",
 		"tfdata/fake_resource_data.go":                                                          "third_party/tgc/tfdata/fake_resource_data.go",
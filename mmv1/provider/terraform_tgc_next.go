@@ -136,6 +136,18 @@ func (tgc *TerraformGoogleConversionNext) GenerateResourceTests(object api.Resou
 	}
 	targetFilePath := path.Join(targetFolder, fmt.Sprintf("%s_%s_generated_test.go", productName, google.Underscore(object.Name)))
 	templateData.GenerateTGCNextTestFile(targetFilePath, object)
+
+	// Write the example configs used by test.RoundTripConversion.
+	testDataFolder := path.Join(targetFolder, "testdata")
+	if err := os.MkdirAll(testDataFolder, os.ModePerm); err != nil {
+		log.Println(fmt.Errorf("error creating parent directory %v: %v", testDataFolder, err))
+	}
+	for _, example := range object.TestExamples() {
+		configPath := path.Join(testDataFolder, fmt.Sprintf("%s.tf", example.TestSlug(object.ProductMetadata.Name, object.Name)))
+		if err := os.WriteFile(configPath, []byte(example.TestHCLText), 0644); err != nil {
+			log.Println(fmt.Errorf("error writing example config %v: %v", configPath, err))
+		}
+	}
 }
 
 func (tgc TerraformGoogleConversionNext) CompileCommonFiles(outputFolder string, products []*api.Product, overridePath string) {
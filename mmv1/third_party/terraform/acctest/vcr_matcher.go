@@ -0,0 +1,476 @@
+package acctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// VcrFieldRules lists request fields that don't need to be equal for a request
+// to match a recorded interaction.
+type VcrFieldRules struct {
+	// JSON body fields that are ignored, as dot-separated paths from the
+	// top-level object, such as "metadata.requestId". Arrays are traversed
+	// transparently. A leading ".." matches at any depth.
+	IgnoreBodyPaths []string `json:"ignore_body_paths"`
+	// URL query parameters that are ignored.
+	IgnoreQueryParams []string `json:"ignore_query_params"`
+	// JSON body lists, as paths like IgnoreBodyPaths, whose elements may be
+	// sent in any order.
+	UnorderedLists []string `json:"unordered_lists"`
+}
+
+// VcrResourceRules are field rules that only apply to requests whose URL
+// matches a regular expression, such as "compute.googleapis.com/.*/instances".
+type VcrResourceRules struct {
+	URL string `json:"url"`
+	VcrFieldRules
+}
+
+// VcrMatchRules configures how requests are matched against recorded
+// interactions when replaying. Requests must have the same method and URL,
+// and equal bodies, apart from the fields in the rules.
+type VcrMatchRules struct {
+	VcrFieldRules
+	Resources []VcrResourceRules `json:"resources"`
+}
+
+var DefaultVcrMatchRules = VcrMatchRules{
+	VcrFieldRules: VcrFieldRules{
+		// Idempotency keys are generated per request
+		IgnoreBodyPaths:   []string{"..requestId"},
+		IgnoreQueryParams: []string{"requestId"},
+	},
+}
+
+// vcrMatchRulesFromEnv returns the default rules extended with the ones in the
+// JSON file at VCR_MATCH_RULES, if set.
+func vcrMatchRulesFromEnv() (VcrMatchRules, error) {
+	path := os.Getenv("VCR_MATCH_RULES")
+	if path == "" {
+		return DefaultVcrMatchRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return VcrMatchRules{}, err
+	}
+	var extra VcrMatchRules
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return VcrMatchRules{}, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return VcrMatchRules{
+		VcrFieldRules: VcrFieldRules{
+			IgnoreBodyPaths:   append(append([]string{}, DefaultVcrMatchRules.IgnoreBodyPaths...), extra.IgnoreBodyPaths...),
+			IgnoreQueryParams: append(append([]string{}, DefaultVcrMatchRules.IgnoreQueryParams...), extra.IgnoreQueryParams...),
+			UnorderedLists:    append(append([]string{}, DefaultVcrMatchRules.UnorderedLists...), extra.UnorderedLists...),
+		},
+		Resources: append(append([]VcrResourceRules{}, DefaultVcrMatchRules.Resources...), extra.Resources...),
+	}, nil
+}
+
+// vcrDebugEnabled returns whether failed replays should report the closest
+// recorded interaction.
+func vcrDebugEnabled() bool {
+	return os.Getenv("VCR_MATCH_DEBUG") != ""
+}
+
+type vcrFieldMatcher struct {
+	ignoreBodyPaths   []vcrJSONPath
+	ignoreQueryParams map[string]bool
+	unorderedLists    []vcrJSONPath
+}
+
+type vcrResourceMatcher struct {
+	url *regexp.Regexp
+	vcrFieldMatcher
+}
+
+type vcrMatcher struct {
+	global    vcrFieldMatcher
+	resources []vcrResourceMatcher
+}
+
+func newVcrFieldMatcher(rules VcrFieldRules) (vcrFieldMatcher, error) {
+	ignoreBodyPaths, err := parseVcrJSONPaths(rules.IgnoreBodyPaths)
+	if err != nil {
+		return vcrFieldMatcher{}, err
+	}
+	unorderedLists, err := parseVcrJSONPaths(rules.UnorderedLists)
+	if err != nil {
+		return vcrFieldMatcher{}, err
+	}
+	sortVcrListPaths(unorderedLists)
+	m := vcrFieldMatcher{
+		ignoreBodyPaths:   ignoreBodyPaths,
+		ignoreQueryParams: make(map[string]bool),
+		unorderedLists:    unorderedLists,
+	}
+	for _, param := range rules.IgnoreQueryParams {
+		m.ignoreQueryParams[param] = true
+	}
+	return m, nil
+}
+
+// sortVcrListPaths sorts longer paths first, so that lists nested in unordered
+// lists are sorted before the lists that contain them.
+func sortVcrListPaths(paths []vcrJSONPath) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i].keys) > len(paths[j].keys)
+	})
+}
+
+func newVcrMatcher(rules VcrMatchRules) (*vcrMatcher, error) {
+	global, err := newVcrFieldMatcher(rules.VcrFieldRules)
+	if err != nil {
+		return nil, err
+	}
+	m := &vcrMatcher{global: global}
+	for _, r := range rules.Resources {
+		re, err := regexp.Compile(r.URL)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling VCR match rule URL %q: %w", r.URL, err)
+		}
+		fm, err := newVcrFieldMatcher(r.VcrFieldRules)
+		if err != nil {
+			return nil, err
+		}
+		m.resources = append(m.resources, vcrResourceMatcher{url: re, vcrFieldMatcher: fm})
+	}
+	return m, nil
+}
+
+// rulesFor returns the global rules combined with the rules of every resource
+// that the URL matches.
+func (m *vcrMatcher) rulesFor(url string) vcrFieldMatcher {
+	rules := m.global
+	for _, r := range m.resources {
+		if !r.url.MatchString(url) {
+			continue
+		}
+		combined := vcrFieldMatcher{
+			ignoreBodyPaths:   append(append([]vcrJSONPath{}, rules.ignoreBodyPaths...), r.ignoreBodyPaths...),
+			ignoreQueryParams: make(map[string]bool),
+			unorderedLists:    append(append([]vcrJSONPath{}, rules.unorderedLists...), r.unorderedLists...),
+		}
+		sortVcrListPaths(combined.unorderedLists)
+		for param := range rules.ignoreQueryParams {
+			combined.ignoreQueryParams[param] = true
+		}
+		for param := range r.ignoreQueryParams {
+			combined.ignoreQueryParams[param] = true
+		}
+		rules = combined
+	}
+	return rules
+}
+
+// NewVcrMatcherFuncWithRules returns a function used for matching HTTP requests
+// with data recorded in VCR cassettes, ignoring the fields in rules.
+func NewVcrMatcherFuncWithRules(ctx context.Context, rules VcrMatchRules) (func(r *http.Request, i cassette.Request) bool, error) {
+	m, err := newVcrMatcher(rules)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *http.Request, i cassette.Request) bool {
+		return m.match(ctx, r, i)
+	}, nil
+}
+
+func (m *vcrMatcher) match(ctx context.Context, r *http.Request, i cassette.Request) bool {
+	if r.Method != i.Method {
+		return false
+	}
+	rules := m.rulesFor(r.URL.String())
+	if !rules.urlsMatch(r.URL, i.URL) {
+		return false
+	}
+	if r.Body == nil {
+		return true
+	}
+
+	var b bytes.Buffer
+	if _, err := b.ReadFrom(r.Body); err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Failed to read request body from cassette: %v", err))
+		return false
+	}
+	r.Body = io.NopCloser(&b)
+	reqBody := b.String()
+	// If body matches identically, we are done
+	if reqBody == i.Body {
+		return true
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if strings.Contains(contentType, "multipart/related") {
+		return rules.multipartBodiesMatch(ctx, reqBody, contentType, i)
+	}
+	// JSON might be the same, but reordered or with ignored fields. Try
+	// parsing json and comparing
+	if strings.Contains(contentType, "application/json") {
+		return rules.jsonBodiesMatch(ctx, reqBody, i.Body)
+	}
+	return false
+}
+
+func (rules vcrFieldMatcher) urlsMatch(u *url.URL, recorded string) bool {
+	if u.String() == recorded {
+		return true
+	}
+	recordedURL, err := url.Parse(recorded)
+	if err != nil {
+		return false
+	}
+	reqURL := *u
+	reqQuery, recordedQuery := reqURL.Query(), recordedURL.Query()
+	reqURL.RawQuery, recordedURL.RawQuery = "", ""
+	if reqURL.String() != recordedURL.String() {
+		return false
+	}
+	for param := range rules.ignoreQueryParams {
+		reqQuery.Del(param)
+		recordedQuery.Del(param)
+	}
+	return reqQuery.Encode() == recordedQuery.Encode()
+}
+
+func (rules vcrFieldMatcher) jsonBodiesMatch(ctx context.Context, reqBody, recordedBody string) bool {
+	var reqJson, cassetteJson interface{}
+	if err := json.Unmarshal([]byte(reqBody), &reqJson); err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Failed to unmarshal request json: %v", err))
+		return false
+	}
+	if err := json.Unmarshal([]byte(recordedBody), &cassetteJson); err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Failed to unmarshal cassette json: %v", err))
+		return false
+	}
+	rules.normalizeJSON(reqJson)
+	rules.normalizeJSON(cassetteJson)
+	return reflect.DeepEqual(reqJson, cassetteJson)
+}
+
+// normalizeJSON removes ignored fields and sorts unordered lists.
+func (rules vcrFieldMatcher) normalizeJSON(v interface{}) {
+	for _, path := range rules.ignoreBodyPaths {
+		path.visit(v, func(obj map[string]interface{}, key string) bool {
+			delete(obj, key)
+			return true
+		})
+	}
+	for _, path := range rules.unorderedLists {
+		path.visit(v, sortVcrJSONList)
+	}
+}
+
+func sortVcrJSONList(obj map[string]interface{}, key string) bool {
+	list, ok := obj[key].([]interface{})
+	if !ok {
+		return false
+	}
+	keys := make(map[int]string, len(list))
+	indices := make([]int, len(list))
+	for i, elem := range list {
+		// Map keys are encoded in sorted order, so equal elements have
+		// equal encodings.
+		encoded, _ := json.Marshal(elem)
+		keys[i] = string(encoded)
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return keys[indices[i]] < keys[indices[j]]
+	})
+	sorted := make([]interface{}, len(list))
+	for i, index := range indices {
+		sorted[i] = list[index]
+	}
+	obj[key] = sorted
+	return true
+}
+
+type vcrBodyPart struct {
+	contentType string
+	body        string
+}
+
+// multipartBodiesMatch compares multipart/related bodies, such as media
+// uploads, part by part. Boundaries are random, so bodies never match
+// identically. Bodies that can't be parsed are assumed to match.
+func (rules vcrFieldMatcher) multipartBodiesMatch(ctx context.Context, reqBody, contentType string, i cassette.Request) bool {
+	reqParts, err := parseVcrMultipartBody(reqBody, contentType)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Failed to parse multipart request body: %v", err))
+		return true
+	}
+	recordedParts, err := parseVcrMultipartBody(i.Body, i.Headers.Get("Content-Type"))
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Failed to parse multipart cassette body: %v", err))
+		return true
+	}
+	if len(reqParts) != len(recordedParts) {
+		return false
+	}
+	for j, part := range reqParts {
+		recorded := recordedParts[j]
+		if part.contentType != recorded.contentType {
+			return false
+		}
+		if part.body == recorded.body {
+			continue
+		}
+		if !strings.Contains(part.contentType, "application/json") || !rules.jsonBodiesMatch(ctx, part.body, recorded.body) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseVcrMultipartBody(body, contentType string) ([]vcrBodyPart, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("no boundary in Content-Type %q", contentType)
+	}
+	var parts []vcrBodyPart
+	r := multipart.NewReader(strings.NewReader(body), boundary)
+	for {
+		p, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, vcrBodyPart{contentType: p.Header.Get("Content-Type"), body: string(data)})
+	}
+}
+
+// vcrDebugRecorder replays like the wrapped recorder, but when no recorded
+// interaction matches a request, the error describes the closest one.
+type vcrDebugRecorder struct {
+	*recorder.Recorder
+	cassettePath string
+	matcher      *vcrMatcher
+}
+
+func (r *vcrDebugRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.Recorder.RoundTrip(req)
+	if !errors.Is(err, cassette.ErrInteractionNotFound) {
+		return resp, err
+	}
+	return nil, fmt.Errorf("%w: %s %s\n%s", err, req.Method, req.URL, r.closestInteraction(req, string(body)))
+}
+
+// closestInteraction describes the recorded interaction that is most similar
+// to the request, and how they differ.
+func (r *vcrDebugRecorder) closestInteraction(req *http.Request, body string) string {
+	c, err := cassette.Load(r.cassettePath)
+	if err != nil {
+		return fmt.Sprintf("error loading cassette to find the closest interaction: %s", err)
+	}
+	rules := r.matcher.rulesFor(req.URL.String())
+	var closest *cassette.Interaction
+	closestScore := -1
+	for _, i := range c.Interactions {
+		score := 0
+		if i.Request.Method == req.Method {
+			score += 4
+		}
+		if recordedURL, err := url.Parse(i.Request.URL); err == nil && recordedURL.Host == req.URL.Host && recordedURL.Path == req.URL.Path {
+			score += 2
+		}
+		if rules.urlsMatch(req.URL, i.Request.URL) {
+			score++
+		}
+		if score > closestScore {
+			closest, closestScore = i, score
+		}
+	}
+	if closest == nil {
+		return "the cassette has no interactions"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "closest recorded interaction (it may have been replayed already): %s %s\n", closest.Request.Method, closest.Request.URL)
+	if closest.Request.Method != req.Method {
+		fmt.Fprintf(&sb, "  method: %s != %s\n", req.Method, closest.Request.Method)
+	}
+	if !rules.urlsMatch(req.URL, closest.Request.URL) {
+		fmt.Fprintf(&sb, "  url: %s != %s\n", req.URL, closest.Request.URL)
+	}
+	var reqJson, cassetteJson interface{}
+	if json.Unmarshal([]byte(body), &reqJson) == nil && json.Unmarshal([]byte(closest.Request.Body), &cassetteJson) == nil {
+		rules.normalizeJSON(reqJson)
+		rules.normalizeJSON(cassetteJson)
+		for _, d := range diffVcrJSON("", reqJson, cassetteJson) {
+			fmt.Fprintf(&sb, "  body %s\n", d)
+		}
+	} else if body != closest.Request.Body {
+		fmt.Fprintf(&sb, "  body: %q != %q\n", body, closest.Request.Body)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// diffVcrJSON returns the paths at which the request and recorded JSON values
+// differ, with both values.
+func diffVcrJSON(path string, req, recorded interface{}) []string {
+	reqObj, reqIsObj := req.(map[string]interface{})
+	recordedObj, recordedIsObj := recorded.(map[string]interface{})
+	if reqIsObj && recordedIsObj {
+		keys := make(map[string]bool)
+		for k := range reqObj {
+			keys[k] = true
+		}
+		for k := range recordedObj {
+			keys[k] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+		var diffs []string
+		for _, k := range sortedKeys {
+			diffs = append(diffs, diffVcrJSON(strings.TrimPrefix(path+"."+k, "."), reqObj[k], recordedObj[k])...)
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(req, recorded) {
+		return nil
+	}
+	reqJson, _ := json.Marshal(req)
+	recordedJson, _ := json.Marshal(recorded)
+	if path == "" {
+		path = "."
+	}
+	return []string{fmt.Sprintf("%s: %s != %s", path, reqJson, recordedJson)}
+}
@@ -0,0 +1,166 @@
+package acctest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestNewVcrMatcherFuncWithRules(t *testing.T) {
+	rules := acctest.VcrMatchRules{
+		VcrFieldRules: acctest.VcrFieldRules{
+			IgnoreBodyPaths:   []string{"..requestId", "labels.generated"},
+			IgnoreQueryParams: []string{"requestId"},
+		},
+		Resources: []acctest.VcrResourceRules{{
+			URL: `compute\.googleapis\.com/.*/instances`,
+			VcrFieldRules: acctest.VcrFieldRules{
+				IgnoreQueryParams: []string{"discardLocalSsd"},
+				UnorderedLists:    []string{"networkInterfaces", "networkInterfaces.accessConfigs"},
+			},
+		}},
+	}
+	instancesURL := "https://compute.googleapis.com/compute/v1/projects/p/zones/z/instances"
+
+	cases := map[string]struct {
+		method       string
+		url          string
+		contentType  string
+		body         string
+		cassetteURL  string
+		cassetteType string
+		cassetteBody string
+		wantMatch    bool
+	}{
+		"ignored global query param": {
+			method:      "GET",
+			url:         "https://example.com/foobar?alt=json&requestId=1",
+			cassetteURL: "https://example.com/foobar?alt=json&requestId=2",
+			wantMatch:   true,
+		},
+		"different query param": {
+			method:      "GET",
+			url:         "https://example.com/foobar?alt=json",
+			cassetteURL: "https://example.com/foobar?alt=proto",
+			wantMatch:   false,
+		},
+		"ignored resource query param": {
+			method:      "POST",
+			url:         instancesURL + "/i/stop?discardLocalSsd=true",
+			cassetteURL: instancesURL + "/i/stop?discardLocalSsd=false",
+			wantMatch:   true,
+		},
+		"resource query param ignored only for the resource": {
+			method:      "POST",
+			url:         "https://example.com/foobar?discardLocalSsd=true",
+			cassetteURL: "https://example.com/foobar?discardLocalSsd=false",
+			wantMatch:   false,
+		},
+		"ignored body paths": {
+			method:       "POST",
+			url:          "https://example.com/foobar",
+			contentType:  "application/json",
+			body:         `{"name":"a","labels":{"generated":"x1","env":"test"},"metadata":{"requestId":"r1"}}`,
+			cassetteURL:  "https://example.com/foobar",
+			cassetteBody: `{"name":"a","labels":{"generated":"x2","env":"test"},"metadata":{"requestId":"r2"}}`,
+			wantMatch:    true,
+		},
+		"different body field": {
+			method:       "POST",
+			url:          "https://example.com/foobar",
+			contentType:  "application/json",
+			body:         `{"name":"a","labels":{"env":"test"}}`,
+			cassetteURL:  "https://example.com/foobar",
+			cassetteBody: `{"name":"a","labels":{"env":"prod"}}`,
+			wantMatch:    false,
+		},
+		"unordered lists": {
+			method:       "POST",
+			url:          instancesURL,
+			contentType:  "application/json",
+			body:         `{"networkInterfaces":[{"network":"b","accessConfigs":[{"name":"y"},{"name":"x"}]},{"network":"a"}]}`,
+			cassetteURL:  instancesURL,
+			cassetteBody: `{"networkInterfaces":[{"network":"a"},{"network":"b","accessConfigs":[{"name":"x"},{"name":"y"}]}]}`,
+			wantMatch:    true,
+		},
+		"lists are ordered unless marked unordered": {
+			method:       "POST",
+			url:          "https://example.com/foobar",
+			contentType:  "application/json",
+			body:         `{"networkInterfaces":[{"network":"b"},{"network":"a"}]}`,
+			cassetteURL:  "https://example.com/foobar",
+			cassetteBody: `{"networkInterfaces":[{"network":"a"},{"network":"b"}]}`,
+			wantMatch:    false,
+		},
+		"multipart bodies with different boundaries": {
+			method:       "POST",
+			url:          "https://storage.googleapis.com/upload/storage/v1/b/bucket/o",
+			contentType:  "multipart/related; boundary=aaa",
+			body:         multipartBody("aaa", `{"name":"object","metadata":{"requestId":"r1"}}`, "content"),
+			cassetteURL:  "https://storage.googleapis.com/upload/storage/v1/b/bucket/o",
+			cassetteType: "multipart/related; boundary=bbb",
+			cassetteBody: multipartBody("bbb", `{"metadata":{"requestId":"r2"},"name":"object"}`, "content"),
+			wantMatch:    true,
+		},
+		"multipart bodies with different media": {
+			method:       "POST",
+			url:          "https://storage.googleapis.com/upload/storage/v1/b/bucket/o",
+			contentType:  "multipart/related; boundary=aaa",
+			body:         multipartBody("aaa", `{"name":"object"}`, "content"),
+			cassetteURL:  "https://storage.googleapis.com/upload/storage/v1/b/bucket/o",
+			cassetteType: "multipart/related; boundary=bbb",
+			cassetteBody: multipartBody("bbb", `{"name":"object"}`, "other content"),
+			wantMatch:    false,
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			matcher, err := acctest.NewVcrMatcherFuncWithRules(context.Background(), rules)
+			if err != nil {
+				t.Fatalf("NewVcrMatcherFuncWithRules() error: %s", err)
+			}
+			req, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			cassetteReq := cassette.Request{
+				Method:  tc.method,
+				URL:     tc.cassetteURL,
+				Body:    tc.cassetteBody,
+				Headers: http.Header{"Content-Type": []string{tc.cassetteType}},
+			}
+
+			if got := matcher(req, cassetteReq); got != tc.wantMatch {
+				t.Errorf("matcher() = %t, want %t", got, tc.wantMatch)
+			}
+		})
+	}
+}
+
+func TestNewVcrMatcherFunc_ignoresRequestIds(t *testing.T) {
+	matcher := acctest.NewVcrMatcherFunc(context.Background())
+	req, err := http.NewRequest("POST", "https://example.com/foobar?requestId=1", strings.NewReader(`{"name":"a","requestId":"1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	cassetteReq := cassette.Request{
+		Method: "POST",
+		URL:    "https://example.com/foobar?requestId=2",
+		Body:   `{"name":"a","requestId":"2"}`,
+	}
+	if !matcher(req, cassetteReq) {
+		t.Errorf("expected matcher to match requests that only differ in request IDs")
+	}
+}
+
+func multipartBody(boundary, metadata, media string) string {
+	return fmt.Sprintf("--%[1]s\r\nContent-Type: application/json; charset=UTF-8\r\n\r\n%[2]s\r\n--%[1]s\r\nContent-Type: text/plain\r\n\r\n%[3]s\r\n--%[1]s--\r\n", boundary, metadata, media)
+}
@@ -0,0 +1,314 @@
+package acctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dnaeon/go-vcr/cassette"
+)
+
+// vcrRedacted replaces secret values in saved cassettes.
+const vcrRedacted = "REDACTED"
+
+// vcrProjectNumberPlaceholder replaces GOOGLE_PROJECT_NUMBER in saved cassettes.
+// It is swapped back before replaying so that requests still match.
+const vcrProjectNumberPlaceholder = "000000000000"
+
+// VcrScrubRules configures what is redacted from cassettes when they are saved.
+// The magician's scrub-cassettes command uses the same format and defaults to
+// check cassettes before they are uploaded.
+type VcrScrubRules struct {
+	// Request and response headers whose values are redacted.
+	Headers []string `json:"headers"`
+	// Response body JSON fields whose values are redacted, as dot-separated
+	// paths from the top-level object, such as "keys.privateKeyData". Arrays
+	// are traversed transparently. A leading ".." matches at any depth.
+	BodyPaths []string `json:"body_paths"`
+	// Regular expressions whose matches are redacted from response bodies
+	// and from headers.
+	Patterns []string `json:"patterns"`
+}
+
+var DefaultVcrScrubRules = VcrScrubRules{
+	Headers: []string{"Authorization", "Cookie", "Set-Cookie", "X-Goog-Api-Key"},
+	BodyPaths: []string{
+		"..privateKeyData",
+		"..privateKey",
+		"..private_key",
+		"..accessToken",
+		"..access_token",
+		"..refreshToken",
+		"..refresh_token",
+		"..idToken",
+		"..id_token",
+		"..clientSecret",
+		"..client_secret",
+	},
+	Patterns: []string{
+		// OAuth access tokens
+		`ya29\.[\w.-]+`,
+		// API keys
+		`AIza[\w-]{35}`,
+		// PEM private keys, such as the ones in service account key files
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	},
+}
+
+// vcrScrubRulesFromEnv returns the default rules extended with the ones in the
+// JSON file at VCR_SCRUB_RULES, if set.
+func vcrScrubRulesFromEnv() (VcrScrubRules, error) {
+	path := os.Getenv("VCR_SCRUB_RULES")
+	if path == "" {
+		return DefaultVcrScrubRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return VcrScrubRules{}, err
+	}
+	var extra VcrScrubRules
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return VcrScrubRules{}, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return VcrScrubRules{
+		Headers:   append(append([]string{}, DefaultVcrScrubRules.Headers...), extra.Headers...),
+		BodyPaths: append(append([]string{}, DefaultVcrScrubRules.BodyPaths...), extra.BodyPaths...),
+		Patterns:  append(append([]string{}, DefaultVcrScrubRules.Patterns...), extra.Patterns...),
+	}, nil
+}
+
+type vcrScrubber struct {
+	headers       map[string]bool
+	paths         []vcrJSONPath
+	patterns      []*regexp.Regexp
+	projectNumber *regexp.Regexp
+}
+
+// vcrJSONPath is a dot-separated path to fields of a JSON object. Arrays are
+// traversed transparently, and a leading ".." matches at any depth.
+type vcrJSONPath struct {
+	anyDepth bool
+	keys     []string
+}
+
+func parseVcrJSONPaths(paths []string) ([]vcrJSONPath, error) {
+	var parsed []vcrJSONPath
+	for _, p := range paths {
+		path := vcrJSONPath{}
+		if rest, ok := strings.CutPrefix(p, ".."); ok {
+			path.anyDepth = true
+			p = rest
+		}
+		if p == "" {
+			return nil, fmt.Errorf("empty JSON path in VCR rules")
+		}
+		path.keys = strings.Split(p, ".")
+		parsed = append(parsed, path)
+	}
+	return parsed, nil
+}
+
+func newVcrScrubber(rules VcrScrubRules, projectNumber string) (*vcrScrubber, error) {
+	s := &vcrScrubber{headers: make(map[string]bool)}
+	for _, h := range rules.Headers {
+		s.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	paths, err := parseVcrJSONPaths(rules.BodyPaths)
+	if err != nil {
+		return nil, err
+	}
+	s.paths = paths
+	for _, p := range rules.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling VCR scrub pattern %q: %w", p, err)
+		}
+		s.patterns = append(s.patterns, re)
+	}
+	if projectNumber != "" && projectNumber != vcrProjectNumberPlaceholder {
+		s.projectNumber = projectNumberRegexp(projectNumber)
+	}
+	return s, nil
+}
+
+func projectNumberRegexp(projectNumber string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(projectNumber) + `\b`)
+}
+
+// scrubInteraction redacts secrets from an interaction. Request URLs and bodies
+// are only changed for the project number, since replaying matches requests on
+// them.
+func (s *vcrScrubber) scrubInteraction(i *cassette.Interaction) {
+	i.Request.URL = s.replaceProjectNumber(i.Request.URL)
+	i.Request.Body = s.replaceProjectNumber(i.Request.Body)
+	for _, values := range i.Request.Form {
+		for j := range values {
+			values[j] = s.replaceProjectNumber(values[j])
+		}
+	}
+	s.scrubHeaders(i.Request.Headers)
+	s.scrubHeaders(i.Response.Headers)
+	i.Response.Body = s.redactPatterns(s.redactBodyPaths(i.Response.Body))
+}
+
+func (s *vcrScrubber) replaceProjectNumber(text string) string {
+	if s.projectNumber == nil {
+		return text
+	}
+	return s.projectNumber.ReplaceAllString(text, vcrProjectNumberPlaceholder)
+}
+
+func (s *vcrScrubber) redactPatterns(text string) string {
+	for _, re := range s.patterns {
+		text = re.ReplaceAllString(text, vcrRedacted)
+	}
+	return s.replaceProjectNumber(text)
+}
+
+func (s *vcrScrubber) scrubHeaders(headers http.Header) {
+	for key, values := range headers {
+		for j := range values {
+			if s.headers[http.CanonicalHeaderKey(key)] {
+				values[j] = vcrRedacted
+				continue
+			}
+			values[j] = s.redactPatterns(values[j])
+		}
+	}
+}
+
+func (s *vcrScrubber) redactBodyPaths(body string) string {
+	if len(s.paths) == 0 {
+		return body
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return body
+	}
+	changed := false
+	for _, path := range s.paths {
+		if path.visit(v, redactVcrJSONField) {
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// visit calls f with the object and key of each field matching the path, and
+// returns whether any call returned true.
+func (p vcrJSONPath) visit(v interface{}, f func(obj map[string]interface{}, key string) bool) bool {
+	changed := visitVcrJSONKeys(v, p.keys, f)
+	if !p.anyDepth {
+		return changed
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			changed = p.visit(child, f) || changed
+		}
+	case []interface{}:
+		for _, child := range v {
+			changed = p.visit(child, f) || changed
+		}
+	}
+	return changed
+}
+
+func visitVcrJSONKeys(v interface{}, keys []string, f func(obj map[string]interface{}, key string) bool) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		child, ok := v[keys[0]]
+		if !ok {
+			return false
+		}
+		if len(keys) == 1 {
+			return f(v, keys[0])
+		}
+		return visitVcrJSONKeys(child, keys[1:], f)
+	case []interface{}:
+		changed := false
+		for _, child := range v {
+			changed = visitVcrJSONKeys(child, keys, f) || changed
+		}
+		return changed
+	}
+	return false
+}
+
+func redactVcrJSONField(obj map[string]interface{}, key string) bool {
+	if obj[key] == vcrRedacted {
+		return false
+	}
+	obj[key] = vcrRedacted
+	return true
+}
+
+// ScrubCassette redacts secrets from the cassette saved at path (without the
+// .yaml extension) using the default rules extended with VCR_SCRUB_RULES, and
+// replaces projectNumber with a placeholder.
+func ScrubCassette(path, projectNumber string) error {
+	rules, err := vcrScrubRulesFromEnv()
+	if err != nil {
+		return err
+	}
+	s, err := newVcrScrubber(rules, projectNumber)
+	if err != nil {
+		return err
+	}
+	c, err := cassette.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// go-vcr doesn't save cassettes without interactions.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, i := range c.Interactions {
+		s.scrubInteraction(i)
+	}
+	return c.Save()
+}
+
+// restoreCassetteProjectNumber writes a copy of the cassette at path (without
+// the .yaml extension) to a temporary directory, with the project number
+// placeholder replaced by projectNumber, and returns the path of the copy.
+// Cassettes without the placeholder are replayed from path. The caller removes
+// the directory of the copy when it's done with it.
+func restoreCassetteProjectNumber(path, projectNumber string) (string, error) {
+	data, err := os.ReadFile(path + ".yaml")
+	if err != nil {
+		return "", err
+	}
+	placeholder := projectNumberRegexp(vcrProjectNumberPlaceholder)
+	if !placeholder.Match(data) {
+		return path, nil
+	}
+	dir, err := os.MkdirTemp("", "vcr-cassettes")
+	if err != nil {
+		return "", err
+	}
+	restored := placeholder.ReplaceAll(data, []byte(projectNumber))
+	restoredPath := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(restoredPath+".yaml", restored, 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return restoredPath, nil
+}
@@ -0,0 +1,104 @@
+package acctest_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestScrubCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TestAccServiceAccountKey_basic")
+	cassette := `---
+version: 1
+interactions:
+- request:
+    body: '{"privateKeyType":"TYPE_GOOGLE_CREDENTIALS_FILE"}'
+    form: {}
+    headers:
+      Authorization:
+      - Bearer ya29.a0AfB_byC-secret
+      Content-Type:
+      - application/json
+    url: https://iam.googleapis.com/v1/projects/123456789012/serviceAccounts/sa/keys?alt=json
+    method: POST
+  response:
+    body: '{"name":"projects/123456789012/serviceAccounts/sa/keys/abc","privateKeyData":"c2VjcmV0"}'
+    headers:
+      Set-Cookie:
+      - NID=secret
+    status: 200 OK
+    code: 200
+    duration: ""
+`
+	if err := os.WriteFile(path+".yaml", []byte(cassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acctest.ScrubCassette(path, "123456789012"); err != nil {
+		t.Fatalf("ScrubCassette() error: %s", err)
+	}
+	data, err := os.ReadFile(path + ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scrubbed := string(data)
+	for _, secret := range []string{"ya29.", "c2VjcmV0", "NID=secret", "123456789012"} {
+		if strings.Contains(scrubbed, secret) {
+			t.Errorf("ScrubCassette() left %q in the cassette:\n%s", secret, scrubbed)
+		}
+	}
+	for _, kept := range []string{
+		`{"privateKeyType":"TYPE_GOOGLE_CREDENTIALS_FILE"}`,
+		"https://iam.googleapis.com/v1/projects/000000000000/serviceAccounts/sa/keys?alt=json",
+		"application/json",
+	} {
+		if !strings.Contains(scrubbed, kept) {
+			t.Errorf("ScrubCassette() removed %q from the cassette:\n%s", kept, scrubbed)
+		}
+	}
+
+	if err := acctest.ScrubCassette(filepath.Join(t.TempDir(), "TestAccMissing"), "123456789012"); err != nil {
+		t.Errorf("ScrubCassette() of a missing cassette returned an error: %s", err)
+	}
+}
+
+// TestScrubCassetteGolden checks ScrubCassette against testdata/scrub, which
+// the magician's scrub-cassettes command is tested against as well.
+func TestScrubCassetteGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "scrub", "cassette.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cassette")
+	if err := os.WriteFile(path+".yaml", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acctest.ScrubCassette(path, "123456789012"); err != nil {
+		t.Fatalf("ScrubCassette() error: %s", err)
+	}
+	got, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := cassette.Load(filepath.Join("testdata", "scrub", "scrubbed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Interactions) != len(want.Interactions) {
+		t.Fatalf("ScrubCassette() kept %d interactions, want %d", len(got.Interactions), len(want.Interactions))
+	}
+	for i := range want.Interactions {
+		if !reflect.DeepEqual(got.Interactions[i].Request, want.Interactions[i].Request) {
+			t.Errorf("ScrubCassette() interaction %d request = %+v, want %+v", i, got.Interactions[i].Request, want.Interactions[i].Request)
+		}
+		if !reflect.DeepEqual(got.Interactions[i].Response, want.Interactions[i].Response) {
+			t.Errorf("ScrubCassette() interaction %d response = %+v, want %+v", i, got.Interactions[i].Response, want.Interactions[i].Response)
+		}
+	}
+}
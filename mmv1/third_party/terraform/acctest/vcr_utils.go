@@ -3,10 +3,8 @@ package acctest
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math/rand"
 	"net/http"
@@ -47,11 +45,16 @@ func IsVcrEnabled() bool {
 
 var configsLock = sync.RWMutex{}
 var sourcesLock = sync.RWMutex{}
+var restoredCassettesLock = sync.Mutex{}
 
 var configs map[string]*transport_tpg.Config
 
 var sources map[string]VcrSource
 
+// restoredCassettes holds the temporary directories of the cassettes that
+// each test replays with the project number restored.
+var restoredCassettes = make(map[string][]string)
+
 // VcrSource is a source for a given VCR test with the value that seeded it
 type VcrSource struct {
 	seed   int64
@@ -175,11 +178,17 @@ func closeRecorder(t *testing.T) {
 		// We did not cache the config if it does not use VCR
 		if !t.Failed() && IsVcrEnabled() {
 			// If a test succeeds, write new seed/yaml to files
-			err := config.Client.Transport.(*recorder.Recorder).Stop()
+			err := config.Client.Transport.(interface{ Stop() error }).Stop()
 			if err != nil {
 				t.Error(err)
 			}
 			envPath := os.Getenv("VCR_PATH")
+			if os.Getenv("VCR_MODE") == "RECORDING" {
+				err = ScrubCassette(filepath.Join(envPath, vcrFileName(t.Name())), os.Getenv("GOOGLE_PROJECT_NUMBER"))
+				if err != nil {
+					t.Error(err)
+				}
+			}
 
 			sourcesLock.RLock()
 			vcrSource, ok := sources[t.Name()]
@@ -200,6 +209,16 @@ func closeRecorder(t *testing.T) {
 		delete(sources, t.Name())
 		sourcesLock.Unlock()
 	}
+
+	restoredCassettesLock.Lock()
+	dirs := restoredCassettes[t.Name()]
+	delete(restoredCassettes, t.Name())
+	restoredCassettesLock.Unlock()
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}
 }
 
 func isReleaseDiffEnabled() bool {
@@ -287,7 +306,9 @@ func reformConfigWithProvider(config, provider string) string {
 // This includes:
 //   - Setting the recording/replaying mode
 //   - Determining the path to the file API interactions will be recorded to/read from
+//   - Restoring the project number in cassettes before replaying them
 //   - Determining the logic used to match requests against recorded HTTP interactions (see rec.SetMatcher)
+//   - Reporting the closest recorded interaction when replaying fails, if VCR_MATCH_DEBUG is set
 func HandleVCRConfiguration(ctx context.Context, testName string, rndTripper http.RoundTripper, pollInterval time.Duration) (time.Duration, http.RoundTripper, fwDiags.Diagnostics) {
 	var diags fwDiags.Diagnostics
 	var vcrMode recorder.Mode
@@ -309,6 +330,22 @@ func HandleVCRConfiguration(ctx context.Context, testName string, rndTripper htt
 		return pollInterval, rndTripper, diags
 	}
 	path := filepath.Join(envPath, vcrFileName(testName))
+	// Saved cassettes have the project number replaced with a placeholder, see
+	// ScrubCassette. Replay from a copy with the project number restored.
+	if projectNumber := os.Getenv("GOOGLE_PROJECT_NUMBER"); vcrMode == recorder.ModeReplaying && projectNumber != "" {
+		restoredPath, err := restoreCassetteProjectNumber(path, projectNumber)
+		if err == nil {
+			if restoredPath != path {
+				restoredCassettesLock.Lock()
+				restoredCassettes[testName] = append(restoredCassettes[testName], filepath.Dir(restoredPath))
+				restoredCassettesLock.Unlock()
+			}
+			path = restoredPath
+		} else if !errors.Is(err, os.ErrNotExist) {
+			diags.AddError("error restoring the project number in the cassette", err.Error())
+			return pollInterval, rndTripper, diags
+		}
+	}
 
 	rec, err := recorder.NewAsMode(path, vcrMode, rndTripper)
 	if err != nil {
@@ -316,54 +353,34 @@ func HandleVCRConfiguration(ctx context.Context, testName string, rndTripper htt
 		return pollInterval, rndTripper, diags
 	}
 	// Defines how VCR will match requests to responses.
-	rec.SetMatcher(NewVcrMatcherFunc(ctx))
+	rules, err := vcrMatchRulesFromEnv()
+	if err != nil {
+		diags.AddError("error reading VCR match rules", err.Error())
+		return pollInterval, rndTripper, diags
+	}
+	matcher, err := newVcrMatcher(rules)
+	if err != nil {
+		diags.AddError("error compiling VCR match rules", err.Error())
+		return pollInterval, rndTripper, diags
+	}
+	rec.SetMatcher(func(r *http.Request, i cassette.Request) bool {
+		return matcher.match(ctx, r, i)
+	})
 
+	if vcrMode == recorder.ModeReplaying && vcrDebugEnabled() {
+		return pollInterval, &vcrDebugRecorder{Recorder: rec, cassettePath: path, matcher: matcher}, diags
+	}
 	return pollInterval, rec, diags
 }
 
-// NewVcrMatcherFunc returns a function used for matching HTTP requests with data recorded in VCR cassettes
+// NewVcrMatcherFunc returns a function used for matching HTTP requests with data recorded in VCR cassettes,
+// using DefaultVcrMatchRules
 func NewVcrMatcherFunc(ctx context.Context) func(r *http.Request, i cassette.Request) bool {
-	return func(r *http.Request, i cassette.Request) bool {
-		// Default matcher compares method and URL only
-		if !cassette.DefaultMatcher(r, i) {
-			return false
-		}
-		if r.Body == nil {
-			return true
-		}
-		contentType := r.Header.Get("Content-Type")
-		// If body contains media, don't try to compare
-		if strings.Contains(contentType, "multipart/related") {
-			return true
-		}
-
-		var b bytes.Buffer
-		if _, err := b.ReadFrom(r.Body); err != nil {
-			tflog.Debug(ctx, fmt.Sprintf("Failed to read request body from cassette: %v", err))
-			return false
-		}
-		r.Body = ioutil.NopCloser(&b)
-		reqBody := b.String()
-		// If body matches identically, we are done
-		if reqBody == i.Body {
-			return true
-		}
-
-		// JSON might be the same, but reordered. Try parsing json and comparing
-		if strings.Contains(contentType, "application/json") {
-			var reqJson, cassetteJson interface{}
-			if err := json.Unmarshal([]byte(reqBody), &reqJson); err != nil {
-				tflog.Debug(ctx, fmt.Sprintf("Failed to unmarshal request json: %v", err))
-				return false
-			}
-			if err := json.Unmarshal([]byte(i.Body), &cassetteJson); err != nil {
-				tflog.Debug(ctx, fmt.Sprintf("Failed to unmarshal cassette json: %v", err))
-				return false
-			}
-			return reflect.DeepEqual(reqJson, cassetteJson)
-		}
-		return false
+	matcher, err := NewVcrMatcherFuncWithRules(ctx, DefaultVcrMatchRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default VCR match rules: %s", err))
 	}
+	return matcher
 }
 
 // MuxedProviders configures the providers, thus, if we want the providers to be configured
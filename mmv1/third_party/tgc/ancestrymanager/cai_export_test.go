@@ -0,0 +1,127 @@
+package ancestrymanager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadCAIExport(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+		want   map[string]string
+	}{
+		{
+			name: "ndjson with ancestors",
+			export: `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"projectId":"test-proj","projectNumber":"123"}},"ancestors":["projects/123","folders/456","organizations/789"]}
+{"name":"//cloudresourcemanager.googleapis.com/folders/456","asset_type":"cloudresourcemanager.googleapis.com/Folder","ancestors":["folders/456","organizations/789"]}
+{"name":"//storage.googleapis.com/test-bucket","asset_type":"storage.googleapis.com/Bucket","ancestors":["projects/123","folders/456","organizations/789"]}
+`,
+			want: map[string]string{
+				"projects/123": "organizations/789/folders/456/projects/123",
+				"test-proj":    "organizations/789/folders/456/projects/123",
+				"folders/456":  "organizations/789/folders/456",
+			},
+		},
+		{
+			name: "json array with ancestors",
+			export: `[
+  {"name":"//cloudresourcemanager.googleapis.com/folders/456","asset_type":"cloudresourcemanager.googleapis.com/Folder","ancestors":["folders/456","organizations/789"]}
+]`,
+			want: map[string]string{
+				"folders/456": "organizations/789/folders/456",
+			},
+		},
+		{
+			name: "resolve hierarchy from parents",
+			export: `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"projectId":"test-proj","parent":{"type":"folder","id":"456"}}}}
+{"name":"//cloudresourcemanager.googleapis.com/folders/456","asset_type":"cloudresourcemanager.googleapis.com/Folder","resource":{"parent":"//cloudresourcemanager.googleapis.com/folders/321"}}
+{"name":"//cloudresourcemanager.googleapis.com/folders/321","asset_type":"cloudresourcemanager.googleapis.com/Folder","resource":{"data":{"parent":"organizations/789"}}}
+`,
+			want: map[string]string{
+				"projects/123": "organizations/789/folders/321/folders/456/projects/123",
+				"test-proj":    "organizations/789/folders/321/folders/456/projects/123",
+				"folders/456":  "organizations/789/folders/321/folders/456",
+				"folders/321":  "organizations/789/folders/321",
+			},
+		},
+		{
+			name: "parent mixed with ancestors",
+			export: `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"parent":{"type":"folder","id":"456"}}}}
+{"name":"//cloudresourcemanager.googleapis.com/folders/456","asset_type":"cloudresourcemanager.googleapis.com/Folder","ancestors":["folders/456","organizations/789"]}
+`,
+			want: map[string]string{
+				"projects/123": "organizations/789/folders/456/projects/123",
+				"folders/456":  "organizations/789/folders/456",
+			},
+		},
+		{
+			name:   "incomplete hierarchy",
+			export: `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"parent":{"type":"folder","id":"456"}}}}`,
+			want:   map[string]string{},
+		},
+		{
+			name:   "empty export",
+			export: "",
+			want:   map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadCAIExport(strings.NewReader(test.export))
+			if err != nil {
+				t.Fatalf("ReadCAIExport() = %s, want = nil", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ReadCAIExport() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadCAIExport_Fail(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+	}{
+		{
+			name:   "malformed json",
+			export: `{"name":`,
+		},
+		{
+			name:   "unexpected asset name",
+			export: `{"name":"projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ReadCAIExport(strings.NewReader(test.export)); err == nil {
+				t.Fatalf("ReadCAIExport(%s) = nil, want = err", test.export)
+			}
+		})
+	}
+}
+
+func TestReadCAIExport_InitAncestryCache(t *testing.T) {
+	export := `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"projectId":"test-proj"}},"ancestors":["projects/123","organizations/789"]}`
+	entries, err := ReadCAIExport(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("ReadCAIExport() = %s, want = nil", err)
+	}
+	m := &manager{
+		ancestorCache: make(map[string][]string),
+	}
+	if err := m.initAncestryCache(entries); err != nil {
+		t.Fatalf("initAncestryCache(%v) = %s, want = nil", entries, err)
+	}
+	want := map[string][]string{
+		"projects/test-proj": {"projects/123", "organizations/789"},
+		"projects/123":       {"projects/123", "organizations/789"},
+		"organizations/789":  {"organizations/789"},
+	}
+	if diff := cmp.Diff(want, m.ancestorCache); diff != "" {
+		t.Errorf("initAncestryCache(%v) returned unexpected diff (-want +got):\n%s", entries, diff)
+	}
+}
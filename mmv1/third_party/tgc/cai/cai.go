@@ -50,6 +50,9 @@ type AssetResource struct {
 	DiscoveryDocumentURI string `json:"discovery_document_uri"`
 	// Resource name.
 	DiscoveryName string `json:"discovery_name"`
+	// Full name of the parent resource, as recorded by CAI exports. Converters
+	// leave it empty; the parent is computed from the ancestry instead.
+	Parent string `json:"parent,omitempty"`
 	// Actual resource state as per Terraform.  Note that this does
 	// not necessarily correspond perfectly with the CAI representation
 	// as there are occasional deviations between CAI and API responses.
@@ -70,8 +73,9 @@ type IAMPolicy struct {
 }
 
 type IAMBinding struct {
-	Role    string   `json:"role"`
-	Members []string `json:"members"`
+	Role      string   `json:"role"`
+	Members   []string `json:"members"`
+	Condition *Expr    `json:"condition,omitempty"`
 }
 
 type OrgPolicy struct {
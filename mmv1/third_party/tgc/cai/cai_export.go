@@ -0,0 +1,40 @@
+package cai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ExportAsset is an entry of a Cloud Asset Inventory export.
+type ExportAsset struct {
+	Asset
+	Ancestors []string `json:"ancestors,omitempty"`
+}
+
+// ReadCAIExport reads a Cloud Asset Inventory export, either as a JSON array
+// or as newline-delimited JSON.
+func ReadCAIExport(r io.Reader) ([]ExportAsset, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading CAI export: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	var assets []ExportAsset
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &assets); err != nil {
+			return nil, fmt.Errorf("parsing CAI export: %w", err)
+		}
+		return assets, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var asset ExportAsset
+		if err := dec.Decode(&asset); err != nil {
+			return nil, fmt.Errorf("parsing CAI export: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
@@ -0,0 +1,68 @@
+package cai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadCAIExport(t *testing.T) {
+	want := []ExportAsset{
+		{
+			Asset: Asset{
+				Name: "//cloudresourcemanager.googleapis.com/projects/123",
+				Type: "cloudresourcemanager.googleapis.com/Project",
+				Resource: &AssetResource{
+					Parent: "//cloudresourcemanager.googleapis.com/folders/456",
+					Data:   map[string]interface{}{"projectId": "test-proj"},
+				},
+			},
+			Ancestors: []string{"projects/123", "folders/456"},
+		},
+		{
+			Asset: Asset{
+				Name: "//cloudresourcemanager.googleapis.com/projects/123",
+				Type: "cloudresourcemanager.googleapis.com/Project",
+				IAMPolicy: &IAMPolicy{
+					Bindings: []IAMBinding{{Role: "roles/viewer", Members: []string{"user:jane@example.com"}}},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		export string
+	}{
+		{
+			name: "ndjson",
+			export: `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"parent":"//cloudresourcemanager.googleapis.com/folders/456","data":{"projectId":"test-proj"}},"ancestors":["projects/123","folders/456"]}
+{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","iam_policy":{"bindings":[{"role":"roles/viewer","members":["user:jane@example.com"]}]}}
+`,
+		},
+		{
+			name: "json array",
+			export: `[
+  {"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"parent":"//cloudresourcemanager.googleapis.com/folders/456","data":{"projectId":"test-proj"}},"ancestors":["projects/123","folders/456"]},
+  {"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","iam_policy":{"bindings":[{"role":"roles/viewer","members":["user:jane@example.com"]}]}}
+]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadCAIExport(strings.NewReader(test.export))
+			if err != nil {
+				t.Fatalf("ReadCAIExport() = %s, want = nil", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("ReadCAIExport() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadCAIExport_Fail(t *testing.T) {
+	if _, err := ReadCAIExport(strings.NewReader(`{"name":`)); err == nil {
+		t.Fatal("ReadCAIExport() = nil, want = err")
+	}
+}
@@ -3,6 +3,7 @@ package cai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -28,8 +29,9 @@ func ExpandIamPolicyBindings(d tpgresource.TerraformResourceData) ([]IAMBinding,
 
 	for _, b := range policy.Bindings {
 		bindings = append(bindings, IAMBinding{
-			Role:    b.Role,
-			Members: b.Members,
+			Role:      b.Role,
+			Members:   b.Members,
+			Condition: expandIamPolicyCondition(b.Condition),
 		})
 	}
 
@@ -44,8 +46,9 @@ func ExpandIamRoleBindings(d tpgresource.TerraformResourceData) ([]IAMBinding, e
 	}
 	return []IAMBinding{
 		{
-			Role:    d.Get("role").(string),
-			Members: members,
+			Role:      d.Get("role").(string),
+			Members:   members,
+			Condition: expandIamCondition(d),
 		},
 	}, nil
 }
@@ -54,12 +57,59 @@ func ExpandIamRoleBindings(d tpgresource.TerraformResourceData) ([]IAMBinding, e
 func ExpandIamMemberBindings(d tpgresource.TerraformResourceData) ([]IAMBinding, error) {
 	return []IAMBinding{
 		{
-			Role:    d.Get("role").(string),
-			Members: []string{d.Get("member").(string)},
+			Role:      d.Get("role").(string),
+			Members:   []string{d.Get("member").(string)},
+			Condition: expandIamCondition(d),
 		},
 	}, nil
 }
 
+// expandIamCondition reads the optional condition block of
+// google_<type>_iam_member and google_<type>_iam_binding resources.
+func expandIamCondition(d tpgresource.TerraformResourceData) *Expr {
+	v, ok := d.GetOk("condition")
+	if !ok {
+		return nil
+	}
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 || l[0] == nil {
+		return nil
+	}
+	raw := l[0].(map[string]interface{})
+	condition := &Expr{}
+	if expression, ok := raw["expression"].(string); ok {
+		condition.Expression = expression
+	}
+	if title, ok := raw["title"].(string); ok {
+		condition.Title = title
+	}
+	if description, ok := raw["description"].(string); ok {
+		condition.Description = description
+	}
+	return condition
+}
+
+func expandIamPolicyCondition(c *cloudresourcemanager.Expr) *Expr {
+	if c == nil {
+		return nil
+	}
+	return &Expr{
+		Expression:  c.Expression,
+		Title:       c.Title,
+		Description: c.Description,
+		Location:    c.Location,
+	}
+}
+
+// bindingKey identifies a binding within a policy. Bindings with the same
+// role but different conditions are distinct.
+func bindingKey(b IAMBinding) string {
+	if b.Condition == nil {
+		return b.Role
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", b.Role, b.Condition.Title, b.Condition.Description, b.Condition.Expression)
+}
+
 // MergeIamAssets merges an existing asset with the IAM bindings of an incoming
 // Asset.
 func MergeIamAssets(
@@ -90,11 +140,11 @@ func MergeDeleteIamAssets(
 func MergeAdditiveBindings(existing, incoming []IAMBinding) []IAMBinding {
 	existingIdxs := make(map[string]int)
 	for i, binding := range existing {
-		existingIdxs[binding.Role] = i
+		existingIdxs[bindingKey(binding)] = i
 	}
 
 	for _, binding := range incoming {
-		if ei, ok := existingIdxs[binding.Role]; ok {
+		if ei, ok := existingIdxs[bindingKey(binding)]; ok {
 			memberExists := make(map[string]bool)
 			for _, m := range existing[ei].Members {
 				memberExists[m] = true
@@ -124,7 +174,7 @@ func MergeDeleteAdditiveBindings(existing, incoming []IAMBinding) []IAMBinding {
 	toDelete := make(map[string]struct{})
 	for _, binding := range incoming {
 		for _, m := range binding.Members {
-			key := binding.Role + "-" + m
+			key := bindingKey(binding) + "-" + m
 			toDelete[key] = struct{}{}
 		}
 	}
@@ -133,7 +183,7 @@ func MergeDeleteAdditiveBindings(existing, incoming []IAMBinding) []IAMBinding {
 	for _, binding := range existing {
 		var newMembers []string
 		for _, m := range binding.Members {
-			key := binding.Role + "-" + m
+			key := bindingKey(binding) + "-" + m
 			_, delete := toDelete[key]
 			if !delete {
 				newMembers = append(newMembers, m)
@@ -141,8 +191,9 @@ func MergeDeleteAdditiveBindings(existing, incoming []IAMBinding) []IAMBinding {
 		}
 		if newMembers != nil {
 			newExisting = append(newExisting, IAMBinding{
-				Role:    binding.Role,
-				Members: newMembers,
+				Role:      binding.Role,
+				Members:   newMembers,
+				Condition: binding.Condition,
 			})
 		}
 	}
@@ -155,11 +206,11 @@ func MergeDeleteAdditiveBindings(existing, incoming []IAMBinding) []IAMBinding {
 func MergeAuthoritativeBindings(existing, incoming []IAMBinding) []IAMBinding {
 	existingIdxs := make(map[string]int)
 	for i, binding := range existing {
-		existingIdxs[binding.Role] = i
+		existingIdxs[bindingKey(binding)] = i
 	}
 
 	for _, binding := range incoming {
-		if ei, ok := existingIdxs[binding.Role]; ok {
+		if ei, ok := existingIdxs[bindingKey(binding)]; ok {
 			existing[ei].Members = binding.Members
 		} else {
 			existing = append(existing, binding)
@@ -180,13 +231,13 @@ func MergeAuthoritativeBindings(existing, incoming []IAMBinding) []IAMBinding {
 func MergeDeleteAuthoritativeBindings(existing, incoming []IAMBinding) []IAMBinding {
 	toDelete := make(map[string]struct{})
 	for _, binding := range incoming {
-		key := binding.Role
+		key := bindingKey(binding)
 		toDelete[key] = struct{}{}
 	}
 
 	var newExisting []IAMBinding
 	for _, binding := range existing {
-		key := binding.Role
+		key := bindingKey(binding)
 		_, delete := toDelete[key]
 		if !delete {
 			newExisting = append(newExisting, binding)
@@ -222,8 +273,9 @@ func FetchIamPolicy(
 		bindings = append(
 			bindings,
 			IAMBinding{
-				Role:    b.Role,
-				Members: b.Members,
+				Role:      b.Role,
+				Members:   b.Members,
+				Condition: expandIamPolicyCondition(b.Condition),
 			},
 		)
 	}
@@ -238,3 +290,56 @@ func FetchIamPolicy(
 		},
 	}, nil
 }
+
+// ReadIamPolicySnapshot reads existing IAM policies from a Cloud Asset
+// Inventory export, either as a JSON array or as newline-delimited JSON, and
+// returns the assets that have an iam_policy. Other assets are ignored, except
+// that their resource data is attached to the policy of the same asset, so
+// projects named by number in the export can be matched by project id.
+func ReadIamPolicySnapshot(r io.Reader) ([]Asset, error) {
+	assets, err := ReadCAIExport(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading IAM policy snapshot: %w", err)
+	}
+
+	resources := make(map[string]*AssetResource)
+	for _, asset := range assets {
+		if asset.Resource != nil {
+			resources[asset.Type+asset.Name] = asset.Resource
+		}
+	}
+	var policies []Asset
+	for _, asset := range assets {
+		if asset.IAMPolicy == nil {
+			continue
+		}
+		policy := asset.Asset
+		if policy.Resource == nil {
+			policy.Resource = resources[asset.Type+asset.Name]
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// CopyIamAsset returns a copy of the asset whose IAM policy can be merged
+// into without modifying the original.
+func CopyIamAsset(a Asset) Asset {
+	if a.IAMPolicy == nil {
+		return a
+	}
+	policy := &IAMPolicy{}
+	for _, b := range a.IAMPolicy.Bindings {
+		binding := IAMBinding{
+			Role:    b.Role,
+			Members: append([]string(nil), b.Members...),
+		}
+		if b.Condition != nil {
+			condition := *b.Condition
+			binding.Condition = &condition
+		}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+	a.IAMPolicy = policy
+	return a
+}
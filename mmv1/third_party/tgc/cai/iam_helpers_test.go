@@ -1,6 +1,7 @@
 package cai
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -298,3 +299,140 @@ func TestMergeDeleteBindings(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeConditionalBindings(t *testing.T) {
+	condition := &Expr{
+		Title:      "expires",
+		Expression: "request.time < timestamp(\"2030-01-01T00:00:00Z\")",
+	}
+	existing := func() []IAMBinding {
+		return []IAMBinding{
+			{
+				Role:    "role-a",
+				Members: []string{"member-a"},
+			},
+			{
+				Role:      "role-a",
+				Members:   []string{"member-b"},
+				Condition: condition,
+			},
+		}
+	}
+	incoming := []IAMBinding{
+		{
+			Role:      "role-a",
+			Members:   []string{"member-c"},
+			Condition: condition,
+		},
+	}
+
+	assert.EqualValues(t,
+		[]IAMBinding{
+			{
+				Role:    "role-a",
+				Members: []string{"member-a"},
+			},
+			{
+				Role:      "role-a",
+				Members:   []string{"member-b", "member-c"},
+				Condition: condition,
+			},
+		},
+		MergeAdditiveBindings(existing(), incoming),
+	)
+	assert.EqualValues(t,
+		[]IAMBinding{
+			{
+				Role:    "role-a",
+				Members: []string{"member-a"},
+			},
+			{
+				Role:      "role-a",
+				Members:   []string{"member-c"},
+				Condition: condition,
+			},
+		},
+		MergeAuthoritativeBindings(existing(), incoming),
+	)
+	assert.EqualValues(t,
+		[]IAMBinding{
+			{
+				Role:    "role-a",
+				Members: []string{"member-a"},
+			},
+			{
+				Role:      "role-a",
+				Members:   []string{"member-b"},
+				Condition: condition,
+			},
+		},
+		MergeDeleteAdditiveBindings(existing(), incoming),
+	)
+	assert.EqualValues(t,
+		[]IAMBinding{
+			{
+				Role:    "role-a",
+				Members: []string{"member-a"},
+			},
+		},
+		MergeDeleteAuthoritativeBindings(existing(), incoming),
+	)
+}
+
+func TestReadIamPolicySnapshot(t *testing.T) {
+	snapshot := `{"name":"//cloudresourcemanager.googleapis.com/projects/my-project","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"version":"v1","data":{"projectId":"my-project"}}}
+{"name":"//cloudresourcemanager.googleapis.com/projects/my-project","asset_type":"cloudresourcemanager.googleapis.com/Project","iam_policy":{"bindings":[{"role":"roles/viewer","members":["user:jane@example.com"],"condition":{"title":"expires","expression":"request.time < timestamp(\"2030-01-01T00:00:00Z\")"}}]}}
+`
+	assets, err := ReadIamPolicySnapshot(strings.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("ReadIamPolicySnapshot() = %s, want = nil", err)
+	}
+	assert.EqualValues(t,
+		[]Asset{
+			{
+				Name: "//cloudresourcemanager.googleapis.com/projects/my-project",
+				Type: "cloudresourcemanager.googleapis.com/Project",
+				Resource: &AssetResource{
+					Version: "v1",
+					Data:    map[string]interface{}{"projectId": "my-project"},
+				},
+				IAMPolicy: &IAMPolicy{
+					Bindings: []IAMBinding{
+						{
+							Role:    "roles/viewer",
+							Members: []string{"user:jane@example.com"},
+							Condition: &Expr{
+								Title:      "expires",
+								Expression: "request.time < timestamp(\"2030-01-01T00:00:00Z\")",
+							},
+						},
+					},
+				},
+			},
+		},
+		assets,
+	)
+}
+
+func TestCopyIamAsset(t *testing.T) {
+	original := Asset{
+		Name: "//cloudresourcemanager.googleapis.com/projects/my-project",
+		IAMPolicy: &IAMPolicy{
+			Bindings: []IAMBinding{
+				{
+					Role:    "role-a",
+					Members: []string{"member-a"},
+				},
+			},
+		},
+	}
+	copied := CopyIamAsset(original)
+	copied.IAMPolicy.Bindings = MergeAdditiveBindings(copied.IAMPolicy.Bindings, []IAMBinding{
+		{
+			Role:    "role-a",
+			Members: []string{"member-b"},
+		},
+	})
+	assert.EqualValues(t, []string{"member-a"}, original.IAMPolicy.Bindings[0].Members)
+	assert.EqualValues(t, []string{"member-a", "member-b"}, copied.IAMPolicy.Bindings[0].Members)
+}
@@ -17,6 +17,7 @@ package google
 import (
 	errorssyslib "errors"
 	"fmt"
+	"io"
 	"runtime/debug"
 	"sort"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/caiasset"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/tfplan2cai/ancestrymanager"
 	resources "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/tfplan2cai/converters/google/resources"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/tfplan2cai/converters/google/resources/cai"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/tfplan2cai/tfdata"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/tfplan2cai/tfplan"
 	"github.com/hashicorp/terraform-provider-google-beta/google-beta/tpgresource"
@@ -93,6 +95,64 @@ type Converter struct {
 
 	// For logging error / status information that doesn't warrant an outright failure
 	errorLogger *zap.Logger
+
+	// Existing IAM policies (key = asset.Type + asset.Name) that IAM changes
+	// are merged with instead of fetching the policies from the API.
+	iamPolicySnapshot map[string]resources.Asset
+}
+
+// SetIamPolicySnapshot provides the existing IAM policies, as CAI assets with an
+// iam_policy, to merge google_*_iam_member/binding/policy changes with. Snapshot
+// policies take precedence over fetching from the API, so the post-apply policy
+// can be computed offline. CAI exports name projects by number, so project
+// policies that carry the project resource data (see cai.ReadIamPolicySnapshot)
+// are also matched by project id, and the other way around.
+//
+// This is a library API for callers that build their own Converter: the
+// tfplan2cai entrypoint and CLI in terraform-google-conversion don't take a
+// snapshot yet.
+func (c *Converter) SetIamPolicySnapshot(assets []resources.Asset) {
+	const (
+		projectAssetType       = "cloudresourcemanager.googleapis.com/Project"
+		projectAssetNamePrefix = "//cloudresourcemanager.googleapis.com/projects/"
+	)
+	c.iamPolicySnapshot = make(map[string]resources.Asset, len(assets))
+	for _, a := range assets {
+		policy := resources.Asset{
+			Name:      a.Name,
+			Type:      a.Type,
+			IAMPolicy: a.IAMPolicy,
+		}
+		c.iamPolicySnapshot[a.Type+a.Name] = policy
+		if a.Type != projectAssetType || a.Resource == nil {
+			continue
+		}
+		for _, field := range []string{"projectId", "projectNumber"} {
+			if v, ok := a.Resource.Data[field].(string); ok && v != "" {
+				c.iamPolicySnapshot[a.Type+projectAssetNamePrefix+v] = policy
+			}
+		}
+	}
+}
+
+// SetAncestryExport builds the ancestry cache from a Cloud Asset Inventory
+// resource export (see ancestrymanager.ReadCAIExport), so ancestry can be
+// resolved without calling Resource Manager. It replaces the converter's
+// ancestry manager; entries take precedence over the export.
+func (c *Converter) SetAncestryExport(export io.Reader, entries map[string]string) error {
+	cache, err := ancestrymanager.ReadCAIExport(export)
+	if err != nil {
+		return fmt.Errorf("reading ancestry export: %w", err)
+	}
+	for k, v := range entries {
+		cache[k] = v
+	}
+	am, err := ancestrymanager.New(c.cfg, c.offline, cache, c.errorLogger)
+	if err != nil {
+		return fmt.Errorf("building ancestry manager: %w", err)
+	}
+	c.ancestryManager = am
+	return nil
 }
 
 // AddResourceChange processes the resource changes in two stages:
@@ -173,22 +233,9 @@ func (c *Converter) addDelete(rc *tfjson.ResourceChange) error {
 
 		for _, converted := range convertedItems {
 			key := converted.Type + converted.Name
-			var existingConverterAsset *resources.Asset
-			if existing, exists := c.assets[key]; exists {
-				existingConverterAsset = &existing.converterAsset
-			} else if !c.offline {
-				asset, err := converter.FetchFullResource(rd, c.cfg)
-				if errors.Cause(err) == resources.ErrEmptyIdentityField {
-					c.errorLogger.Debug(fmt.Sprintf("%s: Unable to fetch and merge remote %s asset due to unset or (known after apply) identity fields on the TF resource.", rc.Address, converted.Type))
-					existingConverterAsset = nil
-				} else if errors.Cause(err) == resources.ErrResourceInaccessible {
-					c.errorLogger.Warn(fmt.Sprintf("%s: Fetching %s for merge failed due to not existing or insufficient permission.", rc.Address, key))
-					existingConverterAsset = nil
-				} else if err != nil {
-					return fmt.Errorf("fetching remote asset %s: %w", key, err)
-				} else {
-					existingConverterAsset = &asset
-				}
+			existingConverterAsset, err := c.existingAsset(rc, rd, converter, converted)
+			if err != nil {
+				return err
 			}
 			if existingConverterAsset != nil {
 				converted = converter.MergeDelete(*existingConverterAsset, converted)
@@ -226,23 +273,9 @@ func (c *Converter) addCreateOrUpdateOrNoop(rc *tfjson.ResourceChange) error {
 
 		for _, converted := range convertedAssets {
 			key := converted.Type + converted.Name
-
-			var existingConverterAsset *resources.Asset
-			if existing, exists := c.assets[key]; exists {
-				existingConverterAsset = &existing.converterAsset
-			} else if converter.FetchFullResource != nil && !c.offline {
-				asset, err := converter.FetchFullResource(rd, c.cfg)
-				if errors.Cause(err) == resources.ErrEmptyIdentityField {
-					c.errorLogger.Debug(fmt.Sprintf("%s: Unable to fetch and merge remote %s asset due to unset or (known after apply) identity fields on the TF resource.", rc.Address, converted.Type))
-					existingConverterAsset = nil
-				} else if errors.Cause(err) == resources.ErrResourceInaccessible {
-					c.errorLogger.Warn(fmt.Sprintf("%s: Fetching %s for merge failed due to not existing or insufficient permission.", rc.Address, key))
-					existingConverterAsset = nil
-				} else if err != nil {
-					return fmt.Errorf("fetching remote asset %s: %w", key, err)
-				} else {
-					existingConverterAsset = &asset
-				}
+			existingConverterAsset, err := c.existingAsset(rc, rd, converter, converted)
+			if err != nil {
+				return err
 			}
 
 			if existingConverterAsset != nil {
@@ -265,6 +298,39 @@ func (c *Converter) addCreateOrUpdateOrNoop(rc *tfjson.ResourceChange) error {
 	return nil
 }
 
+// existingAsset returns the asset that the converted asset is merged into, if
+// any: an asset already converted from the plan, otherwise, for converters that
+// can fetch the full resource, the IAM policy snapshot or the API.
+func (c *Converter) existingAsset(rc *tfjson.ResourceChange, rd tpgresource.TerraformResourceData, converter resources.ResourceConverter, converted resources.Asset) (*resources.Asset, error) {
+	key := converted.Type + converted.Name
+	if existing, exists := c.assets[key]; exists {
+		return &existing.converterAsset, nil
+	}
+	if converter.FetchFullResource == nil {
+		return nil, nil
+	}
+	if snapshot, ok := c.iamPolicySnapshot[key]; ok {
+		asset := cai.CopyIamAsset(snapshot)
+		// The snapshot may name the resource differently, e.g. by project number.
+		asset.Name = converted.Name
+		return &asset, nil
+	}
+	if c.offline {
+		return nil, nil
+	}
+	asset, err := converter.FetchFullResource(rd, c.cfg)
+	if errors.Cause(err) == resources.ErrEmptyIdentityField {
+		c.errorLogger.Debug(fmt.Sprintf("%s: Unable to fetch and merge remote %s asset due to unset or (known after apply) identity fields on the TF resource.", rc.Address, converted.Type))
+		return nil, nil
+	} else if errors.Cause(err) == resources.ErrResourceInaccessible {
+		c.errorLogger.Warn(fmt.Sprintf("%s: Fetching %s for merge failed due to not existing or insufficient permission.", rc.Address, key))
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("fetching remote asset %s: %w", key, err)
+	}
+	return &asset, nil
+}
+
 type byName []caiasset.Asset
 
 func (s byName) Len() int           { return len(s) }
@@ -289,6 +355,19 @@ func (c *Converter) Assets() []caiasset.Asset {
 	return list
 }
 
+// EffectiveIamPolicies lists the post-apply IAM policies of all converted assets
+// that have one. Unlike Assets, the bindings keep their IAM conditions.
+func (c *Converter) EffectiveIamPolicies() []resources.Asset {
+	var list []resources.Asset
+	for _, a := range c.assets {
+		if a.converterAsset.IAMPolicy != nil {
+			list = append(list, a.converterAsset)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
 // augmentAsset adds data to an asset that is not set by the conversion library.
 func (c *Converter) augmentAsset(tfData tpgresource.TerraformResourceData, cfg *transport_tpg.Config, cai resources.Asset) (Asset, error) {
 	ancestors, parent, err := c.ancestryManager.Ancestors(cfg, tfData, &cai)
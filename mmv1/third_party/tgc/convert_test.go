@@ -781,3 +781,285 @@ func TestConvertMergingWithExistAsset(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertMergingWithIamPolicySnapshot(t *testing.T) {
+	condition := &cai.Expr{
+		Title:      "expires",
+		Expression: "request.time < timestamp(\"2030-01-01T00:00:00Z\")",
+	}
+	tests := []struct {
+		name    string
+		changes []*tfjson.ResourceChange
+		want    []cai.Asset
+	}{
+		{
+			name: "CreateMember",
+			changes: []*tfjson.ResourceChange{
+				{
+					Address:      "google_project_iam_member.test",
+					Mode:         "managed",
+					Type:         "google_project_iam_member",
+					Name:         "test",
+					ProviderName: "registry.terraform.io/hashicorp/google-beta",
+					Change: &tfjson.Change{
+						Actions: tfjson.Actions{"create"},
+						Before:  nil,
+						After: map[string]interface{}{
+							"member":  "user:jane@example.com",
+							"project": "example-project",
+							"role":    "roles/editor",
+						},
+					},
+				},
+			},
+			want: []cai.Asset{
+				{
+					Name: "//cloudresourcemanager.googleapis.com/projects/example-project",
+					Type: "cloudresourcemanager.googleapis.com/Project",
+					IAMPolicy: &cai.IAMPolicy{
+						Bindings: []cai.IAMBinding{
+							{
+								Role:    "roles/editor",
+								Members: []string{"user:abc@example.com", "user:jane@example.com"},
+							},
+							{
+								Role:      "roles/editor",
+								Members:   []string{"user:def@example.com"},
+								Condition: condition,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "DeleteConditionalMember",
+			changes: []*tfjson.ResourceChange{
+				{
+					Address:      "google_project_iam_member.test",
+					Mode:         "managed",
+					Type:         "google_project_iam_member",
+					Name:         "test",
+					ProviderName: "registry.terraform.io/hashicorp/google-beta",
+					Change: &tfjson.Change{
+						Actions: tfjson.Actions{"delete"},
+						After:   nil,
+						Before: map[string]interface{}{
+							"member":  "user:def@example.com",
+							"project": "example-project",
+							"role":    "roles/editor",
+							"condition": []interface{}{
+								map[string]interface{}{
+									"title":      condition.Title,
+									"expression": condition.Expression,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: []cai.Asset{
+				{
+					Name: "//cloudresourcemanager.googleapis.com/projects/example-project",
+					Type: "cloudresourcemanager.googleapis.com/Project",
+					IAMPolicy: &cai.IAMPolicy{
+						Bindings: []cai.IAMBinding{
+							{
+								Role:    "roles/editor",
+								Members: []string{"user:abc@example.com"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter, _, err := newTestConverter(false)
+			assert.Nil(t, err)
+
+			fetchFuncCalled := 0
+			fetchFunc := func(d tpgresource.TerraformResourceData, config *transport_tpg.Config) (cai.Asset, error) {
+				fetchFuncCalled++
+				return resourcemanager.FetchProjectIamPolicy(d, config)
+			}
+
+			converter.converters["google_project_iam_member"] = []cai.ResourceConverter{
+				{
+					AssetType:         "cloudresourcemanager.googleapis.com/Project",
+					Convert:           resourcemanager.GetProjectIamMemberCaiObject,
+					FetchFullResource: fetchFunc,
+					MergeCreateUpdate: resourcemanager.MergeProjectIamMember,
+					MergeDelete:       resourcemanager.MergeProjectIamMemberDelete,
+				},
+			}
+
+			snapshot := []cai.Asset{
+				{
+					Name: "//cloudresourcemanager.googleapis.com/projects/example-project",
+					Type: "cloudresourcemanager.googleapis.com/Project",
+					IAMPolicy: &cai.IAMPolicy{
+						Bindings: []cai.IAMBinding{
+							{
+								Role:    "roles/editor",
+								Members: []string{"user:abc@example.com"},
+							},
+							{
+								Role:      "roles/editor",
+								Members:   []string{"user:def@example.com"},
+								Condition: condition,
+							},
+						},
+					},
+				},
+			}
+			converter.SetIamPolicySnapshot(snapshot)
+
+			err = converter.AddResourceChanges(test.changes)
+			if err != nil {
+				t.Fatalf("AddResourceChanges() = %s, want = nil", err)
+			}
+			if diff := cmp.Diff(test.want, converter.EffectiveIamPolicies()); diff != "" {
+				t.Errorf("EffectiveIamPolicies() returned unexpected diff (-want +got):\n%s", diff)
+			}
+			assert.Equal(t, 0, fetchFuncCalled)
+			// The snapshot itself is left unchanged.
+			assert.Equal(t, 2, len(snapshot[0].IAMPolicy.Bindings))
+		})
+	}
+}
+
+func TestConvertMergingWithIamPolicySnapshot_projectNumber(t *testing.T) {
+	// CAI exports name projects by number; the project id comes from the
+	// project resource in the same export.
+	export := `{"name":"//cloudresourcemanager.googleapis.com/projects/123456789","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"version":"v1","data":{"projectId":"example-project","projectNumber":"123456789"}}}
+{"name":"//cloudresourcemanager.googleapis.com/projects/123456789","asset_type":"cloudresourcemanager.googleapis.com/Project","iam_policy":{"bindings":[{"role":"roles/editor","members":["user:abc@example.com","user:def@example.com"]}]}}
+`
+	tests := []struct {
+		name    string
+		actions tfjson.Actions
+		member  string
+		members []string
+	}{
+		{
+			name:    "CreateMember",
+			actions: tfjson.Actions{"create"},
+			member:  "user:jane@example.com",
+			members: []string{"user:abc@example.com", "user:def@example.com", "user:jane@example.com"},
+		},
+		{
+			name:    "DeleteMember",
+			actions: tfjson.Actions{"delete"},
+			member:  "user:def@example.com",
+			members: []string{"user:abc@example.com"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter, _, err := newTestConverter(false)
+			assert.Nil(t, err)
+			converter.converters["google_project_iam_member"] = []cai.ResourceConverter{
+				{
+					AssetType:         "cloudresourcemanager.googleapis.com/Project",
+					Convert:           resourcemanager.GetProjectIamMemberCaiObject,
+					FetchFullResource: resourcemanager.FetchProjectIamPolicy,
+					MergeCreateUpdate: resourcemanager.MergeProjectIamMember,
+					MergeDelete:       resourcemanager.MergeProjectIamMemberDelete,
+				},
+			}
+
+			snapshot, err := cai.ReadIamPolicySnapshot(strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ReadIamPolicySnapshot() = %s, want = nil", err)
+			}
+			converter.SetIamPolicySnapshot(snapshot)
+
+			values := map[string]interface{}{
+				"member":  test.member,
+				"project": "example-project",
+				"role":    "roles/editor",
+			}
+			change := &tfjson.Change{Actions: test.actions, After: values}
+			if test.actions.Delete() {
+				change = &tfjson.Change{Actions: test.actions, Before: values}
+			}
+			err = converter.AddResourceChanges([]*tfjson.ResourceChange{
+				{
+					Address:      "google_project_iam_member.test",
+					Mode:         "managed",
+					Type:         "google_project_iam_member",
+					Name:         "test",
+					ProviderName: "registry.terraform.io/hashicorp/google",
+					Change:       change,
+				},
+			})
+			if err != nil {
+				t.Fatalf("AddResourceChanges() = %s, want = nil", err)
+			}
+
+			want := []cai.Asset{
+				{
+					Name: "//cloudresourcemanager.googleapis.com/projects/example-project",
+					Type: "cloudresourcemanager.googleapis.com/Project",
+					IAMPolicy: &cai.IAMPolicy{
+						Bindings: []cai.IAMBinding{
+							{
+								Role:    "roles/editor",
+								Members: test.members,
+							},
+						},
+					},
+				},
+			}
+			if diff := cmp.Diff(want, converter.EffectiveIamPolicies()); diff != "" {
+				t.Errorf("EffectiveIamPolicies() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetAncestryExport(t *testing.T) {
+	c, _, err := newTestConverter(false)
+	assert.Nil(t, err)
+
+	export := `{"name":"//cloudresourcemanager.googleapis.com/projects/123","asset_type":"cloudresourcemanager.googleapis.com/Project","resource":{"data":{"projectId":"test-proj"}},"ancestors":["projects/123","folders/456","organizations/789"]}`
+	if err := c.SetAncestryExport(strings.NewReader(export), nil); err != nil {
+		t.Fatalf("SetAncestryExport() = %s, want = nil", err)
+	}
+
+	rc := tfjson.ResourceChange{
+		Address:      "google_project.test",
+		Mode:         "managed",
+		Type:         "google_project",
+		Name:         "test",
+		ProviderName: "registry.terraform.io/hashicorp/google",
+		Change: &tfjson.Change{
+			Actions: tfjson.Actions{"create"},
+			Before:  nil,
+			After: map[string]interface{}{
+				"name":       "test",
+				"project_id": "test-proj",
+			},
+		},
+	}
+	err = c.AddResourceChanges([]*tfjson.ResourceChange{&rc})
+	assert.Nil(t, err)
+
+	var found bool
+	for _, a := range c.Assets() {
+		if a.Type != "cloudresourcemanager.googleapis.com/Project" {
+			continue
+		}
+		found = true
+		assert.Equal(t, []string{"projects/123", "folders/456", "organizations/789"}, a.Ancestors)
+		assert.Equal(t, "//cloudresourcemanager.googleapis.com/folders/456", a.Resource.Parent)
+	}
+	assert.True(t, found, "no project asset converted")
+}
+
+func TestSetAncestryExport_Fail(t *testing.T) {
+	c, _, err := newTestConverter(false)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.SetAncestryExport(strings.NewReader(`{"name":`), nil))
+}
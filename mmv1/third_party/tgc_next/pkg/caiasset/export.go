@@ -0,0 +1,203 @@
+package caiasset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportAsset is an asset in the Cloud Asset Inventory export format, as
+// written by `gcloud asset export` with newline-delimited JSON output.
+type ExportAsset struct {
+	Name          string           `json:"name"`
+	Type          string           `json:"asset_type"`
+	Resource      *AssetResource   `json:"resource,omitempty"`
+	IAMPolicy     *IAMPolicy       `json:"iam_policy,omitempty"`
+	OrgPolicy     []*OrgPolicy     `json:"org_policy,omitempty"`
+	V2OrgPolicies []*V2OrgPolicies `json:"v2_org_policies,omitempty"`
+	Ancestors     []string         `json:"ancestors"`
+	UpdateTime    *Timestamp       `json:"update_time,omitempty"`
+}
+
+// PolicyLibraryAsset is the asset shape evaluated by Policy Library
+// (config-validator) constraints.
+type PolicyLibraryAsset struct {
+	Name          string           `json:"name"`
+	Type          string           `json:"asset_type"`
+	AncestryPath  string           `json:"ancestry_path"`
+	Resource      *AssetResource   `json:"resource,omitempty"`
+	IAMPolicy     *IAMPolicy       `json:"iam_policy,omitempty"`
+	OrgPolicy     []*OrgPolicy     `json:"org_policy,omitempty"`
+	V2OrgPolicies []*V2OrgPolicies `json:"v2_org_policies,omitempty"`
+	Ancestors     []string         `json:"ancestors"`
+}
+
+// PolicyLibraryInput is the OPA input document for evaluating a single asset.
+type PolicyLibraryInput struct {
+	Asset PolicyLibraryAsset `json:"asset"`
+}
+
+// ToExportAsset converts the asset to the CAI export format. A zero
+// updateTime leaves update_time unset.
+func ToExportAsset(a Asset, updateTime time.Time) ExportAsset {
+	e := ExportAsset{
+		Name:          a.Name,
+		Type:          a.Type,
+		Resource:      a.Resource,
+		IAMPolicy:     a.IAMPolicy,
+		OrgPolicy:     a.OrgPolicy,
+		V2OrgPolicies: a.V2OrgPolicies,
+		Ancestors:     a.Ancestors,
+	}
+	if !updateTime.IsZero() {
+		e.UpdateTime = &Timestamp{
+			Seconds: updateTime.Unix(),
+			Nanos:   updateTime.UnixNano(),
+		}
+	}
+	return e
+}
+
+// ToPolicyLibraryInput converts the asset to the Policy Library input format.
+func ToPolicyLibraryInput(a Asset) PolicyLibraryInput {
+	return PolicyLibraryInput{
+		Asset: PolicyLibraryAsset{
+			Name:          a.Name,
+			Type:          a.Type,
+			AncestryPath:  AncestryPath(a.Ancestors),
+			Resource:      a.Resource,
+			IAMPolicy:     a.IAMPolicy,
+			OrgPolicy:     a.OrgPolicy,
+			V2OrgPolicies: a.V2OrgPolicies,
+			Ancestors:     a.Ancestors,
+		},
+	}
+}
+
+// AncestryPath composes the ancestry path used by Policy Library from
+// ancestors sorted from closest to furthest
+// (i.e. "organizations/123/folders/456/projects/789" becomes
+// "organization/123/folder/456/project/789").
+func AncestryPath(ancestors []string) string {
+	var path []string
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		path = append(path, ancestors[i])
+	}
+	ret := strings.Join(path, "/")
+	for _, r := range []struct {
+		old string
+		new string
+	}{
+		{"organizations/", "organization/"},
+		{"folders/", "folder/"},
+		{"projects/", "project/"},
+	} {
+		ret = strings.ReplaceAll(ret, r.old, r.new)
+	}
+	return ret
+}
+
+// WriteCAIExport writes the assets as CAI export newline-delimited JSON.
+func WriteCAIExport(w io.Writer, assets []Asset, updateTime time.Time) error {
+	enc := json.NewEncoder(w)
+	for _, a := range assets {
+		if err := enc.Encode(ToExportAsset(a, updateTime)); err != nil {
+			return fmt.Errorf("writing asset %s: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// WritePolicyLibraryInput writes the assets as a JSON array of Policy
+// Library inputs.
+func WritePolicyLibraryInput(w io.Writer, assets []Asset) error {
+	inputs := make([]PolicyLibraryInput, 0, len(assets))
+	for _, a := range assets {
+		inputs = append(inputs, ToPolicyLibraryInput(a))
+	}
+	data, err := json.MarshalIndent(inputs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling policy library input: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadCAIExport reads a CAI export into assets, either as newline-delimited
+// JSON or as a JSON array. Blank lines are skipped and update_time is dropped.
+func ReadCAIExport(r io.Reader) ([]Asset, error) {
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CAI export: %w", err)
+	}
+	if first == '[' {
+		var entries []ExportAsset
+		if err := json.NewDecoder(br).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("parsing CAI export: %w", err)
+		}
+		assets := make([]Asset, 0, len(entries))
+		for _, e := range entries {
+			assets = append(assets, e.toAsset())
+		}
+		return assets, nil
+	}
+
+	var assets []Asset
+	scanner := bufio.NewScanner(br)
+	// Resource data of a single asset can be much larger than the default token size.
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var e ExportAsset
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("parsing CAI export line %d: %w", line, err)
+		}
+		assets = append(assets, e.toAsset())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading CAI export: %w", err)
+	}
+	return assets, nil
+}
+
+// firstNonSpace peeks at the first byte that isn't whitespace, consuming the
+// whitespace before it.
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+func (e ExportAsset) toAsset() Asset {
+	return Asset{
+		Name:          e.Name,
+		Type:          e.Type,
+		Resource:      e.Resource,
+		IAMPolicy:     e.IAMPolicy,
+		OrgPolicy:     e.OrgPolicy,
+		V2OrgPolicies: e.V2OrgPolicies,
+		Ancestors:     e.Ancestors,
+	}
+}
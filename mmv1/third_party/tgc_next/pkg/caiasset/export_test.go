@@ -0,0 +1,141 @@
+package caiasset
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readSampleExport(t *testing.T) []Asset {
+	t.Helper()
+	f, err := os.Open("testdata/cai_export.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assets, err := ReadCAIExport(f)
+	if err != nil {
+		t.Fatalf("ReadCAIExport() = %s, want = nil", err)
+	}
+	return assets
+}
+
+func TestReadCAIExport(t *testing.T) {
+	assets := readSampleExport(t)
+	if len(assets) != 3 {
+		t.Fatalf("ReadCAIExport() returned %d assets, want 3", len(assets))
+	}
+	want := Asset{
+		Name: "//compute.googleapis.com/projects/my-project/regions/us-central1/addresses/my-address",
+		Type: "compute.googleapis.com/Address",
+		Resource: &AssetResource{
+			Version:              "v1",
+			DiscoveryDocumentURI: "https://www.googleapis.com/discovery/v1/apis/compute/v1/rest",
+			DiscoveryName:        "Address",
+			Parent:               "//cloudresourcemanager.googleapis.com/projects/123456789",
+			Data: map[string]interface{}{
+				"addressType": "EXTERNAL",
+				"name":        "my-address",
+				"region":      "projects/my-project/regions/us-central1",
+			},
+			Location: "us-central1",
+		},
+		Ancestors: []string{"projects/123456789", "folders/456", "organizations/789"},
+	}
+	if diff := cmp.Diff(want, assets[2]); diff != "" {
+		t.Errorf("ReadCAIExport() returned unexpected diff (-want +got):\n%s", diff)
+	}
+	if assets[1].IAMPolicy == nil || len(assets[1].IAMPolicy.Bindings) != 2 {
+		t.Errorf("ReadCAIExport() = %v, want iam_policy with 2 bindings", assets[1].IAMPolicy)
+	}
+}
+
+func TestReadCAIExport_JSONArray(t *testing.T) {
+	export := `
+[
+  {"name":"//cloudresourcemanager.googleapis.com/folders/456","asset_type":"cloudresourcemanager.googleapis.com/Folder","ancestors":["folders/456","organizations/789"]}
+]`
+	assets, err := ReadCAIExport(bytes.NewBufferString(export))
+	if err != nil {
+		t.Fatalf("ReadCAIExport() = %s, want = nil", err)
+	}
+	want := []Asset{{
+		Name:      "//cloudresourcemanager.googleapis.com/folders/456",
+		Type:      "cloudresourcemanager.googleapis.com/Folder",
+		Ancestors: []string{"folders/456", "organizations/789"},
+	}}
+	if diff := cmp.Diff(want, assets); diff != "" {
+		t.Errorf("ReadCAIExport() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadCAIExport_Empty(t *testing.T) {
+	assets, err := ReadCAIExport(bytes.NewBufferString("\n  \n"))
+	if err != nil || len(assets) != 0 {
+		t.Fatalf("ReadCAIExport() = %v, %v, want no assets and no error", assets, err)
+	}
+}
+
+func TestReadCAIExport_Fail(t *testing.T) {
+	_, err := ReadCAIExport(bytes.NewBufferString("{\"name\":\"a\"}\n{\"name\":"))
+	if err == nil {
+		t.Fatal("ReadCAIExport() = nil, want = err")
+	}
+}
+
+func TestWriteCAIExport(t *testing.T) {
+	want, err := os.ReadFile("testdata/cai_export.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	updateTime := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := WriteCAIExport(&got, readSampleExport(t), updateTime); err != nil {
+		t.Fatalf("WriteCAIExport() = %s, want = nil", err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("WriteCAIExport() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWritePolicyLibraryInput(t *testing.T) {
+	want, err := os.ReadFile("testdata/policy_library_input.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := WritePolicyLibraryInput(&got, readSampleExport(t)); err != nil {
+		t.Fatalf("WritePolicyLibraryInput() = %s, want = nil", err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("WritePolicyLibraryInput() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestAncestryPath(t *testing.T) {
+	tests := []struct {
+		ancestors []string
+		want      string
+	}{
+		{
+			ancestors: []string{"projects/123", "folders/456", "organizations/789"},
+			want:      "organization/789/folder/456/project/123",
+		},
+		{
+			ancestors: []string{"organizations/789"},
+			want:      "organization/789",
+		},
+		{
+			ancestors: nil,
+			want:      "",
+		},
+	}
+	for _, test := range tests {
+		if got := AncestryPath(test.ancestors); got != test.want {
+			t.Errorf("AncestryPath(%v) = %s, want = %s", test.ancestors, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ancestrymanager
+
+// This file is a copy of mmv1/third_party/tgc/ancestrymanager/cai_export.go
+// in magic-modules, like the rest of this package. tgc_next is generated into
+// its own module with its own asset types, so it can't import the tgc
+// package. The only difference is that exports are parsed into caiasset.Asset
+// with caiasset.ReadCAIExport; fixes to the ancestry logic belong in both
+// copies.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+)
+
+const (
+	crmAssetNamePrefix = "//cloudresourcemanager.googleapis.com/"
+	projectAssetType   = "cloudresourcemanager.googleapis.com/Project"
+	folderAssetType    = "cloudresourcemanager.googleapis.com/Folder"
+)
+
+// ReadCAIExport reads a Cloud Asset Inventory resource export, either as a
+// JSON array or as newline-delimited JSON, and returns the ancestry cache
+// entries for every project and folder asset in it. Projects are keyed by
+// both `projects/<number>` and their project id. The result can be passed
+// to New so ancestry can be resolved without calling Resource Manager.
+func ReadCAIExport(r io.Reader) (map[string]string, error) {
+	assets, err := caiasset.ReadCAIExport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := make(map[string][]string)
+	parents := make(map[string]string)
+	projectIDs := make(map[string]string)
+	for _, asset := range assets {
+		if asset.Type != projectAssetType && asset.Type != folderAssetType {
+			continue
+		}
+		key := strings.TrimPrefix(asset.Name, crmAssetNamePrefix)
+		if key == asset.Name {
+			return nil, fmt.Errorf("unexpected name %q for asset type %s", asset.Name, asset.Type)
+		}
+		if len(asset.Ancestors) > 0 {
+			ancestors[key] = asset.Ancestors
+		} else if parent := exportedAssetParent(asset); parent != "" {
+			parents[key] = parent
+		}
+		if asset.Type == projectAssetType && asset.Resource != nil {
+			if id, ok := asset.Resource.Data["projectId"].(string); ok && id != "" {
+				projectIDs[key] = id
+			}
+		}
+	}
+
+	// Assets without an ancestors list are resolved by following their parents.
+	// Paths that can't be followed up to an organization are left out, so they
+	// are still looked up online when possible.
+	resolved := make(map[string][]string)
+	for key := range parents {
+		var path []string
+		seen := map[string]bool{}
+		cur := key
+		complete := false
+		for cur != "" && !seen[cur] {
+			seen[cur] = true
+			if known, ok := ancestors[cur]; ok {
+				path = append(path, known...)
+				complete = true
+				break
+			}
+			path = append(path, cur)
+			complete = strings.HasPrefix(cur, orgPrefix)
+			cur = parents[cur]
+		}
+		if complete {
+			resolved[key] = path
+		}
+	}
+	for key, path := range resolved {
+		ancestors[key] = path
+	}
+
+	entries := make(map[string]string)
+	for key, as := range ancestors {
+		path := ancestryPath(as)
+		entries[key] = path
+		if id, ok := projectIDs[key]; ok {
+			entries[id] = path
+		}
+	}
+	return entries, nil
+}
+
+// exportedAssetParent returns the parent of a project or folder asset in the
+// `folders/<id>` or `organizations/<id>` format.
+func exportedAssetParent(asset caiasset.Asset) string {
+	if asset.Resource == nil {
+		return ""
+	}
+	if asset.Resource.Parent != "" {
+		return strings.TrimPrefix(asset.Resource.Parent, crmAssetNamePrefix)
+	}
+	switch parent := asset.Resource.Data["parent"].(type) {
+	case string:
+		// Folders (v2 and v3) and projects (v3) use "folders/123" or "organizations/123".
+		return parent
+	case map[string]interface{}:
+		// Projects (v1) use {"type": "folder", "id": "123"}.
+		parentType, _ := parent["type"].(string)
+		parentID, _ := parent["id"].(string)
+		if parentType == "" || parentID == "" {
+			return ""
+		}
+		return normalizeAncestry(fmt.Sprintf("%s/%s", parentType, parentID))
+	}
+	return ""
+}
+
+// ancestryPath converts ancestors sorted from closest to furthest into an
+// ancestry path like "organizations/123/folders/456/projects/789".
+func ancestryPath(ancestors []string) string {
+	var path []string
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		path = append(path, normalizeAncestry(ancestors[i]))
+	}
+	return strings.Join(path, "/")
+}
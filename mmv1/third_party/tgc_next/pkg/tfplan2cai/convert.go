@@ -1,7 +1,9 @@
 package tfplan2cai
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
 	"go.uber.org/zap"
@@ -9,8 +11,11 @@ import (
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/ancestrymanager"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/converters"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/models"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/resolvers"
 	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/transport"
+
+	transport_tpg "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/transport"
 )
 
 // Options struct to avoid updating function signatures all along the pipe.
@@ -25,13 +30,47 @@ type Options struct {
 	// Map hierarchy resource (like projects/<number> or folders/<number>)
 	// to an ancestry path (like organizations/123/folders/456/projects/789)
 	AncestryCache map[string]string
+	// Cloud Asset Inventory resource export (JSON array or newline-delimited
+	// JSON of project and folder assets) used to build the ancestry cache, so
+	// ancestry can be resolved without calling Resource Manager. Entries in
+	// AncestryCache take precedence over the export.
+	AncestryExport []byte
+	// Strict makes ConvertWithDiagnostics fail on the first resource that
+	// cannot be converted, instead of reporting it in the diagnostics and
+	// continuing. Convert always fails fast.
+	Strict bool
 }
 
 // Convert converts terraform json plan to CAI Assets.
+// It fails on the first resource that cannot be converted; warnings for
+// resources that were only partially converted are logged to the ErrorLogger.
+// Use ConvertWithDiagnostics to continue past failures and inspect them.
 func Convert(ctx context.Context, jsonPlan []byte, o *Options) ([]caiasset.Asset, error) {
 	if o == nil || o.ErrorLogger == nil {
 		return nil, fmt.Errorf("logger is not initialized")
 	}
+	strict := *o
+	strict.Strict = true
+	assets, diags, err := ConvertWithDiagnostics(ctx, jsonPlan, &strict)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range diags {
+		if d.Severity == converters.SeverityWarning {
+			o.ErrorLogger.Warn(d.String())
+		}
+	}
+	return assets, nil
+}
+
+// ConvertWithDiagnostics converts terraform json plan to CAI Assets, and
+// returns the assets it could build together with per-resource diagnostics.
+// Unless Strict is set, a resource that fails to convert doesn't stop the
+// conversion of the rest of the plan.
+func ConvertWithDiagnostics(ctx context.Context, jsonPlan []byte, o *Options) ([]caiasset.Asset, converters.Diagnostics, error) {
+	if o == nil || o.ErrorLogger == nil {
+		return nil, nil, fmt.Errorf("logger is not initialized")
+	}
 
 	resourceDataMap := resolvers.NewDefaultPreResolver(o.ErrorLogger).Resolve(jsonPlan)
 
@@ -41,21 +80,46 @@ func Convert(ctx context.Context, jsonPlan []byte, o *Options) ([]caiasset.Asset
 	// Config and ancestry manager are shared among resources.
 	cfg, err := transport.NewConfig(ctx, o.DefaultProject, o.DefaultZone, o.DefaultRegion, o.Offline, o.UserAgent)
 	if err != nil {
-		return nil, fmt.Errorf("building config: %w", err)
+		return nil, nil, fmt.Errorf("building config: %w", err)
 	}
 
-	ancestryManager, err := ancestrymanager.New(cfg, o.Offline, o.AncestryCache, o.ErrorLogger)
+	ancestryCache := o.AncestryCache
+	if len(o.AncestryExport) > 0 {
+		ancestryCache, err = ancestrymanager.ReadCAIExport(bytes.NewReader(o.AncestryExport))
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading ancestry export: %w", err)
+		}
+		for k, v := range o.AncestryCache {
+			ancestryCache[k] = v
+		}
+	}
+
+	ancestryManager, err := ancestrymanager.New(cfg, o.Offline, ancestryCache, o.ErrorLogger)
 	if err != nil {
-		return nil, fmt.Errorf("building ancestry manager: %w", err)
+		return nil, nil, fmt.Errorf("building ancestry manager: %w", err)
 	}
 
+	return convertResources(resourceDataMap, cfg, ancestryManager, o)
+}
+
+// convertResources converts the resolved resources, collecting per-resource
+// diagnostics. With o.Strict set it stops at the first error diagnostic.
+func convertResources(resourceDataMap map[string][]*models.FakeResourceDataWithMeta, cfg *transport_tpg.Config, am ancestrymanager.AncestryManager, o *Options) ([]caiasset.Asset, converters.Diagnostics, error) {
 	var assets []caiasset.Asset
+	var diags converters.Diagnostics
 	for _, resourceDataList := range resourceDataMap {
-		convertedAssets, err := converters.ConvertResource(resourceDataList, cfg, ancestryManager, o.ErrorLogger)
-		if err != nil {
-			return nil, fmt.Errorf("tfplan2ai converting: %w", err)
+		convertedAssets, resourceDiags := converters.ConvertResource(resourceDataList, cfg, am, o.ErrorLogger)
+		if o.Strict {
+			if errs := resourceDiags.Errors(); len(errs) > 0 {
+				var joined []error
+				for _, d := range errs {
+					joined = append(joined, errors.New(d.String()))
+				}
+				return nil, nil, fmt.Errorf("tfplan2cai converting: %w", errors.Join(joined...))
+			}
 		}
 		assets = append(assets, convertedAssets...)
+		diags = append(diags, resourceDiags...)
 	}
-	return assets, nil
+	return assets, diags, nil
 }
@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package tfplan2cai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/converters"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/converters/cai"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/models"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/testutil"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tpgresource"
+
+	transport_tpg "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/transport"
+)
+
+// failingProjectConverter converts google_project resources, except the ones
+// named "bad".
+func failingProjectConverter(t *testing.T) {
+	t.Helper()
+	testutil.ReplaceConverters(t, converters.ConverterMap, map[string]cai.ResourceConverter{
+		"google_project": {
+			Convert: func(d tpgresource.TerraformResourceData, config *transport_tpg.Config) ([]caiasset.Asset, error) {
+				if d.Get("name") == "bad" {
+					return nil, errors.New("conversion failed")
+				}
+				return []caiasset.Asset{{
+					Name: "//cloudresourcemanager.googleapis.com/projects/" + d.Get("project_id").(string),
+					Type: "cloudresourcemanager.googleapis.com/Project",
+				}}, nil
+			},
+		},
+	})
+}
+
+func projectResourceData(name string) map[string][]*models.FakeResourceDataWithMeta {
+	rd := testutil.FakeResourceData("google_project", "google_project."+name, map[string]interface{}{"name": name, "project_id": name})
+	return map[string][]*models.FakeResourceDataWithMeta{"google_project." + name: {rd}}
+}
+
+func TestConvertResources_continueOnError(t *testing.T) {
+	failingProjectConverter(t)
+	resources := projectResourceData("bad")
+	for k, v := range projectResourceData("good") {
+		resources[k] = v
+	}
+
+	assets, diags, err := convertResources(resources, &transport_tpg.Config{}, &testutil.FakeAncestryManager{}, &Options{ErrorLogger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("convertResources() = %v, want no error", err)
+	}
+	if assert.Len(t, assets, 1) {
+		assert.Equal(t, "//cloudresourcemanager.googleapis.com/projects/good", assets[0].Name)
+	}
+	if assert.Len(t, diags.Errors(), 1) {
+		assert.Equal(t, "google_project.bad", diags.Errors()[0].Address)
+	}
+}
+
+func TestConvertResources_strict(t *testing.T) {
+	failingProjectConverter(t)
+	resources := projectResourceData("bad")
+	for k, v := range projectResourceData("good") {
+		resources[k] = v
+	}
+
+	assets, diags, err := convertResources(resources, &transport_tpg.Config{}, &testutil.FakeAncestryManager{}, &Options{ErrorLogger: zap.NewNop(), Strict: true})
+	assert.EqualError(t, err, "tfplan2cai converting: error: google_project.bad: conversion failed")
+	assert.Nil(t, assets)
+	assert.Nil(t, diags)
+}
+
+const badProjectPlan = `{
+  "format_version": "0.1",
+  "resource_changes": [
+    {
+      "address": "google_project.bad",
+      "mode": "managed",
+      "type": "google_project",
+      "name": "bad",
+      "provider_name": "registry.terraform.io/hashicorp/google",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"name": "bad", "project_id": "bad", "org_id": "123"}
+      }
+    }
+  ]
+}`
+
+func TestConvert_failsFast(t *testing.T) {
+	failingProjectConverter(t)
+
+	_, err := Convert(context.Background(), []byte(badProjectPlan), &Options{
+		ErrorLogger: zap.NewNop(),
+		Offline:     true,
+	})
+	assert.EqualError(t, err, "tfplan2cai converting: error: google_project.bad: conversion failed")
+}
+
+func TestConvertWithDiagnostics_reportsError(t *testing.T) {
+	failingProjectConverter(t)
+
+	assets, diags, err := ConvertWithDiagnostics(context.Background(), []byte(badProjectPlan), &Options{
+		ErrorLogger: zap.NewNop(),
+		Offline:     true,
+	})
+	if err != nil {
+		t.Fatalf("ConvertWithDiagnostics() = %v, want no error", err)
+	}
+	assert.Empty(t, assets)
+	assert.Equal(t, converters.Diagnostics{{
+		Address:      "google_project.bad",
+		ResourceType: "google_project",
+		Severity:     converters.SeverityError,
+		Message:      "conversion failed",
+	}}, diags)
+}
@@ -14,18 +14,27 @@ import (
 	"go.uber.org/zap"
 )
 
-// Converts the single resource into CAI assets
-func ConvertResource(rdList []*models.FakeResourceDataWithMeta, cfg *transport_tpg.Config, am ancestrymanager.AncestryManager, errLogger *zap.Logger) ([]caiasset.Asset, error) {
+// Converts the single resource into CAI assets.
+// A resource that fails to convert doesn't abort the conversion of the others;
+// the failure is reported in the returned diagnostics instead.
+func ConvertResource(rdList []*models.FakeResourceDataWithMeta, cfg *transport_tpg.Config, am ancestrymanager.AncestryManager, errLogger *zap.Logger) ([]caiasset.Asset, Diagnostics) {
 	if rdList == nil || len(rdList) == 0 {
 		return nil, nil
 	}
 
 	var assets []caiasset.Asset
+	var diags Diagnostics
 	for _, rd := range rdList {
 		// Skip unsupported resources
 		converter, ok := ConverterMap[rd.Kind()]
 		if !ok {
 			errLogger.Debug(fmt.Sprintf("%s: resource type cannot be converted for CAI-based policies: %s. For details, see https://cloud.google.com/docs/terraform/policy-validation/create-cai-constraints#supported_resources", rd.Address(), rd.Kind()))
+			diags = append(diags, Diagnostic{
+				Address:      rd.Address(),
+				ResourceType: rd.Kind(),
+				Severity:     SeverityInfo,
+				Message:      "resource type cannot be converted for CAI-based policies",
+			})
 			continue
 		} else {
 			convertedAssets, err := converter.Convert(rd, cfg)
@@ -33,22 +42,51 @@ func ConvertResource(rdList []*models.FakeResourceDataWithMeta, cfg *transport_t
 				if errors.Cause(err) == cai.ErrNoConversion {
 					continue
 				}
-				return assets, err
+				diags = append(diags, Diagnostic{
+					Address:      rd.Address(),
+					ResourceType: rd.Kind(),
+					Severity:     SeverityError,
+					Message:      err.Error(),
+				})
+				continue
 			}
 
 			// TODO: combine assets and fetch full policy for IAM bindings/members
 			// TODO: combine tfplan address
 
+			var rdAssets []caiasset.Asset
 			for _, asset := range convertedAssets {
 				asset.TfplanAddress = []string{rd.Address()}
-				err := am.SetAncestors(rd, cfg, &asset)
+				err = am.SetAncestors(rd, cfg, &asset)
 				if err != nil {
-					return nil, err
+					break
 				}
-				assets = append(assets, asset)
+				rdAssets = append(rdAssets, asset)
 			}
+			if err != nil {
+				diags = append(diags, Diagnostic{
+					Address:      rd.Address(),
+					ResourceType: rd.Kind(),
+					AssetType:    convertedAssets[0].Type,
+					Severity:     SeverityError,
+					Message:      fmt.Sprintf("setting ancestors: %s", err),
+				})
+				continue
+			}
+
+			if fields := rd.UnsupportedFields(); len(fields) > 0 && len(rdAssets) > 0 {
+				diags = append(diags, Diagnostic{
+					Address:           rd.Address(),
+					ResourceType:      rd.Kind(),
+					AssetType:         rdAssets[0].Type,
+					Severity:          SeverityWarning,
+					Message:           "fields are not supported and were dropped from the converted asset",
+					UnsupportedFields: fields,
+				})
+			}
+			assets = append(assets, rdAssets...)
 		}
 	}
 
-	return assets, nil
+	return assets, diags
 }
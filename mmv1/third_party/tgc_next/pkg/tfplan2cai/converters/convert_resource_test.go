@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package converters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/converters/cai"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/models"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/testutil"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tpgresource"
+
+	transport_tpg "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/transport"
+)
+
+func TestConvertResource_continuesAfterError(t *testing.T) {
+	testutil.ReplaceConverters(t, ConverterMap, map[string]cai.ResourceConverter{
+		"google_project": {
+			Convert: func(d tpgresource.TerraformResourceData, config *transport_tpg.Config) ([]caiasset.Asset, error) {
+				if d.Get("name") == "bad" {
+					return nil, errors.New("conversion failed")
+				}
+				return []caiasset.Asset{{Name: "//cloudresourcemanager.googleapis.com/projects/good", Type: "cloudresourcemanager.googleapis.com/Project"}}, nil
+			},
+		},
+	})
+
+	rdList := []*models.FakeResourceDataWithMeta{
+		testutil.FakeResourceData("google_project", "google_project.bad", map[string]interface{}{"name": "bad", "project_id": "bad"}),
+		testutil.FakeResourceData("google_project", "google_project.good", map[string]interface{}{"name": "good", "project_id": "good"}),
+	}
+	assets, diags := ConvertResource(rdList, &transport_tpg.Config{}, &testutil.FakeAncestryManager{}, zap.NewNop())
+
+	if assert.Len(t, assets, 1) {
+		assert.Equal(t, []string{"google_project.good"}, assets[0].TfplanAddress)
+		assert.Equal(t, []string{"projects/123"}, assets[0].Ancestors)
+	}
+	assert.Equal(t, Diagnostics{{
+		Address:      "google_project.bad",
+		ResourceType: "google_project",
+		Severity:     SeverityError,
+		Message:      "conversion failed",
+	}}, diags)
+}
+
+func TestConvertResource_ancestryError(t *testing.T) {
+	testutil.ReplaceConverters(t, ConverterMap, map[string]cai.ResourceConverter{
+		"google_project": {
+			Convert: func(d tpgresource.TerraformResourceData, config *transport_tpg.Config) ([]caiasset.Asset, error) {
+				return []caiasset.Asset{{Name: "//cloudresourcemanager.googleapis.com/projects/p", Type: "cloudresourcemanager.googleapis.com/Project"}}, nil
+			},
+		},
+	})
+
+	rdList := []*models.FakeResourceDataWithMeta{
+		testutil.FakeResourceData("google_project", "google_project.p", map[string]interface{}{"name": "p", "project_id": "p"}),
+	}
+	am := &testutil.FakeAncestryManager{Err: errors.New("project not found in ancestry cache")}
+	assets, diags := ConvertResource(rdList, &transport_tpg.Config{}, am, zap.NewNop())
+
+	assert.Empty(t, assets)
+	assert.Equal(t, Diagnostics{{
+		Address:      "google_project.p",
+		ResourceType: "google_project",
+		AssetType:    "cloudresourcemanager.googleapis.com/Project",
+		Severity:     SeverityError,
+		Message:      "setting ancestors: project not found in ancestry cache",
+	}}, diags)
+}
+
+func TestConvertResource_unsupportedFieldsWarning(t *testing.T) {
+	testutil.ReplaceConverters(t, ConverterMap, map[string]cai.ResourceConverter{
+		"google_project": {
+			Convert: func(d tpgresource.TerraformResourceData, config *transport_tpg.Config) ([]caiasset.Asset, error) {
+				return []caiasset.Asset{{Name: "//cloudresourcemanager.googleapis.com/projects/p", Type: "cloudresourcemanager.googleapis.com/Project"}}, nil
+			},
+		},
+	})
+
+	rdList := []*models.FakeResourceDataWithMeta{
+		testutil.FakeResourceData("google_project", "google_project.p", map[string]interface{}{"name": "p", "project_id": "p", "unknown_str": "value"}),
+	}
+	assets, diags := ConvertResource(rdList, &transport_tpg.Config{}, &testutil.FakeAncestryManager{}, zap.NewNop())
+
+	assert.Len(t, assets, 1)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, SeverityWarning, diags[0].Severity)
+		assert.Equal(t, []string{"unknown_str"}, diags[0].UnsupportedFields)
+	}
+	assert.Empty(t, diags.Errors())
+}
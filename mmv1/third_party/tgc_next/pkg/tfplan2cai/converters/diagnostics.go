@@ -0,0 +1,60 @@
+package converters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity describes how a diagnostic affected the conversion of a resource.
+type Severity string
+
+const (
+	// SeverityError means the resource could not be converted and no assets
+	// were produced for it.
+	SeverityError Severity = "error"
+	// SeverityWarning means the resource was converted, but the assets may not
+	// reflect the full configuration (for example, some fields are unsupported).
+	SeverityWarning Severity = "warning"
+	// SeverityInfo means the resource was skipped intentionally, for example
+	// because its resource type has no CAI converter.
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic reports a problem encountered while converting a single tfplan
+// resource into CAI assets.
+type Diagnostic struct {
+	// Address is the tfplan address of the resource, e.g. "google_project.my_project".
+	Address string `json:"tfplan_address"`
+	// ResourceType is the terraform resource type, e.g. "google_project".
+	ResourceType string `json:"resource_type"`
+	// AssetType is the CAI asset type produced for the resource. It is empty
+	// when the conversion failed before any asset was built.
+	AssetType string   `json:"asset_type,omitempty"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+	// UnsupportedFields lists the fields set in the plan that the converter
+	// does not know about, using terraform attribute paths like "boot_disk.0.kms_key_self_link".
+	UnsupportedFields []string `json:"unsupported_fields,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s: %s", d.Severity, d.Address, d.Message)
+	if len(d.UnsupportedFields) > 0 {
+		s += fmt.Sprintf(" (unsupported fields: %s)", strings.Join(d.UnsupportedFields, ", "))
+	}
+	return s
+}
+
+// Diagnostics is a list of Diagnostic.
+type Diagnostics []Diagnostic
+
+// Errors returns only the diagnostics with error severity.
+func (diags Diagnostics) Errors() Diagnostics {
+	var errs Diagnostics
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
@@ -18,6 +18,10 @@
 package models
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -27,9 +31,10 @@ type FakeResourceDataWithMeta struct {
 }
 
 type FakeResourceMeta struct {
-	kind      string
-	address   string
-	isDeleted bool
+	kind              string
+	address           string
+	isDeleted         bool
+	unsupportedFields []string
 }
 
 // Kind returns the type of resource (i.e. "google_storage_bucket").
@@ -45,6 +50,12 @@ func (d *FakeResourceMeta) IsDeleted() bool {
 	return d.isDeleted
 }
 
+// UnsupportedFields returns the attribute paths that are set in the plan
+// but are not part of the resource schema, so they are dropped during conversion.
+func (d *FakeResourceMeta) UnsupportedFields() []string {
+	return d.unsupportedFields
+}
+
 func NewFakeResourceDataWithMeta(kind string, resourceSchema map[string]*schema.Schema, values map[string]interface{}, isDeleted bool, tfplanAddress string) *FakeResourceDataWithMeta {
 	state := map[string]string{}
 	var address []string
@@ -59,9 +70,70 @@ func NewFakeResourceDataWithMeta(kind string, resourceSchema map[string]*schema.
 			reader: reader,
 		},
 		FakeResourceMeta{
-			kind:      kind,
-			isDeleted: isDeleted,
-			address:   tfplanAddress,
+			kind:              kind,
+			isDeleted:         isDeleted,
+			address:           tfplanAddress,
+			unsupportedFields: unsupportedFields(values, nil, resourceSchema),
 		},
 	}
 }
+
+// Attributes that are present in every plan but never converted.
+var ignoredPlanAttributes = map[string]bool{
+	"id":       true,
+	"timeouts": true,
+}
+
+// unsupportedFields walks the plan values and returns the sorted paths of
+// the fields that have a non-empty value but no schema.
+func unsupportedFields(values map[string]interface{}, address []string, schemas map[string]*schema.Schema) []string {
+	var fields []string
+	for k, v := range values {
+		if isEmptyValue(v) {
+			continue
+		}
+		if len(address) == 0 && ignoredPlanAttributes[k] {
+			continue
+		}
+		addr := append(append([]string{}, address...), k)
+		sch, ok := schemas[k]
+		if !ok {
+			fields = append(fields, strings.Join(addr, "."))
+			continue
+		}
+		res, ok := sch.Elem.(*schema.Resource)
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case []interface{}:
+			for i, e := range v {
+				if m, ok := e.(map[string]interface{}); ok {
+					fields = append(fields, unsupportedFields(m, append(addr, strconv.Itoa(i)), res.Schema)...)
+				}
+			}
+		case map[string]interface{}:
+			fields = append(fields, unsupportedFields(v, addr, res.Schema)...)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -363,3 +363,47 @@ func TestFakeResourceDataWithMeta_address(t *testing.T) {
 	)
 	assert.Equal(t, "google_project.test-project", d.Address())
 }
+
+func TestFakeResourceDataWithMeta_unsupportedFields(t *testing.T) {
+	p := provider.Provider()
+
+	values := map[string]interface{}{
+		"id":           "projects/tf-test-872899419570852129",
+		"name":         "test-project",
+		"project_id":   "tf-test-872899419570852129",
+		"unknown_bool": false,
+		"unknown_list": []interface{}{},
+		"unknown_str":  "value",
+	}
+	d := NewFakeResourceDataWithMeta(
+		"google_project",
+		p.ResourcesMap["google_project"].Schema,
+		values,
+		false,
+		"google_project.test-project",
+	)
+	assert.Equal(t, []string{"unknown_str"}, d.UnsupportedFields())
+}
+
+func TestFakeResourceDataWithMeta_unsupportedNestedFields(t *testing.T) {
+	p := provider.Provider()
+
+	values := map[string]interface{}{
+		"machine_type": "n1-standard-1",
+		"name":         "test-instance",
+		"boot_disk": []interface{}{
+			map[string]interface{}{
+				"auto_delete":   true,
+				"unknown_field": "value",
+			},
+		},
+	}
+	d := NewFakeResourceDataWithMeta(
+		"google_compute_instance",
+		p.ResourcesMap["google_compute_instance"].Schema,
+		values,
+		false,
+		"google_compute_instance.test-instance",
+	)
+	assert.Equal(t, []string{"boot_disk.0.unknown_field"}, d.UnsupportedFields())
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testutil has fakes shared by the tfplan2cai tests.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/converters/cai"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai/models"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tpgresource"
+
+	provider "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/provider"
+	transport_tpg "github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/transport"
+)
+
+// FakeAncestryManager puts every resource under "projects/123", or fails with
+// Err if it is set.
+type FakeAncestryManager struct {
+	Err error
+}
+
+func (m *FakeAncestryManager) Ancestors(config *transport_tpg.Config, tfData tpgresource.TerraformResourceData, cai *caiasset.Asset) ([]string, string, error) {
+	return []string{"projects/123"}, "projects/123", m.Err
+}
+
+func (m *FakeAncestryManager) SetAncestors(d tpgresource.TerraformResourceData, config *transport_tpg.Config, cai *caiasset.Asset) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	cai.Ancestors = []string{"projects/123"}
+	return nil
+}
+
+// FakeResourceData returns the resource data of a planned resource of the
+// given kind at address.
+func FakeResourceData(kind, address string, values map[string]interface{}) *models.FakeResourceDataWithMeta {
+	p := provider.Provider()
+	return models.NewFakeResourceDataWithMeta(kind, p.ResourcesMap[kind].Schema, values, false, address)
+}
+
+// ReplaceConverters replaces the converters for the given resource types in
+// converterMap, usually converters.ConverterMap, for the duration of the test.
+func ReplaceConverters(t *testing.T, converterMap, replacements map[string]cai.ResourceConverter) {
+	t.Helper()
+	for kind, converter := range replacements {
+		orig, ok := converterMap[kind]
+		converterMap[kind] = converter
+		t.Cleanup(func() {
+			if ok {
+				converterMap[kind] = orig
+			} else {
+				delete(converterMap, kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/cai2hcl"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/tfplan2cai"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// RoundTripReport lists the fields of the primary resource that are lost
+// when its config is converted with tfplan2cai and then back with cai2hcl.
+type RoundTripReport struct {
+	// Fields set in the config that are missing from the CAI asset data.
+	LostInTfplan2cai []string
+	// Fields present in the CAI asset data that are missing from the
+	// roundtrip config.
+	LostInCai2hcl []string
+}
+
+func (r RoundTripReport) empty() bool {
+	return len(r.LostInTfplan2cai) == 0 && len(r.LostInCai2hcl) == 0
+}
+
+func (r RoundTripReport) String() string {
+	var sb strings.Builder
+	if len(r.LostInTfplan2cai) > 0 {
+		sb.WriteString(fmt.Sprintf("fields lost in tfplan2cai conversion:\n  %s\n", strings.Join(r.LostInTfplan2cai, "\n  ")))
+	}
+	if len(r.LostInCai2hcl) > 0 {
+		sb.WriteString(fmt.Sprintf("fields lost in cai2hcl conversion:\n  %s\n", strings.Join(r.LostInCai2hcl, "\n  ")))
+	}
+	return sb.String()
+}
+
+// RoundTripOptions describes a single example for RoundTripConversion.
+type RoundTripOptions struct {
+	// Address of the resource under test, e.g. "google_compute_address.default".
+	PrimaryResource string
+	// Example config with test variables like "%{random_suffix}" or "%{org_id}".
+	ConfigFile string
+	// TestEnvVars maps the config variables that hold test environment values
+	// to the name of the environment variable (e.g. "org_id" -> "ORG_ID").
+	TestEnvVars map[string]string
+	// ApiFields maps terraform fields whose name differs in the API to their
+	// API path (e.g. "labels_map" -> "labels"), both in the
+	// "parent.child" format without list indexes.
+	ApiFields     map[string]string
+	IgnoredFields []string
+}
+
+// roundTripEnvValues are the offline values of test environment variables
+// used in example configs.
+var roundTripEnvValues = map[string]string{
+	"PROJECT_NAME":        defaultProject,
+	"PROJECT_NUMBER":      "1111111111111",
+	"REGION":              "us-central1",
+	"ZONE":                "us-central1-a",
+	"ORG_ID":              defaultOrganization,
+	"ORG_DOMAIN":          "example.com",
+	"ORG_TARGET":          defaultOrganization,
+	"BILLING_ACCT":        "000000-0000000-0000000-000000",
+	"MASTER_BILLING_ACCT": "000000-0000000-0000000-000000",
+	"SERVICE_ACCT":        "tf-test@" + defaultProject + ".iam.gserviceaccount.com",
+	"CUST_ID":             "A01b123xz",
+}
+
+var testVarRegex = regexp.MustCompile(`%\{(\w+)\}`)
+
+// substituteTestVars replaces the test variables in an example config with
+// offline values. Variables that have no known value (such as overrides set
+// from Go code in the provider tests) are replaced with "tf-test-<name>".
+func substituteTestVars(config string, testEnvVars map[string]string) string {
+	return testVarRegex.ReplaceAllStringFunc(config, func(m string) string {
+		name := testVarRegex.FindStringSubmatch(m)[1]
+		if name == "random_suffix" {
+			return "roundtrip"
+		}
+		if v, ok := roundTripEnvValues[testEnvVars[name]]; ok {
+			return v
+		}
+		return "tf-test-" + strings.ReplaceAll(name, "_", "-")
+	})
+}
+
+// RoundTripConversion converts an example config through tfplan2cai and back
+// through cai2hcl, and reports the fields of the primary resource that are
+// lost in either direction. Lost fields are only logged, so resources with
+// known gaps don't fail the test; conversion errors do.
+func RoundTripConversion(t *testing.T, o RoundTripOptions) RoundTripReport {
+	primaryResource := o.PrimaryResource
+	config, err := os.ReadFile(o.ConfigFile)
+	if err != nil {
+		t.Fatalf("Error reading the example config %s: %s", o.ConfigFile, err)
+	}
+
+	tfDir, err := os.MkdirTemp(tmpDir, "terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tfDir)
+
+	fileName := strings.ReplaceAll(t.Name(), "/", "_")
+	rawTfFilePath := filepath.Join(tfDir, fmt.Sprintf("%s.tf", fileName))
+	if err := os.WriteFile(rawTfFilePath, []byte(substituteTestVars(string(config), o.TestEnvVars)), 0644); err != nil {
+		t.Fatalf("Error writing the file %s: %s", rawTfFilePath, err)
+	}
+
+	rawResources, err := parseResourceConfigs(rawTfFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawConfig, ok := convertToConfigMap(rawResources)[primaryResource]
+	if !ok {
+		t.Fatalf("Primary resource %s is not found in the example config %s", primaryResource, o.ConfigFile)
+	}
+
+	terraformWorkflow(t, tfDir, fileName)
+	jsonPlan, err := os.ReadFile(filepath.Join(tfDir, fmt.Sprintf("%s.tfplan.json", fileName)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zaptest.NewLogger(t)
+	assets, diags, err := tfplan2cai.ConvertWithDiagnostics(context.Background(), jsonPlan, &tfplan2cai.Options{
+		ErrorLogger:    logger,
+		Offline:        true,
+		DefaultProject: defaultProject,
+		AncestryCache: map[string]string{
+			defaultProject: fmt.Sprintf("organizations/%s", defaultOrganization),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error converting the plan with tfplan2cai: %s", err)
+	}
+
+	var primaryAssets []caiasset.Asset
+	for _, asset := range assets {
+		for _, address := range asset.TfplanAddress {
+			if address == primaryResource {
+				primaryAssets = append(primaryAssets, asset)
+				break
+			}
+		}
+	}
+	var unsupportedFields []string
+	for _, d := range diags {
+		if d.Address != primaryResource {
+			continue
+		}
+		t.Logf("tfplan2cai diagnostic: %s", d)
+		unsupportedFields = append(unsupportedFields, d.UnsupportedFields...)
+	}
+	if len(primaryAssets) == 0 {
+		t.Fatalf("tfplan2cai produced no assets for the primary resource %s", primaryResource)
+	}
+
+	roundtripConfigData, err := cai2hcl.Convert(primaryAssets, &cai2hcl.Options{
+		ErrorLogger: logger,
+	})
+	if err != nil {
+		t.Fatalf("Error converting the assets with cai2hcl: %s", err)
+	}
+	roundtripTfFilePath := filepath.Join(tfDir, fmt.Sprintf("%s_roundtrip.tf", fileName))
+	if err := os.WriteFile(roundtripTfFilePath, roundtripConfigData, 0644); err != nil {
+		t.Fatalf("Error writing the file %s: %s", roundtripTfFilePath, err)
+	}
+	roundtripResources, err := parseResourceConfigs(roundtripTfFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourceType := strings.SplitN(primaryResource, ".", 2)[0]
+	roundtripConfig := make(map[string]interface{})
+	for _, r := range roundtripResources {
+		if r.Type == resourceType {
+			roundtripConfig = r.Attributes
+			break
+		}
+	}
+
+	ignoredFieldMap := make(map[string]bool, 0)
+	for _, f := range o.IgnoredFields {
+		ignoredFieldMap[f] = true
+	}
+	report := compareRoundTrip(rawConfig, roundtripConfig, primaryAssets, unsupportedFields, o.ApiFields, ignoredFieldMap)
+	if !report.empty() {
+		t.Logf("Round-trip conversion of %s lost fields:\n%s", primaryResource, report)
+		return report
+	}
+	log.Printf("Round-trip conversion passes for resource %s. All of the fields in the example config are in the roundtrip config", primaryResource)
+	return report
+}
+
+// compareRoundTrip finds the fields of the raw config that are missing from
+// the roundtrip config, and attributes each of them to the conversion that
+// lost it by looking the field up in the CAI asset data.
+func compareRoundTrip(rawConfig, roundtripConfig map[string]interface{}, assets []caiasset.Asset, unsupportedFields []string, apiFields map[string]string, ignoredFields map[string]bool) RoundTripReport {
+	var report RoundTripReport
+	lostInTfplan2cai := make(map[string]bool)
+	for _, f := range unsupportedFields {
+		lostInTfplan2cai[hclFieldPath(f)] = true
+	}
+
+	for _, field := range compareHCLFields(rawConfig, roundtripConfig, "", ignoredFields) {
+		if lostInTfplan2cai[stripIndexes(field)] {
+			continue
+		}
+		if assetsHaveField(assets, field, apiFields) {
+			report.LostInCai2hcl = append(report.LostInCai2hcl, field)
+		} else {
+			lostInTfplan2cai[stripIndexes(field)] = true
+		}
+	}
+	for f := range lostInTfplan2cai {
+		if !ignoredFields[f] {
+			report.LostInTfplan2cai = append(report.LostInTfplan2cai, f)
+		}
+	}
+	sort.Strings(report.LostInTfplan2cai)
+	sort.Strings(report.LostInCai2hcl)
+	return report
+}
+
+// hclFieldPath converts a terraform attribute path like "boot_disk.0.source"
+// to the path format used by compareHCLFields without indexes ("boot_disk.source").
+func hclFieldPath(attributePath string) string {
+	var parts []string
+	for _, p := range strings.Split(attributePath, ".") {
+		if p == "#" || isIndex(p) {
+			continue
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// stripIndexes removes list indexes like "[0]" from a path returned by compareHCLFields.
+func stripIndexes(path string) string {
+	var sb strings.Builder
+	inIndex := false
+	for _, c := range path {
+		switch {
+		case c == '[':
+			inIndex = true
+		case c == ']':
+			inIndex = false
+		case !inIndex:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// assetsHaveField reports whether any of the assets has a value for the field
+// in its resource data. Fields are looked up by their API path from apiFields
+// when they are renamed, and by their terraform name otherwise, matching the
+// camelCase CAI field names. Fields that are flattened by the converter are
+// treated as missing.
+func assetsHaveField(assets []caiasset.Asset, field string, apiFields map[string]string) bool {
+	path := stripIndexes(field)
+	if apiPath, ok := apiFields[path]; ok {
+		path = apiPath
+	}
+	parts := strings.Split(path, ".")
+	for _, asset := range assets {
+		if asset.Resource == nil {
+			continue
+		}
+		if dataHasField(asset.Resource.Data, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+func dataHasField(data interface{}, parts []string) bool {
+	if len(parts) == 0 {
+		return data != nil
+	}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		value, ok := v[snakeToCamel(parts[0])]
+		if !ok {
+			value, ok = v[parts[0]]
+		}
+		return ok && dataHasField(value, parts[1:])
+	case []interface{}:
+		for _, e := range v {
+			if dataHasField(e, parts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/terraform-google-conversion/v6/pkg/caiasset"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompareRoundTrip(t *testing.T) {
+	rawConfig := map[string]interface{}{
+		"name":        "my-address",
+		"labels_map":  map[string]interface{}{"env": "test"},
+		"description": "lost in cai2hcl",
+		"unsupported": "dropped by tfplan2cai",
+		"missing":     "not in the asset",
+		"ignored":     "ignored field",
+		"boot_disk": []interface{}{
+			map[string]interface{}{"source": "disk", "kms_key": "key"},
+		},
+	}
+	roundtripConfig := map[string]interface{}{
+		"name":      "my-address",
+		"boot_disk": []interface{}{map[string]interface{}{"source": "disk"}},
+	}
+	assets := []caiasset.Asset{{
+		Resource: &caiasset.AssetResource{
+			Data: map[string]interface{}{
+				"name":        "my-address",
+				"labels":      map[string]interface{}{"env": "test"},
+				"description": "lost in cai2hcl",
+				"bootDisk":    []interface{}{map[string]interface{}{"source": "disk", "kmsKey": "key"}},
+			},
+		},
+	}}
+	apiFields := map[string]string{"labels_map": "labels"}
+	ignoredFields := map[string]bool{"ignored": true}
+
+	got := compareRoundTrip(rawConfig, roundtripConfig, assets, []string{"unsupported"}, apiFields, ignoredFields)
+	want := RoundTripReport{
+		LostInTfplan2cai: []string{"missing", "unsupported"},
+		LostInCai2hcl:    []string{"boot_disk[0].kms_key", "description", "labels_map"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("compareRoundTrip() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareRoundTrip_Empty(t *testing.T) {
+	config := map[string]interface{}{"name": "my-address"}
+	got := compareRoundTrip(config, config, nil, nil, nil, nil)
+	if !got.empty() {
+		t.Errorf("compareRoundTrip() = %v, want an empty report", got)
+	}
+}
+
+func TestHclFieldPath(t *testing.T) {
+	cases := map[string]string{
+		"name":                          "name",
+		"boot_disk.0.source":            "boot_disk.source",
+		"tags.#":                        "tags",
+		"network_interface.1.alias.0.a": "network_interface.alias.a",
+	}
+	for in, want := range cases {
+		if got := hclFieldPath(in); got != want {
+			t.Errorf("hclFieldPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStripIndexes(t *testing.T) {
+	cases := map[string]string{
+		"name":                              "name",
+		"boot_disk[0].source":               "boot_disk.source",
+		"network_interface[10].alias[2].ip": "network_interface.alias.ip",
+	}
+	for in, want := range cases {
+		if got := stripIndexes(in); got != want {
+			t.Errorf("stripIndexes(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDataHasField(t *testing.T) {
+	data := map[string]interface{}{
+		"name":     "my-address",
+		"bootDisk": []interface{}{map[string]interface{}{"diskSizeGb": 10}},
+		"snake_key": map[string]interface{}{
+			"nested": "value",
+		},
+		"empty": nil,
+	}
+	cases := []struct {
+		parts []string
+		want  bool
+	}{
+		{[]string{"name"}, true},
+		{[]string{"boot_disk", "disk_size_gb"}, true},
+		{[]string{"snake_key", "nested"}, true},
+		{[]string{"boot_disk", "source"}, false},
+		{[]string{"empty"}, false},
+		{[]string{"missing"}, false},
+	}
+	for _, tc := range cases {
+		if got := dataHasField(data, tc.parts); got != tc.want {
+			t.Errorf("dataHasField(%v) = %t, want %t", tc.parts, got, tc.want)
+		}
+	}
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	cases := map[string]string{
+		"name":         "name",
+		"disk_size_gb": "diskSizeGb",
+		"ipv6_address": "ipv6Address",
+	}
+	for in, want := range cases {
+		if got := snakeToCamel(in); got != want {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSubstituteTestVars(t *testing.T) {
+	config := `resource "google_project" "default" {
+  name       = "tf-test-project%{random_suffix}"
+  org_id     = "%{org_id}"
+  network    = "%{network_name}"
+}`
+	want := `resource "google_project" "default" {
+  name       = "tf-test-projectroundtrip"
+  org_id     = "` + defaultOrganization + `"
+  network    = "tf-test-network-name"
+}`
+	if got := substituteTestVars(config, map[string]string{"org_id": "ORG_ID"}); got != want {
+		t.Errorf("substituteTestVars() = %q, want %q", got, want)
+	}
+}
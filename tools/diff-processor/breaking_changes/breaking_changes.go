@@ -23,12 +23,22 @@ func NewBreakingChange(message, identifier string) BreakingChange {
 	}
 }
 
+// newBreakingChangeForRule records where a breaking change was found, so that
+// it can be reported per resource and field.
+func newBreakingChangeForRule(message, identifier, resource, field string) BreakingChange {
+	breakingChange := NewBreakingChange(message, identifier)
+	breakingChange.Resource = resource
+	breakingChange.Field = field
+	breakingChange.RuleName = identifier
+	return breakingChange
+}
+
 func ComputeBreakingChanges(schemaDiff diff.SchemaDiff) []BreakingChange {
 	var breakingChanges []BreakingChange
 	for resource, resourceDiff := range schemaDiff {
 		for _, rule := range ResourceConfigDiffRules {
 			for _, message := range rule.Messages(resource, resourceDiff.ResourceConfig) {
-				breakingChanges = append(breakingChanges, NewBreakingChange(message, rule.Identifier))
+				breakingChanges = append(breakingChanges, newBreakingChangeForRule(message, rule.Identifier, resource, ""))
 			}
 		}
 
@@ -39,7 +49,7 @@ func ComputeBreakingChanges(schemaDiff diff.SchemaDiff) []BreakingChange {
 
 		for _, rule := range ResourceDiffRules {
 			for _, message := range rule.Messages(resource, resourceDiff) {
-				breakingChanges = append(breakingChanges, NewBreakingChange(message, rule.Identifier))
+				breakingChanges = append(breakingChanges, newBreakingChangeForRule(message, rule.Identifier, resource, ""))
 			}
 		}
 
@@ -47,7 +57,7 @@ func ComputeBreakingChanges(schemaDiff diff.SchemaDiff) []BreakingChange {
 			for _, rule := range FieldDiffRules {
 				rd := schemaDiff[resource]
 				for _, message := range rule.Messages(resource, field, fieldDiff, rd) {
-					breakingChanges = append(breakingChanges, NewBreakingChange(message, rule.Identifier))
+					breakingChanges = append(breakingChanges, newBreakingChangeForRule(message, rule.Identifier, resource, field))
 				}
 			}
 		}
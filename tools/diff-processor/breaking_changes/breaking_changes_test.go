@@ -95,6 +95,8 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Resource `google-x` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-map-resource-removal-or-rename",
+					Resource:               "google-x",
+					RuleName:               "resource-map-resource-removal-or-rename",
 				},
 			},
 		},
@@ -119,6 +121,8 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-b` within resource `google-x` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-schema-field-removal-or-rename",
+					Resource:               "google-x",
+					RuleName:               "resource-schema-field-removal-or-rename",
 				},
 			},
 		},
@@ -144,6 +148,9 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a` changed from optional to required on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required",
+					Resource:               "google-x",
+					Field:                  "field-a",
+					RuleName:               "field-optional-to-required",
 				},
 			},
 		},
@@ -168,10 +175,15 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a` changed from optional to required on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required",
+					Resource:               "google-x",
+					Field:                  "field-a",
+					RuleName:               "field-optional-to-required",
 				},
 				{
 					Message:                "Field `field-b` within resource `google-x` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-schema-field-removal-or-rename",
+					Resource:               "google-x",
+					RuleName:               "resource-schema-field-removal-or-rename",
 				},
 			},
 		},
@@ -201,14 +213,21 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a` changed from optional to required on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required",
+					Resource:               "google-x",
+					Field:                  "field-a",
+					RuleName:               "field-optional-to-required",
 				},
 				{
 					Message:                "Field `field-b` within resource `google-x` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-schema-field-removal-or-rename",
+					Resource:               "google-x",
+					RuleName:               "resource-schema-field-removal-or-rename",
 				},
 				{
 					Message:                "Resource `google-y` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-map-resource-removal-or-rename",
+					Resource:               "google-y",
+					RuleName:               "resource-map-resource-removal-or-rename",
 				},
 			},
 		},
@@ -255,6 +274,8 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a.sub-field-2` within resource `google-x` was either removed or renamed",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-schema-field-removal-or-rename",
+					Resource:               "google-x",
+					RuleName:               "resource-schema-field-removal-or-rename",
 				},
 			},
 		},
@@ -300,6 +321,9 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a.sub-field-1` MaxItems went from 100 to 25 on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-shrinking-max",
+					Resource:               "google-x",
+					Field:                  "field-a.sub-field-1",
+					RuleName:               "field-shrinking-max",
 				},
 			},
 		},
@@ -345,6 +369,9 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a.sub-field-1` MaxItems went from 100 to 25 on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-shrinking-max",
+					Resource:               "google-x",
+					Field:                  "field-a.sub-field-1",
+					RuleName:               "field-shrinking-max",
 				},
 			},
 		},
@@ -376,6 +403,9 @@ func TestComputeBreakingChanges(t *testing.T) {
 				{
 					Message:                "Field `field-a` MinItems went from 1 to 4 on `google-x`",
 					DocumentationReference: "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-growing-min",
+					Resource:               "google-x",
+					Field:                  "field-a",
+					RuleName:               "field-growing-min",
 				},
 			},
 		},
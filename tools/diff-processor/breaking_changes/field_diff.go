@@ -2,7 +2,9 @@ package breaking_changes
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
@@ -29,6 +31,14 @@ var FieldDiffRules = []FieldDiffRule{
 	FieldGrowingMin,
 	FieldShrinkingMax,
 	FieldRemovingDiffSuppress,
+	FieldRemovingEnumValue,
+	FieldNarrowingValidationRegex,
+	FieldShrinkingValidationRange,
+	FieldBecomingForceNew,
+	FieldRemovingSensitive,
+	FieldChangingWriteOnly,
+	FieldAddingConflictsWith,
+	FieldOneOfGroupGainingMember,
 }
 
 var FieldChangingType = FieldDiffRule{
@@ -257,3 +267,285 @@ func FieldNewOptionalFieldWithDefaultMessages(resource, field string, fieldDiff
 	}
 	return nil
 }
+
+var FieldRemovingEnumValue = FieldDiffRule{
+	Identifier: "field-removing-enum-value",
+	Messages:   FieldRemovingEnumValueMessages,
+}
+
+func FieldRemovingEnumValueMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// only applies to mmv1 fields present before and after
+	if fieldDiff.ApiType.Old == nil || fieldDiff.ApiType.New == nil {
+		return nil
+	}
+	oldValues := diff.EnumValues(fieldDiff.ApiType.Old)
+	newValues := diff.EnumValues(fieldDiff.ApiType.New)
+	if len(newValues) == 0 {
+		return nil
+	}
+	if len(oldValues) == 0 {
+		tmpl := "Field `%s` changed from accepting any value to accepting only %s on `%s`"
+		return []string{fmt.Sprintf(tmpl, field, formatValues(newValues), resource)}
+	}
+	var removed []string
+	for _, v := range oldValues {
+		if !slices.Contains(newValues, v) {
+			removed = append(removed, v)
+		}
+	}
+	if len(removed) > 0 {
+		tmpl := "Field `%s` no longer accepts %s on `%s`"
+		return []string{fmt.Sprintf(tmpl, field, formatValues(removed), resource)}
+	}
+	return nil
+}
+
+var FieldNarrowingValidationRegex = FieldDiffRule{
+	Identifier: "field-narrowing-validation-regex",
+	Messages:   FieldNarrowingValidationRegexMessages,
+}
+
+func FieldNarrowingValidationRegexMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// only applies to mmv1 fields present before and after
+	if fieldDiff.ApiType.Old == nil || fieldDiff.ApiType.New == nil {
+		return nil
+	}
+	oldType, newType := fieldDiff.ApiType.Old, fieldDiff.ApiType.New
+	var messages []string
+	for _, v := range []struct {
+		subject         string
+		oldRegex, oldFn string
+		newRegex, newFn string
+	}{
+		{"Field", oldType.Validation.Regex, oldType.Validation.Function, newType.Validation.Regex, newType.Validation.Function},
+		{"Items of field", oldType.ItemValidation.Regex, oldType.ItemValidation.Function, newType.ItemValidation.Regex, newType.ItemValidation.Function},
+	} {
+		oldRegex := validationRegex(v.oldRegex, v.oldFn)
+		newRegex := validationRegex(v.newRegex, v.newFn)
+		// Only adding a regex to a field that accepted any value is provably
+		// narrowing. Whether one regex accepts a subset of another can't be
+		// determined in general, so other regex changes are left to reviewers.
+		// Replacing another validation function with a regex isn't flagged either.
+		if newRegex == "" || oldRegex != "" || v.oldFn != "" {
+			continue
+		}
+		tmpl := "%s `%s` added validation regex `%s` on `%s`"
+		messages = append(messages, fmt.Sprintf(tmpl, v.subject, field, newRegex, resource))
+	}
+	return messages
+}
+
+var FieldShrinkingValidationRange = FieldDiffRule{
+	Identifier: "field-shrinking-validation-range",
+	Messages:   FieldShrinkingValidationRangeMessages,
+}
+
+func FieldShrinkingValidationRangeMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// only applies to mmv1 fields present before and after
+	if fieldDiff.ApiType.Old == nil || fieldDiff.ApiType.New == nil {
+		return nil
+	}
+	oldType, newType := fieldDiff.ApiType.Old, fieldDiff.ApiType.New
+	var messages []string
+	for _, v := range []struct {
+		subject         string
+		oldRegex, oldFn string
+		newFn           string
+	}{
+		{"Field", oldType.Validation.Regex, oldType.Validation.Function, newType.Validation.Function},
+		{"Items of field", oldType.ItemValidation.Regex, oldType.ItemValidation.Function, newType.ItemValidation.Function},
+	} {
+		newRange, ok := parseValidationRange(v.newFn)
+		if !ok {
+			continue
+		}
+		tmpl := "%s `%s` allowed %s went from %s to %s on `%s`"
+		if v.oldFn == "" && v.oldRegex == "" {
+			messages = append(messages, fmt.Sprintf(tmpl, v.subject, field, newRange.kind, "unset", newRange, resource))
+			continue
+		}
+		oldRange, ok := parseValidationRange(v.oldFn)
+		if !ok || oldRange.kind != newRange.kind {
+			continue
+		}
+		if newRange.narrows(oldRange) {
+			messages = append(messages, fmt.Sprintf(tmpl, v.subject, field, newRange.kind, oldRange, newRange, resource))
+		}
+	}
+	return messages
+}
+
+var FieldBecomingForceNew = FieldDiffRule{
+	Identifier: "field-becoming-force-new",
+	Messages:   FieldBecomingForceNewMessages,
+}
+
+func FieldBecomingForceNewMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// ignore for added / removed fields
+	if fieldDiff.Old == nil || fieldDiff.New == nil {
+		return nil
+	}
+	// output-only fields can't be changed by users
+	if fieldDiff.New.Computed && !fieldDiff.New.Optional {
+		return nil
+	}
+	tmpl := "Field `%s` became ForceNew on `%s`; changing it will now recreate the resource"
+	if !fieldDiff.Old.ForceNew && fieldDiff.New.ForceNew {
+		return []string{fmt.Sprintf(tmpl, field, resource)}
+	}
+	return nil
+}
+
+var FieldRemovingSensitive = FieldDiffRule{
+	Identifier: "field-removing-sensitive",
+	Messages:   FieldRemovingSensitiveMessages,
+}
+
+func FieldRemovingSensitiveMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// ignore for added / removed fields
+	if fieldDiff.Old == nil || fieldDiff.New == nil {
+		return nil
+	}
+	tmpl := "Field `%s` is no longer Sensitive on `%s`"
+	if fieldDiff.Old.Sensitive && !fieldDiff.New.Sensitive {
+		return []string{fmt.Sprintf(tmpl, field, resource)}
+	}
+	return nil
+}
+
+var FieldChangingWriteOnly = FieldDiffRule{
+	Identifier: "field-changing-write-only",
+	Messages:   FieldChangingWriteOnlyMessages,
+}
+
+func FieldChangingWriteOnlyMessages(resource, field string, fieldDiff diff.FieldDiff, _ diff.ResourceDiffInterface) []string {
+	// only applies to mmv1 fields present before and after
+	if fieldDiff.ApiType.Old == nil || fieldDiff.ApiType.New == nil {
+		return nil
+	}
+	if !fieldDiff.ApiType.Old.WriteOnly && fieldDiff.ApiType.New.WriteOnly {
+		tmpl := "Field `%s` became write-only on `%s`"
+		return []string{fmt.Sprintf(tmpl, field, resource)}
+	}
+	if fieldDiff.ApiType.Old.WriteOnly && !fieldDiff.ApiType.New.WriteOnly {
+		tmpl := "Field `%s` is no longer write-only on `%s`"
+		return []string{fmt.Sprintf(tmpl, field, resource)}
+	}
+	return nil
+}
+
+var FieldAddingConflictsWith = FieldDiffRule{
+	Identifier: "field-adding-conflicts-with",
+	Messages:   FieldAddingConflictsWithMessages,
+}
+
+func FieldAddingConflictsWithMessages(resource, field string, fieldDiff diff.FieldDiff, resourceDiff diff.ResourceDiffInterface) []string {
+	// ignore for added / removed fields
+	if fieldDiff.Old == nil || fieldDiff.New == nil {
+		return nil
+	}
+	if len(fieldDiff.Old.ConflictsWith) > 0 || len(fieldDiff.New.ConflictsWith) == 0 {
+		return nil
+	}
+	// Conflicting with fields that didn't exist before can't break existing configs.
+	var conflicts []string
+	for _, other := range existingFields(diff.NewFieldSet(fieldDiff.New.ConflictsWith...), resourceDiff) {
+		// A conflict declared on both fields is reported once, by the first of the pair.
+		if other < field && addsConflictWith(other, field, resourceDiff) {
+			continue
+		}
+		conflicts = append(conflicts, other)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	tmpl := "Field `%s` now conflicts with %s on `%s`"
+	return []string{fmt.Sprintf(tmpl, field, formatValues(conflicts), resource)}
+}
+
+// addsConflictWith reports whether the existing field other newly conflicts with field.
+func addsConflictWith(other, field string, resourceDiff diff.ResourceDiffInterface) bool {
+	oldOther, newOther := resourceDiff.OldField(other), resourceDiff.NewField(other)
+	if oldOther == nil || newOther == nil || len(oldOther.ConflictsWith) > 0 {
+		return false
+	}
+	_, ok := diff.NewFieldSet(newOther.ConflictsWith...)[field]
+	return ok
+}
+
+var FieldOneOfGroupGainingMember = FieldDiffRule{
+	Identifier: "field-one-of-group-gaining-member",
+	Messages:   FieldOneOfGroupGainingMemberMessages,
+}
+
+func FieldOneOfGroupGainingMemberMessages(resource, field string, fieldDiff diff.FieldDiff, resourceDiff diff.ResourceDiffInterface) []string {
+	// ignore for added / removed fields
+	if fieldDiff.Old == nil || fieldDiff.New == nil {
+		return nil
+	}
+	var messages []string
+	// Creating an ExactlyOneOf group is covered by AddingExactlyOneOf.
+	if len(fieldDiff.Old.ExactlyOneOf) > 0 && len(fieldDiff.New.ExactlyOneOf) > 0 {
+		oldGroup := diff.NewFieldSet(append(fieldDiff.Old.ExactlyOneOf, field)...)
+		newGroup := diff.NewFieldSet(append(fieldDiff.New.ExactlyOneOf, field)...)
+		added := existingFields(newGroup.Difference(oldGroup), resourceDiff)
+		// Every member of the group sees the same change; only the first reports it.
+		if len(added) > 0 && firstExistingMember(field, oldGroup.Intersection(newGroup), resourceDiff) {
+			tmpl := "Field `%s` ExactlyOneOf group gained %s on `%s`"
+			messages = append(messages, fmt.Sprintf(tmpl, field, formatValues(added), resource))
+		}
+	}
+	// Adding members to an existing AtLeastOneOf group only relaxes it, but
+	// grouping previously unconstrained fields requires one of them to be set.
+	if len(fieldDiff.Old.AtLeastOneOf) == 0 && len(fieldDiff.New.AtLeastOneOf) > 0 && !fieldDiff.Old.Required {
+		newGroup := diff.NewFieldSet(fieldDiff.New.AtLeastOneOf...)
+		delete(newGroup, field)
+		var others []string
+		for f := range newGroup {
+			// Existing configs already satisfy the group if another member
+			// was required or the group existed before.
+			if old := resourceDiff.OldField(f); old != nil && (len(old.AtLeastOneOf) > 0 || old.Required) {
+				return messages
+			}
+			others = append(others, f)
+		}
+		if !firstExistingMember(field, diff.NewFieldSet(fieldDiff.New.AtLeastOneOf...), resourceDiff) {
+			return messages
+		}
+		slices.Sort(others)
+		tmpl := "Field `%s` joined a new AtLeastOneOf group with %s on `%s`"
+		messages = append(messages, fmt.Sprintf(tmpl, field, formatValues(others), resource))
+	}
+	return messages
+}
+
+// firstExistingMember reports whether field sorts before every other member
+// of the group that exists in the old schema.
+func firstExistingMember(field string, group diff.FieldSet, resourceDiff diff.ResourceDiffInterface) bool {
+	for _, f := range existingFields(group, resourceDiff) {
+		if f < field {
+			return false
+		}
+	}
+	return true
+}
+
+// existingFields returns the sorted fields of the set that exist in the old schema.
+func existingFields(fields diff.FieldSet, resourceDiff diff.ResourceDiffInterface) []string {
+	var existing []string
+	for f := range fields {
+		if resourceDiff.OldField(f) != nil {
+			existing = append(existing, f)
+		}
+	}
+	slices.Sort(existing)
+	return existing
+}
+
+func formatValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api"
+	mmv1resource "github.com/GoogleCloudPlatform/magic-modules/mmv1/api/resource"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -750,3 +752,472 @@ func (tc *fieldTestCase) check(rule FieldDiffRule, t *testing.T) {
 		}
 	}
 }
+
+type apiTypeTestCase struct {
+	name              string
+	oldType           *api.Type
+	newType           *api.Type
+	expectedViolation bool
+	messageRegex      string
+}
+
+func (tc *apiTypeTestCase) check(rule FieldDiffRule, t *testing.T) {
+	fieldDiff := diff.FieldDiff{
+		Old:     &schema.Schema{Type: schema.TypeString, Optional: true},
+		New:     &schema.Schema{Type: schema.TypeString, Optional: true},
+		ApiType: diff.ApiTypeDiff{Old: tc.oldType, New: tc.newType},
+	}
+	messages := rule.Messages("resource", "field", fieldDiff, nil)
+	violation := len(messages) > 0
+	if tc.expectedViolation != violation {
+		t.Errorf("Test `%s` failed: expected %v violations, got %v (%v)", tc.name, tc.expectedViolation, violation, messages)
+		return
+	}
+	for _, msg := range messages {
+		if match, _ := regexp.MatchString(tc.messageRegex, msg); !match {
+			t.Errorf("Test `%s` failed: message didn't match expected pattern '%s'. Got message: %s", tc.name, tc.messageRegex, msg)
+		}
+	}
+}
+
+func TestFieldRemovingEnumValue(t *testing.T) {
+	for _, tc := range FieldRemovingEnumValueTestCases {
+		tc.check(FieldRemovingEnumValue, t)
+	}
+}
+
+var FieldRemovingEnumValueTestCases = []apiTypeTestCase{
+	{
+		name:              "control",
+		oldType:           &api.Type{Type: "Enum", EnumValues: []string{"A", "B"}},
+		newType:           &api.Type{Type: "Enum", EnumValues: []string{"A", "B"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "adding enum value",
+		oldType:           &api.Type{Type: "Enum", EnumValues: []string{"A", "B"}},
+		newType:           &api.Type{Type: "Enum", EnumValues: []string{"A", "B", "C"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "removing enum value",
+		oldType:           &api.Type{Type: "Enum", EnumValues: []string{"A", "B", "C"}},
+		newType:           &api.Type{Type: "Enum", EnumValues: []string{"A"}},
+		expectedViolation: true,
+		messageRegex:      "no longer accepts `B`, `C`",
+	},
+	{
+		name:              "string to enum",
+		oldType:           &api.Type{Type: "String"},
+		newType:           &api.Type{Type: "Enum", EnumValues: []string{"A"}},
+		expectedViolation: true,
+		messageRegex:      "from accepting any value to accepting only `A`",
+	},
+	{
+		name:              "enum to string",
+		oldType:           &api.Type{Type: "Enum", EnumValues: []string{"A"}},
+		newType:           &api.Type{Type: "String"},
+		expectedViolation: false,
+	},
+	{
+		name: "removing enum value from array items",
+		oldType: &api.Type{
+			Type:     "Array",
+			ItemType: &api.Type{Type: "Enum", EnumValues: []string{"A", "B"}},
+		},
+		newType: &api.Type{
+			Type:     "Array",
+			ItemType: &api.Type{Type: "Enum", EnumValues: []string{"B"}},
+		},
+		expectedViolation: true,
+		messageRegex:      "no longer accepts `A`",
+	},
+	{
+		name:              "not an mmv1 field",
+		oldType:           nil,
+		newType:           nil,
+		expectedViolation: false,
+	},
+}
+
+func TestFieldNarrowingValidationRegex(t *testing.T) {
+	for _, tc := range FieldNarrowingValidationRegexTestCases {
+		tc.check(FieldNarrowingValidationRegex, t)
+	}
+}
+
+var FieldNarrowingValidationRegexTestCases = []apiTypeTestCase{
+	{
+		name:              "control",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "adding regex",
+		oldType:           &api.Type{},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		expectedViolation: true,
+		messageRegex:      "added validation regex",
+	},
+	{
+		name:              "adding regex in verify.ValidateRegexp",
+		oldType:           &api.Type{},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "verify.ValidateRegexp(`^[a-z]+$`)"}},
+		expectedViolation: true,
+		messageRegex:      "^Field `field` added validation regex `\\^\\[a-z\\]\\+\\$`",
+	},
+	{
+		name:              "narrowing regex",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z0-9]+$"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "loosening regex",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z0-9]+$"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "changing regex in verify.ValidateRegexp",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "verify.ValidateRegexp(`^[a-z0-9]+$`)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "verify.ValidateRegexp(`^[a-z]+$`)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "moving regex to verify.ValidateRegexp",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "verify.ValidateRegexp(`^[a-z]+$`)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "removing regex",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		newType:           &api.Type{},
+		expectedViolation: false,
+	},
+	{
+		name:              "adding item regex",
+		oldType:           &api.Type{},
+		newType:           &api.Type{ItemValidation: mmv1resource.Validation{Regex: "^[a-z]+$"}},
+		expectedViolation: true,
+		messageRegex:      "^Items of field `field`",
+	},
+}
+
+func TestFieldShrinkingValidationRange(t *testing.T) {
+	for _, tc := range FieldShrinkingValidationRangeTestCases {
+		tc.check(FieldShrinkingValidationRange, t)
+	}
+}
+
+var FieldShrinkingValidationRangeTestCases = []apiTypeTestCase{
+	{
+		name:              "control",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "growing range",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(1, 100)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 200)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "raising min",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(1, 100)"}},
+		expectedViolation: true,
+		messageRegex:      "allowed range went from \\[0, 100\\] to \\[1, 100\\]",
+	},
+	{
+		name:              "lowering max length",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.StringLenBetween(0, 1024)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.StringLenBetween(0, 63)"}},
+		expectedViolation: true,
+		messageRegex:      "allowed length went from \\[0, 1024\\] to \\[0, 63\\]",
+	},
+	{
+		name:              "at least to between",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntAtLeast(1)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(1, 10)"}},
+		expectedViolation: true,
+		messageRegex:      "from \\[1, inf\\] to \\[1, 10\\]",
+	},
+	{
+		name:              "between to at least",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(1, 10)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntAtLeast(1)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "adding range",
+		oldType:           &api.Type{},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.FloatBetween(0.5, 1)"}},
+		expectedViolation: true,
+		messageRegex:      "from unset to \\[0.5, 1\\]",
+	},
+	{
+		name:              "replacing regex with range",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Regex: "^[0-9]+$"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.StringLenBetween(1, 10)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "changing range kind",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.StringLenBetween(0, 10)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "non-literal bounds",
+		oldType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		newType:           &api.Type{Validation: mmv1resource.Validation{Function: "validation.IntBetween(0, maxPort)"}},
+		expectedViolation: false,
+	},
+	{
+		name:              "shrinking item range",
+		oldType:           &api.Type{ItemValidation: mmv1resource.Validation{Function: "validation.IntBetween(0, 100)"}},
+		newType:           &api.Type{ItemValidation: mmv1resource.Validation{Function: "validation.IntBetween(0, 10)"}},
+		expectedViolation: true,
+		messageRegex:      "^Items of field `field`",
+	},
+}
+
+func TestFieldBecomingForceNew(t *testing.T) {
+	for _, tc := range FieldBecomingForceNewTestCases {
+		tc.check(FieldBecomingForceNew, t)
+	}
+}
+
+var FieldBecomingForceNewTestCases = []fieldTestCase{
+	{
+		name:              "control",
+		oldField:          &schema.Schema{Optional: true, ForceNew: true},
+		newField:          &schema.Schema{Optional: true, ForceNew: true},
+		expectedViolation: false,
+	},
+	{
+		name:              "becoming force new",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ForceNew: true},
+		expectedViolation: true,
+		messageRegex:      "became ForceNew",
+	},
+	{
+		name:              "removing force new",
+		oldField:          &schema.Schema{Optional: true, ForceNew: true},
+		newField:          &schema.Schema{Optional: true},
+		expectedViolation: false,
+	},
+	{
+		name:              "output-only field becoming force new",
+		oldField:          &schema.Schema{Computed: true},
+		newField:          &schema.Schema{Computed: true, ForceNew: true},
+		expectedViolation: false,
+	},
+	{
+		name:              "field added as force new",
+		oldField:          nil,
+		newField:          &schema.Schema{Optional: true, ForceNew: true},
+		expectedViolation: false,
+	},
+}
+
+func TestFieldRemovingSensitive(t *testing.T) {
+	for _, tc := range FieldRemovingSensitiveTestCases {
+		tc.check(FieldRemovingSensitive, t)
+	}
+}
+
+var FieldRemovingSensitiveTestCases = []fieldTestCase{
+	{
+		name:              "control",
+		oldField:          &schema.Schema{Optional: true, Sensitive: true},
+		newField:          &schema.Schema{Optional: true, Sensitive: true},
+		expectedViolation: false,
+	},
+	{
+		name:              "removing sensitive",
+		oldField:          &schema.Schema{Optional: true, Sensitive: true},
+		newField:          &schema.Schema{Optional: true},
+		expectedViolation: true,
+		messageRegex:      "no longer Sensitive",
+	},
+	{
+		name:              "adding sensitive",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, Sensitive: true},
+		expectedViolation: false,
+	},
+}
+
+func TestFieldChangingWriteOnly(t *testing.T) {
+	for _, tc := range FieldChangingWriteOnlyTestCases {
+		tc.check(FieldChangingWriteOnly, t)
+	}
+}
+
+var FieldChangingWriteOnlyTestCases = []apiTypeTestCase{
+	{
+		name:              "control",
+		oldType:           &api.Type{WriteOnly: true},
+		newType:           &api.Type{WriteOnly: true},
+		expectedViolation: false,
+	},
+	{
+		name:              "becoming write-only",
+		oldType:           &api.Type{},
+		newType:           &api.Type{WriteOnly: true},
+		expectedViolation: true,
+		messageRegex:      "became write-only",
+	},
+	{
+		name:              "no longer write-only",
+		oldType:           &api.Type{WriteOnly: true},
+		newType:           &api.Type{},
+		expectedViolation: true,
+		messageRegex:      "no longer write-only",
+	},
+	{
+		name:              "not an mmv1 field",
+		expectedViolation: false,
+	},
+}
+
+func TestFieldAddingConflictsWith(t *testing.T) {
+	for _, tc := range FieldAddingConflictsWithTestCases {
+		tc.check(FieldAddingConflictsWith, t)
+	}
+}
+
+var FieldAddingConflictsWithTestCases = []fieldTestCase{
+	{
+		name:              "control",
+		oldField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other"}},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"other": {}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "adding conflicts with existing field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"parent.0.other"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"parent.other": {}}},
+		expectedViolation: true,
+		messageRegex:      "now conflicts with `parent.other`",
+	},
+	{
+		name:              "adding conflicts with new field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other"}},
+		resourceDiff:      MockSchemaDiff{},
+		expectedViolation: false,
+	},
+	{
+		name:              "symmetric conflict reported by the first field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"other": {Optional: true}}, newFields: map[string]*schema.Schema{"other": {Optional: true, ConflictsWith: []string{"field"}}}},
+		expectedViolation: true,
+		messageRegex:      "`field` now conflicts with `other`",
+	},
+	{
+		name:              "symmetric conflict left to the first field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"another"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"another": {Optional: true}}, newFields: map[string]*schema.Schema{"another": {Optional: true, ConflictsWith: []string{"field"}}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "one-sided conflict reported by the declaring field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"another"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"another": {Optional: true}}, newFields: map[string]*schema.Schema{"another": {Optional: true}}},
+		expectedViolation: true,
+		messageRegex:      "`field` now conflicts with `another`",
+	},
+	{
+		name:              "adding to existing conflicts",
+		oldField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other"}},
+		newField:          &schema.Schema{Optional: true, ConflictsWith: []string{"other", "another"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"other": {}, "another": {}}},
+		expectedViolation: false,
+	},
+}
+
+func TestFieldOneOfGroupGainingMember(t *testing.T) {
+	for _, tc := range FieldOneOfGroupGainingMemberTestCases {
+		tc.check(FieldOneOfGroupGainingMember, t)
+	}
+}
+
+var FieldOneOfGroupGainingMemberTestCases = []fieldTestCase{
+	{
+		name:              "control",
+		oldField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a"}},
+		newField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "exactly one of gaining existing member",
+		oldField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "x"}},
+		newField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "x", "y"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"x": {}, "y": {}}},
+		expectedViolation: true,
+		messageRegex:      "ExactlyOneOf group gained `y`",
+	},
+	{
+		name:              "exactly one of gaining member left to first member",
+		oldField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a"}},
+		newField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a", "b"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {}, "b": {}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "exactly one of gaining new member",
+		oldField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a"}},
+		newField:          &schema.Schema{Optional: true, ExactlyOneOf: []string{"field", "a", "b"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "at least one of formed on existing fields",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "x"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"x": {Optional: true}}},
+		expectedViolation: true,
+		messageRegex:      "joined a new AtLeastOneOf group with `x`",
+	},
+	{
+		name:              "at least one of formed left to first member",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "a"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {Optional: true}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "at least one of formed with new field",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "a"}},
+		resourceDiff:      MockSchemaDiff{},
+		expectedViolation: true,
+	},
+	{
+		name:              "joining existing at least one of group",
+		oldField:          &schema.Schema{Optional: true},
+		newField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "a", "b"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {Optional: true, AtLeastOneOf: []string{"a", "b"}}, "b": {Optional: true, AtLeastOneOf: []string{"a", "b"}}}},
+		expectedViolation: false,
+	},
+	{
+		name:              "at least one of gaining member",
+		oldField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "a"}},
+		newField:          &schema.Schema{Optional: true, AtLeastOneOf: []string{"field", "a", "b"}},
+		resourceDiff:      MockSchemaDiff{oldFields: map[string]*schema.Schema{"a": {}, "b": {}}},
+		expectedViolation: false,
+	},
+}
@@ -1,9 +1,13 @@
 package breaking_changes
 
+import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
 // MockSchemaDiff implements the diff.SchemaDiff interface for testing
 type MockSchemaDiff struct {
 	isNewResource        bool
-	fieldsInNewStructure map[string]bool // Maps field names to whether they're in a new structure
+	fieldsInNewStructure map[string]bool           // Maps field names to whether they're in a new structure
+	oldFields            map[string]*schema.Schema // Maps field names to their old schema
+	newFields            map[string]*schema.Schema // Maps field names to their new schema
 }
 
 func (sd MockSchemaDiff) IsNewResource() bool {
@@ -14,6 +18,14 @@ func (sd MockSchemaDiff) IsFieldInNewNestedStructure(field string) bool {
 	return sd.fieldsInNewStructure[field]
 }
 
+func (sd MockSchemaDiff) OldField(field string) *schema.Schema {
+	return sd.oldFields[field]
+}
+
+func (sd MockSchemaDiff) NewField(field string) *schema.Schema {
+	return sd.newFields[field]
+}
+
 // Create mock schema diffs for testing
 var (
 	// Mock for existing resource (not new, field not in new structure)
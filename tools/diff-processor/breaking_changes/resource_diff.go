@@ -2,6 +2,8 @@ package breaking_changes
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
 )
@@ -13,7 +15,14 @@ type ResourceDiffRule struct {
 }
 
 // ResourceDiffRules is a list of all ResourceDiff rules
-var ResourceDiffRules = []ResourceDiffRule{RemovingAField, AddingExactlyOneOf}
+var ResourceDiffRules = []ResourceDiffRule{
+	RemovingAField,
+	AddingExactlyOneOf,
+	ChangingResourceIdFormat,
+	RemovingImportFormat,
+	ShrinkingDefaultTimeout,
+	ChangingSchemaVersionWithoutUpgrade,
+}
 
 var RemovingAField = ResourceDiffRule{
 	Identifier: "resource-schema-field-removal-or-rename",
@@ -78,3 +87,107 @@ func AddingExactlyOneOfMessages(resource string, resourceDiff diff.ResourceDiff)
 	}
 	return messages
 }
+
+var ChangingResourceIdFormat = ResourceDiffRule{
+	Identifier: "resource-id",
+	Messages:   ChangingResourceIdFormatMessages,
+}
+
+func ChangingResourceIdFormatMessages(resource string, resourceDiff diff.ResourceDiff) []string {
+	oldFormat, newFormat := resourceDiff.Metadata.Old.IdFormat, resourceDiff.Metadata.New.IdFormat
+	if oldFormat == "" || newFormat == "" || oldFormat == newFormat {
+		return nil
+	}
+	tmpl := "Resource `%s` ID format changed from `%s` to `%s`"
+	return []string{fmt.Sprintf(tmpl, resource, oldFormat, newFormat)}
+}
+
+var RemovingImportFormat = ResourceDiffRule{
+	Identifier: "resource-import-format",
+	Messages:   RemovingImportFormatMessages,
+}
+
+func RemovingImportFormatMessages(resource string, resourceDiff diff.ResourceDiff) []string {
+	oldFormats, newFormats := resourceDiff.Metadata.Old.ImportFormats, resourceDiff.Metadata.New.ImportFormats
+	// Resources without generated import formats (custom or no import) can't be compared.
+	if len(oldFormats) == 0 || len(newFormats) == 0 {
+		return nil
+	}
+	tmpl := "Import ID format `%s` is no longer accepted by resource `%s`"
+	var messages []string
+	for _, format := range oldFormats {
+		if !importFormatAccepted(format, newFormats) {
+			messages = append(messages, fmt.Sprintf(tmpl, format, resource))
+		}
+	}
+	return messages
+}
+
+var ShrinkingDefaultTimeout = ResourceDiffRule{
+	Identifier: "resource-shrinking-default-timeout",
+	Messages:   ShrinkingDefaultTimeoutMessages,
+}
+
+func ShrinkingDefaultTimeoutMessages(resource string, resourceDiff diff.ResourceDiff) []string {
+	oldTimeouts, newTimeouts := resourceDiff.Metadata.Old.Timeouts, resourceDiff.Metadata.New.Timeouts
+	if oldTimeouts == nil {
+		return nil
+	}
+	if newTimeouts == nil {
+		return []string{fmt.Sprintf("Resource `%s` no longer supports the `timeouts` block", resource)}
+	}
+	var messages []string
+	for _, t := range []struct {
+		name     string
+		old, new *time.Duration
+	}{
+		{"create", oldTimeouts.Create, newTimeouts.Create},
+		{"read", oldTimeouts.Read, newTimeouts.Read},
+		{"update", oldTimeouts.Update, newTimeouts.Update},
+		{"delete", oldTimeouts.Delete, newTimeouts.Delete},
+		{"default", oldTimeouts.Default, newTimeouts.Default},
+	} {
+		if t.old == nil {
+			continue
+		}
+		if t.new == nil {
+			tmpl := "Resource `%s` no longer supports the `%s` timeout"
+			messages = append(messages, fmt.Sprintf(tmpl, resource, t.name))
+			continue
+		}
+		if *t.new < *t.old {
+			tmpl := "Resource `%s` default `%s` timeout went from %s to %s"
+			messages = append(messages, fmt.Sprintf(tmpl, resource, t.name, *t.old, *t.new))
+		}
+	}
+	return messages
+}
+
+var ChangingSchemaVersionWithoutUpgrade = ResourceDiffRule{
+	Identifier: "resource-schema-version-without-state-upgrade",
+	Messages:   ChangingSchemaVersionWithoutUpgradeMessages,
+}
+
+func ChangingSchemaVersionWithoutUpgradeMessages(resource string, resourceDiff diff.ResourceDiff) []string {
+	oldMetadata, newMetadata := resourceDiff.Metadata.Old, resourceDiff.Metadata.New
+	if newMetadata.SchemaVersion < oldMetadata.SchemaVersion {
+		tmpl := "Resource `%s` schema version went down from %d to %d"
+		return []string{fmt.Sprintf(tmpl, resource, oldMetadata.SchemaVersion, newMetadata.SchemaVersion)}
+	}
+	if newMetadata.SchemaVersion == oldMetadata.SchemaVersion || newMetadata.HasMigrateState {
+		return nil
+	}
+	// State written at each version between the old and new schema version
+	// needs an upgrader to reach the new version.
+	var missing []string
+	for v := oldMetadata.SchemaVersion; v < newMetadata.SchemaVersion; v++ {
+		if !slices.Contains(newMetadata.StateUpgraderVersions, v) {
+			missing = append(missing, fmt.Sprint(v))
+		}
+	}
+	if len(missing) > 0 {
+		tmpl := "Resource `%s` schema version went from %d to %d without a state upgrader for version %s"
+		return []string{fmt.Sprintf(tmpl, resource, oldMetadata.SchemaVersion, newMetadata.SchemaVersion, formatValues(missing))}
+	}
+	return nil
+}
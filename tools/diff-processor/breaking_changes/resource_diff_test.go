@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -427,3 +428,167 @@ var resourceSchemaRule_AddingExactlyOneOf_TestCases = []resourceSchemaTestCase{
 		},
 	},
 }
+
+type resourceMetadataTestCase struct {
+	name         string
+	old          diff.ResourceMetadata
+	new          diff.ResourceMetadata
+	wantMessages []string // substrings of the expected messages, in sorted message order
+}
+
+func (tc resourceMetadataTestCase) check(rule ResourceDiffRule, t *testing.T) {
+	gotMessages := rule.Messages("resource", diff.ResourceDiff{
+		Metadata: diff.ResourceMetadataDiff{Old: tc.old, New: tc.new},
+	})
+	if len(gotMessages) != len(tc.wantMessages) {
+		t.Errorf("%s.Messages(%v) got %d messages %v; want %d", rule.Identifier, tc.name, len(gotMessages), gotMessages, len(tc.wantMessages))
+		return
+	}
+	sort.Strings(gotMessages)
+	for i, want := range tc.wantMessages {
+		if !strings.Contains(gotMessages[i], want) {
+			t.Errorf("%s.Messages(%v) got message %q; want %q", rule.Identifier, tc.name, gotMessages[i], want)
+		}
+	}
+}
+
+func TestChangingResourceIdFormatMessages(t *testing.T) {
+	for _, tc := range []resourceMetadataTestCase{
+		{
+			name: "control",
+			old:  diff.ResourceMetadata{IdFormat: "projects/{{project}}/topics/{{name}}"},
+			new:  diff.ResourceMetadata{IdFormat: "projects/{{project}}/topics/{{name}}"},
+		},
+		{
+			name: "handwritten resource",
+			old:  diff.ResourceMetadata{},
+			new:  diff.ResourceMetadata{},
+		},
+		{
+			name:         "changing id format",
+			old:          diff.ResourceMetadata{IdFormat: "{{project}}/{{name}}"},
+			new:          diff.ResourceMetadata{IdFormat: "projects/{{project}}/topics/{{name}}"},
+			wantMessages: []string{"ID format changed from `{{project}}/{{name}}` to `projects/{{project}}/topics/{{name}}`"},
+		},
+	} {
+		tc.check(ChangingResourceIdFormat, t)
+	}
+}
+
+func TestRemovingImportFormatMessages(t *testing.T) {
+	defaultFormats := []string{"projects/{{project}}/topics/{{name}}", "{{project}}/{{name}}", "{{name}}"}
+	for _, tc := range []resourceMetadataTestCase{
+		{
+			name: "control",
+			old:  diff.ResourceMetadata{ImportFormats: defaultFormats},
+			new:  diff.ResourceMetadata{ImportFormats: defaultFormats},
+		},
+		{
+			name: "adding a format",
+			old:  diff.ResourceMetadata{ImportFormats: defaultFormats},
+			new:  diff.ResourceMetadata{ImportFormats: append([]string{"topics/{{project}}/{{name}}"}, defaultFormats...)},
+		},
+		{
+			name:         "removing a format",
+			old:          diff.ResourceMetadata{ImportFormats: defaultFormats},
+			new:          diff.ResourceMetadata{ImportFormats: defaultFormats[:2]},
+			wantMessages: []string{"`{{name}}` is no longer accepted"},
+		},
+		{
+			name: "renaming a format variable",
+			old:  diff.ResourceMetadata{ImportFormats: []string{"projects/{{project}}/topics/{{name}}"}},
+			new:  diff.ResourceMetadata{ImportFormats: []string{"projects/{{project}}/topics/{{topic}}"}},
+		},
+		{
+			name: "format covered by a url-encoded field",
+			old:  diff.ResourceMetadata{ImportFormats: []string{"{{project}}/{{name}}"}},
+			new:  diff.ResourceMetadata{ImportFormats: []string{"{{%name}}"}},
+		},
+		{
+			name:         "url-encoded field no longer accepting slashes",
+			old:          diff.ResourceMetadata{ImportFormats: []string{"{{project}}/{{%name}}"}},
+			new:          diff.ResourceMetadata{ImportFormats: []string{"{{project}}/{{name}}"}},
+			wantMessages: []string{"`{{project}}/{{%name}}` is no longer accepted"},
+		},
+		{
+			name: "custom import",
+			old:  diff.ResourceMetadata{ImportFormats: defaultFormats},
+			new:  diff.ResourceMetadata{},
+		},
+	} {
+		tc.check(RemovingImportFormat, t)
+	}
+}
+
+func TestShrinkingDefaultTimeoutMessages(t *testing.T) {
+	minutes := func(m int) *time.Duration {
+		d := time.Duration(m) * time.Minute
+		return &d
+	}
+	for _, tc := range []resourceMetadataTestCase{
+		{
+			name: "control",
+			old:  diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20)}},
+			new:  diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20)}},
+		},
+		{
+			name: "growing timeout",
+			old:  diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20)}},
+			new:  diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(30), Update: minutes(20)}},
+		},
+		{
+			name:         "shrinking timeout",
+			old:          diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20), Delete: minutes(20)}},
+			new:          diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(10), Delete: minutes(20)}},
+			wantMessages: []string{"default `create` timeout went from 20m0s to 10m0s"},
+		},
+		{
+			name:         "removing timeout",
+			old:          diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20), Delete: minutes(20)}},
+			new:          diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20)}},
+			wantMessages: []string{"no longer supports the `delete` timeout"},
+		},
+		{
+			name:         "removing timeouts block",
+			old:          diff.ResourceMetadata{Timeouts: &schema.ResourceTimeout{Create: minutes(20)}},
+			new:          diff.ResourceMetadata{},
+			wantMessages: []string{"no longer supports the `timeouts` block"},
+		},
+	} {
+		tc.check(ShrinkingDefaultTimeout, t)
+	}
+}
+
+func TestChangingSchemaVersionWithoutUpgradeMessages(t *testing.T) {
+	for _, tc := range []resourceMetadataTestCase{
+		{
+			name: "control",
+			old:  diff.ResourceMetadata{SchemaVersion: 1, StateUpgraderVersions: []int{0}},
+			new:  diff.ResourceMetadata{SchemaVersion: 1, StateUpgraderVersions: []int{0}},
+		},
+		{
+			name: "bumping version with upgrader",
+			old:  diff.ResourceMetadata{SchemaVersion: 1, StateUpgraderVersions: []int{0}},
+			new:  diff.ResourceMetadata{SchemaVersion: 2, StateUpgraderVersions: []int{0, 1}},
+		},
+		{
+			name: "bumping version with MigrateState",
+			old:  diff.ResourceMetadata{},
+			new:  diff.ResourceMetadata{SchemaVersion: 1, HasMigrateState: true},
+		},
+		{
+			name:         "bumping version without upgrader",
+			old:          diff.ResourceMetadata{SchemaVersion: 1, StateUpgraderVersions: []int{0}},
+			new:          diff.ResourceMetadata{SchemaVersion: 3, StateUpgraderVersions: []int{0, 2}},
+			wantMessages: []string{"without a state upgrader for version `1`"},
+		},
+		{
+			name:         "lowering version",
+			old:          diff.ResourceMetadata{SchemaVersion: 1, StateUpgraderVersions: []int{0}},
+			new:          diff.ResourceMetadata{},
+			wantMessages: []string{"schema version went down from 1 to 0"},
+		},
+	} {
+		tc.check(ChangingSchemaVersionWithoutUpgrade, t)
+	}
+}
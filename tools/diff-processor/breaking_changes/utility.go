@@ -1,6 +1,13 @@
 package breaking_changes
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/google"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -23,3 +30,106 @@ func getValueType(valueType schema.ValueType) string {
 	}
 	return "TypeUndefined"
 }
+
+var validateRegexpFunction = regexp.MustCompile("^verify\\.ValidateRegexp\\(`(.*)`\\)$")
+
+// validationRegex returns the regex of an mmv1 validation, which is either
+// set directly or passed to verify.ValidateRegexp.
+func validationRegex(regex, function string) string {
+	if regex != "" {
+		return regex
+	}
+	if m := validateRegexpFunction.FindStringSubmatch(strings.TrimSpace(function)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var rangeFunction = regexp.MustCompile(`^validation\.(IntBetween|FloatBetween|StringLenBetween|IntAtLeast|IntAtMost)\(([^,()]+)(?:,([^,()]+))?\)$`)
+
+// validationRange is the range of values (or of string lengths) allowed by an
+// mmv1 validation function. A nil bound is unbounded.
+type validationRange struct {
+	kind string
+	min  *float64
+	max  *float64
+}
+
+// parseValidationRange parses range validation functions like
+// `validation.IntBetween(0, 100)`. Functions with non-literal arguments are
+// not parsed.
+func parseValidationRange(function string) (validationRange, bool) {
+	m := rangeFunction.FindStringSubmatch(strings.TrimSpace(function))
+	if m == nil {
+		return validationRange{}, false
+	}
+	first, err := strconv.ParseFloat(strings.TrimSpace(m[2]), 64)
+	if err != nil {
+		return validationRange{}, false
+	}
+	r := validationRange{kind: "range"}
+	switch m[1] {
+	case "IntAtLeast":
+		r.min = &first
+	case "IntAtMost":
+		r.max = &first
+	default:
+		second, err := strconv.ParseFloat(strings.TrimSpace(m[3]), 64)
+		if err != nil {
+			return validationRange{}, false
+		}
+		r.min, r.max = &first, &second
+		if m[1] == "StringLenBetween" {
+			r.kind = "length"
+		}
+	}
+	return r, true
+}
+
+// narrows reports whether r rejects values that old allowed.
+func (r validationRange) narrows(old validationRange) bool {
+	if r.min != nil && (old.min == nil || *r.min > *old.min) {
+		return true
+	}
+	if r.max != nil && (old.max == nil || *r.max < *old.max) {
+		return true
+	}
+	return false
+}
+
+func (r validationRange) String() string {
+	bound := func(b *float64, unbounded string) string {
+		if b == nil {
+			return unbounded
+		}
+		return strconv.FormatFloat(*b, 'f', -1, 64)
+	}
+	return fmt.Sprintf("[%s, %s]", bound(r.min, "-inf"), bound(r.max, "inf"))
+}
+
+var (
+	urlEncodedFieldMarker = regexp.MustCompile(`\{\{%([[:word:]]+)\}\}`)
+	fieldMarker           = regexp.MustCompile(`\{\{([[:word:]]+)\}\}`)
+)
+
+// importFormatAccepted reports whether an ID written in the given import
+// format is still parsed by one of the import formats in formats, which is
+// how the generated importers match IDs.
+func importFormatAccepted(format string, formats []string) bool {
+	if slices.Contains(formats, format) {
+		return true
+	}
+	// Build a sample ID for the format. URL-encoded values may contain slashes.
+	id := urlEncodedFieldMarker.ReplaceAllString(format, "$1/sample")
+	id = fieldMarker.ReplaceAllString(id, "$1")
+	for _, f := range formats {
+		re, err := regexp.Compile("^" + google.Format2Regex(f) + "$")
+		if err != nil {
+			continue
+		}
+		if re.MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
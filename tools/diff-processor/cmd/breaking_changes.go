@@ -10,15 +10,23 @@ import (
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/breaking_changes"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
 	"github.com/spf13/cobra"
 )
 
 const breakingChangesDesc = `Check for breaking changes between the new / old Terraform provider versions.`
 
 type breakingChangesOptions struct {
-	rootOptions       *rootOptions
-	computeSchemaDiff func() diff.SchemaDiff
-	stdout            io.Writer
+	rootOptions                   *rootOptions
+	computeSchemaDiff             func() diff.SchemaDiff
+	computeSchemaDiffWithApiTypes func(oldApiTypes, newApiTypes diff.ApiTypes) diff.SchemaDiff
+	oldProductsPath               string
+	newProductsPath               string
+	oldProviderPath               string
+	newProviderPath               string
+	format                        string
+	loadSourceFiles               func() (map[string]string, error)
+	stdout                        io.Writer
 }
 
 func newBreakingChangesCmd(rootOptions *rootOptions) *cobra.Command {
@@ -27,7 +35,14 @@ func newBreakingChangesCmd(rootOptions *rootOptions) *cobra.Command {
 		computeSchemaDiff: func() diff.SchemaDiff {
 			return schemaDiff
 		},
-		stdout: os.Stdout,
+		computeSchemaDiffWithApiTypes: computeSchemaDiffWithApiTypes,
+		stdout:                        os.Stdout,
+	}
+	o.loadSourceFiles = func() (map[string]string, error) {
+		if o.oldProviderPath == "" && o.newProviderPath == "" {
+			return loadSourceFiles("new", "old")
+		}
+		return loadSourceFiles(o.newProviderPath, o.oldProviderPath)
 	}
 	cmd := &cobra.Command{
 		Use:   "breaking-changes",
@@ -37,16 +52,86 @@ func newBreakingChangesCmd(rootOptions *rootOptions) *cobra.Command {
 			return o.run()
 		},
 	}
+	cmd.Flags().StringVar(&o.oldProductsPath, "old-products", "", "Path to the mmv1/products directory of the old version. Enables validation checks for mmv1 fields when set together with --new-products")
+	cmd.Flags().StringVar(&o.newProductsPath, "new-products", "", "Path to the mmv1/products directory of the new version")
+	cmd.Flags().StringVar(&o.oldProviderPath, "old-provider", "", "Path to the old provider source. Enables id and import format checks when set together with --new-provider")
+	cmd.Flags().StringVar(&o.newProviderPath, "new-provider", "", "Path to the new provider source")
+	addFormatFlag(cmd, &o.format)
 	return cmd
 }
 func (o *breakingChangesOptions) run() error {
-	schemaDiff := o.computeSchemaDiff()
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
+	schemaDiff, err := o.schemaDiff()
+	if err != nil {
+		return err
+	}
 	breakingChanges := breaking_changes.ComputeBreakingChanges(schemaDiff)
 	sort.Slice(breakingChanges, func(i, j int) bool {
 		return breakingChanges[i].Message < breakingChanges[j].Message
 	})
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "breaking-changes", breakingChangeFindings(breakingChanges), o.loadSourceFiles)
+	}
 	if err := json.NewEncoder(o.stdout).Encode(breakingChanges); err != nil {
 		return fmt.Errorf("error encoding json: %w", err)
 	}
 	return nil
 }
+
+func breakingChangeFindings(breakingChanges []breaking_changes.BreakingChange) []report.Finding {
+	var findings []report.Finding
+	for _, breakingChange := range breakingChanges {
+		findings = append(findings, report.Finding{
+			Resource: breakingChange.Resource,
+			Field:    breakingChange.Field,
+			RuleID:   breakingChange.RuleName,
+			Severity: report.SeverityError,
+			Message:  breakingChange.Message,
+			HelpURI:  breakingChange.DocumentationReference,
+		})
+	}
+	return findings
+}
+
+func (o *breakingChangesOptions) schemaDiff() (diff.SchemaDiff, error) {
+	schemaDiff, err := o.schemaDiffWithApiTypes()
+	if err != nil {
+		return nil, err
+	}
+	if o.oldProviderPath == "" && o.newProviderPath == "" {
+		return schemaDiff, nil
+	}
+	if o.oldProviderPath == "" || o.newProviderPath == "" {
+		return nil, fmt.Errorf("--old-provider and --new-provider must be set together")
+	}
+	oldIdFormats, err := diff.LoadIdFormats(o.oldProviderPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading old resource metadata: %w", err)
+	}
+	newIdFormats, err := diff.LoadIdFormats(o.newProviderPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading new resource metadata: %w", err)
+	}
+	diff.AddIdFormats(schemaDiff, oldIdFormats, newIdFormats)
+	return schemaDiff, nil
+}
+
+func (o *breakingChangesOptions) schemaDiffWithApiTypes() (diff.SchemaDiff, error) {
+	if o.oldProductsPath == "" && o.newProductsPath == "" {
+		return o.computeSchemaDiff(), nil
+	}
+	if o.oldProductsPath == "" || o.newProductsPath == "" {
+		return nil, fmt.Errorf("--old-products and --new-products must be set together")
+	}
+	oldApiTypes, err := diff.LoadApiTypes(o.oldProductsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading old mmv1 products: %w", err)
+	}
+	newApiTypes, err := diff.LoadApiTypes(o.newProductsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading new mmv1 products: %w", err)
+	}
+	return o.computeSchemaDiffWithApiTypes(oldApiTypes, newApiTypes), nil
+}
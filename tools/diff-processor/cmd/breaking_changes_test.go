@@ -7,6 +7,8 @@ import (
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/breaking_changes"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -105,3 +107,83 @@ func TestBreakingChangesCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestBreakingChangesCmdFormat(t *testing.T) {
+	oldResourceMap := map[string]*schema.Resource{
+		"google_x": {
+			Schema: map[string]*schema.Schema{
+				"field_a": {Description: "beep", Optional: true},
+			},
+		},
+	}
+	newResourceMap := map[string]*schema.Resource{
+		"google_x": {
+			Schema: map[string]*schema.Schema{
+				"field_a": {Description: "beep", Required: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	o := breakingChangesOptions{
+		computeSchemaDiff: func() diff.SchemaDiff {
+			return diff.ComputeSchemaDiff(oldResourceMap, newResourceMap)
+		},
+		format: report.FormatJSON,
+		loadSourceFiles: func() (map[string]string, error) {
+			return map[string]string{"google_x": "mmv1/products/x/X.yaml"}, nil
+		},
+		stdout: &buf,
+	}
+	if err := o.run(); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+
+	var got report.Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshall output: %s", err)
+	}
+	want := report.Report{
+		Version: 1,
+		Command: "breaking-changes",
+		Findings: []report.Finding{
+			{
+				Resource:   "google_x",
+				Field:      "field_a",
+				RuleID:     "field-optional-to-required",
+				Severity:   report.SeverityError,
+				Message:    "Field `field_a` changed from optional to required on `google_x`",
+				SourceFile: "mmv1/products/x/X.yaml",
+				HelpURI:    "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected report (-want, +got):\n%s", diff)
+	}
+
+	o.format = "xml"
+	if err := o.run(); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestBreakingChangesCmdProviderPaths(t *testing.T) {
+	o := breakingChangesOptions{
+		computeSchemaDiff: func() diff.SchemaDiff {
+			return diff.ComputeSchemaDiff(map[string]*schema.Resource{}, map[string]*schema.Resource{})
+		},
+		oldProviderPath: "old",
+		stdout:          &bytes.Buffer{},
+	}
+	if err := o.run(); err == nil {
+		t.Errorf("Expected an error when only --old-provider is set")
+	}
+
+	cmd := newBreakingChangesCmd(&rootOptions{})
+	for _, name := range []string{"old-provider", "new-provider"} {
+		if got := cmd.Flags().Lookup(name).DefValue; got != "" {
+			t.Errorf("Unexpected default for --%s: %q", name, got)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/maps"
 
@@ -29,6 +30,8 @@ type detectMissingDocsOptions struct {
 	rootOptions                 *rootOptions
 	computeSchemaDiff           func() diff.SchemaDiff
 	computeDatasourceSchemaDiff func() diff.SchemaDiff
+	format                      string
+	loadSourceFiles             func() (map[string]string, error)
 	stdout                      io.Writer
 }
 
@@ -46,6 +49,9 @@ func newDetectMissingDocsCmd(rootOptions *rootOptions) *cobra.Command {
 		computeDatasourceSchemaDiff: func() diff.SchemaDiff {
 			return diff.ComputeSchemaDiff(oldProvider.DatasourceMap(), newProvider.DatasourceMap())
 		},
+		loadSourceFiles: func() (map[string]string, error) {
+			return loadSourceFiles("new")
+		},
 		stdout: os.Stdout,
 	}
 	cmd := &cobra.Command{
@@ -57,9 +63,13 @@ func newDetectMissingDocsCmd(rootOptions *rootOptions) *cobra.Command {
 			return o.run(args)
 		},
 	}
+	addFormatFlag(cmd, &o.format)
 	return cmd
 }
 func (o *detectMissingDocsOptions) run(args []string) error {
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
 	schemaDiff := o.computeSchemaDiff()
 	detectedResources, err := detector.DetectMissingDocs(schemaDiff, args[0])
 	if err != nil {
@@ -77,6 +87,10 @@ func (o *detectMissingDocsOptions) run(args []string) error {
 		DataSource: sortMissingDocDetails(detectedDataSources),
 	}
 
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "detect-missing-docs", missingDocFindings(sum), o.loadSourceFiles)
+	}
+
 	if err := json.NewEncoder(o.stdout).Encode(sum); err != nil {
 		return fmt.Errorf("error encoding json: %w", err)
 	}
@@ -84,6 +98,30 @@ func (o *detectMissingDocsOptions) run(args []string) error {
 	return nil
 }
 
+func missingDocFindings(sum MissingDocsSummary) []report.Finding {
+	var findings []report.Finding
+	for _, details := range []struct {
+		missingDocs []detector.MissingDocDetails
+		kind        string
+	}{
+		{sum.Resource, "resource"},
+		{sum.DataSource, "datasource"},
+	} {
+		for _, missingDoc := range details.missingDocs {
+			for _, field := range missingDoc.Fields {
+				findings = append(findings, report.Finding{
+					Resource: missingDoc.Name,
+					Field:    field,
+					RuleID:   "missing-doc",
+					Severity: report.SeverityWarning,
+					Message:  fmt.Sprintf("Field `%s` of %s `%s` is not documented in `%s`", field, details.kind, missingDoc.Name, missingDoc.FilePath),
+				})
+			}
+		}
+	}
+	return findings
+}
+
 func sortMissingDocDetails(m map[string]detector.MissingDocDetails) []detector.MissingDocDetails {
 	itemNames := maps.Keys(m)
 	slices.Sort(itemNames)
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/test-reader/reader"
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
@@ -16,16 +17,21 @@ import (
 const detectMissingTestsDesc = "Run the missing test detector using the given services directory"
 
 type detectMissingTestsOptions struct {
-	rootOptions *rootOptions
-	stdout      io.Writer
+	rootOptions     *rootOptions
+	format          string
+	loadSourceFiles func() (map[string]string, error)
+	stdout          io.Writer
 }
 
 func newDetectMissingTestsCmd(rootOptions *rootOptions) *cobra.Command {
 	o := &detectMissingTestsOptions{
 		rootOptions: rootOptions,
-		stdout:      os.Stdout,
+		loadSourceFiles: func() (map[string]string, error) {
+			return loadSourceFiles("new")
+		},
+		stdout: os.Stdout,
 	}
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "detect-missing-tests SERVICES_DIR",
 		Short: detectMissingTestsDesc,
 		Long:  detectMissingTestsDesc,
@@ -34,9 +40,14 @@ func newDetectMissingTestsCmd(rootOptions *rootOptions) *cobra.Command {
 			return o.run(args)
 		},
 	}
+	addFormatFlag(cmd, &o.format)
+	return cmd
 }
 
 func (o *detectMissingTestsOptions) run(args []string) error {
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
 	allTests, errs := reader.ReadAllTests(args[0])
 	for path, err := range errs {
 		glog.Infof("error reading path: %s, err: %v", path, err)
@@ -46,8 +57,27 @@ func (o *detectMissingTestsOptions) run(args []string) error {
 	if err != nil {
 		return fmt.Errorf("error detecting missing tests: %v", err)
 	}
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "detect-missing-tests", missingTestFindings(missingTests), o.loadSourceFiles)
+	}
 	if err := json.NewEncoder(o.stdout).Encode(missingTests); err != nil {
 		return fmt.Errorf("error encoding json: %w", err)
 	}
 	return nil
 }
+
+func missingTestFindings(missingTests map[string]*detector.MissingTestInfo) []report.Finding {
+	var findings []report.Finding
+	for resource, info := range missingTests {
+		for _, field := range info.UntestedFields {
+			findings = append(findings, report.Finding{
+				Resource: resource,
+				Field:    field,
+				RuleID:   "missing-test",
+				Severity: report.SeverityWarning,
+				Message:  fmt.Sprintf("Field `%s` of resource `%s` is not set in any test", field, resource),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	"github.com/spf13/cobra"
+)
+
+// addFormatFlag adds the --format flag shared by all commands. Without it,
+// commands keep printing their original JSON output.
+func addFormatFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVar(format, "format", "", fmt.Sprintf("Output format, one of %s. Defaults to the command's own JSON output", strings.Join(report.Formats, ", ")))
+}
+
+// validateFormat checks the --format flag, if set, before any work is done.
+func validateFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	return report.ValidateFormat(format)
+}
+
+// loadSourceFiles returns the mmv1 source file of each generated resource in
+// the given provider directories. Earlier directories take precedence, and
+// directories that don't exist are skipped.
+func loadSourceFiles(providerPaths ...string) (map[string]string, error) {
+	sourceFiles := make(map[string]string)
+	for _, providerPath := range providerPaths {
+		if providerPath == "" {
+			continue
+		}
+		if _, err := os.Stat(providerPath); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		files, err := diff.LoadSourceFiles(providerPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading resource metadata from %s: %w", providerPath, err)
+		}
+		for resource, sourceFile := range files {
+			if _, ok := sourceFiles[resource]; !ok {
+				sourceFiles[resource] = sourceFile
+			}
+		}
+	}
+	return sourceFiles, nil
+}
+
+// writeReport sets the source file of each finding and writes the findings
+// in the given format.
+func writeReport(w io.Writer, format, command string, findings []report.Finding, loadSourceFiles func() (map[string]string, error)) error {
+	if loadSourceFiles != nil {
+		sourceFiles, err := loadSourceFiles()
+		if err != nil {
+			return err
+		}
+		for i, f := range findings {
+			findings[i].SourceFile = sourceFiles[f.Resource]
+		}
+	}
+	return report.Write(w, format, command, findings)
+}
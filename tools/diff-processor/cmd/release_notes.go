@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	newProvider "google/provider/new/google/provider"
+	oldProvider "google/provider/old/google/provider"
+
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/breaking_changes"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	changelog "github.com/hashicorp/go-changelog"
+	"github.com/spf13/cobra"
+)
+
+const releaseNotesDesc = `Check the release notes in the given file (or "-" for stdin), usually the PR description, against the schema diff and breaking changes of the PR`
+
+const releaseNotesDocs = "https://googlecloudplatform.github.io/magic-modules/code-review/release-notes/"
+
+type releaseNotesOptions struct {
+	rootOptions                 *rootOptions
+	computeSchemaDiff           func() diff.SchemaDiff
+	computeDatasourceSchemaDiff func() diff.SchemaDiff
+	oldProviderPath             string
+	newProviderPath             string
+	loadServices                func() (map[string]string, error)
+	loadSourceFiles             func() (map[string]string, error)
+	format                      string
+	stdin                       io.Reader
+	stdout                      io.Writer
+}
+
+func newReleaseNotesCmd(rootOptions *rootOptions) *cobra.Command {
+	o := &releaseNotesOptions{
+		rootOptions: rootOptions,
+		computeSchemaDiff: func() diff.SchemaDiff {
+			return schemaDiff
+		},
+		computeDatasourceSchemaDiff: func() diff.SchemaDiff {
+			return diff.ComputeSchemaDiff(oldProvider.DatasourceMap(), newProvider.DatasourceMap())
+		},
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+	o.loadServices = func() (map[string]string, error) {
+		return loadReleaseNoteServices(o.newProviderPath, o.oldProviderPath)
+	}
+	o.loadSourceFiles = func() (map[string]string, error) {
+		return loadSourceFiles(o.newProviderPath, o.oldProviderPath)
+	}
+	cmd := &cobra.Command{
+		Use:   "release-notes RELEASE_NOTES_FILE",
+		Short: releaseNotesDesc,
+		Long:  releaseNotesDesc,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+	cmd.Flags().StringVar(&o.oldProviderPath, "old-provider", "old", "Path to the old provider source, used to read the service of removed resources")
+	cmd.Flags().StringVar(&o.newProviderPath, "new-provider", "new", "Path to the new provider source, used to read the service of each resource")
+	addFormatFlag(cmd, &o.format)
+	return cmd
+}
+
+func (o *releaseNotesOptions) run(args []string) error {
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
+	var content []byte
+	var err error
+	if args[0] == "-" {
+		content, err = io.ReadAll(o.stdin)
+	} else {
+		content, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("error reading release notes: %w", err)
+	}
+	notes := changelog.NotesFromEntry(changelog.Entry{Body: string(content)})
+
+	services, err := o.loadServices()
+	if err != nil {
+		return err
+	}
+	schemaDiff := o.computeSchemaDiff()
+	breakingChanges := breaking_changes.ComputeBreakingChanges(schemaDiff)
+	issues := detector.DetectReleaseNoteIssues(notes, schemaDiff, o.computeDatasourceSchemaDiff(), breakingChanges, services)
+
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "release-notes", releaseNoteFindings(issues), o.loadSourceFiles)
+	}
+	if issues == nil {
+		issues = []detector.ReleaseNoteIssue{}
+	}
+	if err := json.NewEncoder(o.stdout).Encode(issues); err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+	return nil
+}
+
+func releaseNoteFindings(issues []detector.ReleaseNoteIssue) []report.Finding {
+	var findings []report.Finding
+	for _, issue := range issues {
+		severity := report.SeverityError
+		if issue.RuleID == detector.RuleWrongServicePrefix {
+			severity = report.SeverityWarning
+		}
+		findings = append(findings, report.Finding{
+			Resource: issue.Resource,
+			RuleID:   issue.RuleID,
+			Severity: severity,
+			Message:  issue.Message,
+			HelpURI:  releaseNotesDocs,
+		})
+	}
+	return findings
+}
+
+// loadReleaseNoteServices returns the product that release notes for each
+// resource should start with. That is the folder of the mmv1 YAML files for
+// generated resources, and the service directory for handwritten ones.
+func loadReleaseNoteServices(providerPaths ...string) (map[string]string, error) {
+	services := make(map[string]string)
+	for i := len(providerPaths) - 1; i >= 0; i-- {
+		providerServices, err := loadResourceServices(providerPaths[i])
+		if err != nil {
+			return nil, err
+		}
+		for resource, service := range providerServices {
+			services[resource] = service
+		}
+	}
+	sourceFiles, err := loadSourceFiles(providerPaths...)
+	if err != nil {
+		return nil, err
+	}
+	for resource, sourceFile := range sourceFiles {
+		// Source files are like mmv1/products/compute/Instance.yaml.
+		parts := strings.Split(path.Dir(sourceFile), "/")
+		if len(parts) >= 2 && parts[len(parts)-2] == "products" {
+			services[resource] = parts[len(parts)-1]
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const releaseNotesBody = "Adds a new resource.\n\n" +
+	"```release-note:enhancement\n" +
+	"gce: added `google_compute_new` resource\n" +
+	"```\n"
+
+func newTestReleaseNotesOptions(format string, stdout *bytes.Buffer) releaseNotesOptions {
+	return releaseNotesOptions{
+		computeSchemaDiff: func() diff.SchemaDiff {
+			return diff.ComputeSchemaDiff(map[string]*schema.Resource{}, map[string]*schema.Resource{
+				"google_compute_new": {
+					Schema: map[string]*schema.Schema{
+						"name": {Type: schema.TypeString, Required: true},
+					},
+				},
+			})
+		},
+		computeDatasourceSchemaDiff: func() diff.SchemaDiff {
+			return diff.SchemaDiff{}
+		},
+		loadServices: func() (map[string]string, error) {
+			return map[string]string{"google_compute_new": "compute"}, nil
+		},
+		loadSourceFiles: func() (map[string]string, error) {
+			return map[string]string{"google_compute_new": "mmv1/products/compute/New.yaml"}, nil
+		},
+		format: format,
+		stdin:  strings.NewReader(releaseNotesBody),
+		stdout: stdout,
+	}
+}
+
+func TestReleaseNotesCmd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.md")
+	if err := os.WriteFile(path, []byte(releaseNotesBody), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := []detector.ReleaseNoteIssue{
+		{
+			RuleID:        detector.RuleMissingNewResourceNote,
+			Resource:      "google_compute_new",
+			Message:       "Resource `google_compute_new` was added, but there is no `new-resource` release note for it.",
+			SuggestedNote: "```release-note:new-resource\n`google_compute_new`\n```",
+		},
+		{
+			RuleID:        detector.RuleWrongServicePrefix,
+			NoteType:      "enhancement",
+			Note:          "gce: added `google_compute_new` resource",
+			Message:       "Release note starts with `gce:`, but the changed resources belong to `compute`. Use `provider:` for changes that affect the whole provider.",
+			SuggestedNote: "```release-note:enhancement\ncompute: added `google_compute_new` resource\n```",
+		},
+	}
+	for _, arg := range []string{path, "-"} {
+		var buf bytes.Buffer
+		o := newTestReleaseNotesOptions("", &buf)
+		if err := o.run([]string{arg}); err != nil {
+			t.Fatalf("Error running command with %s: %s", arg, err)
+		}
+		var got []detector.ReleaseNoteIssue
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to unmarshall output: %s", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected result with %s, diff(-want, got) = %s", arg, diff)
+		}
+	}
+}
+
+func TestReleaseNotesCmdFormat(t *testing.T) {
+	var buf bytes.Buffer
+	o := newTestReleaseNotesOptions(report.FormatJSON, &buf)
+	if err := o.run([]string{"-"}); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	var got report.Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshall output: %s", err)
+	}
+	want := []report.Finding{
+		{
+			RuleID:   detector.RuleWrongServicePrefix,
+			Severity: report.SeverityWarning,
+			Message:  "Release note starts with `gce:`, but the changed resources belong to `compute`. Use `provider:` for changes that affect the whole provider.",
+			HelpURI:  releaseNotesDocs,
+		},
+		{
+			Resource:   "google_compute_new",
+			RuleID:     detector.RuleMissingNewResourceNote,
+			Severity:   report.SeverityError,
+			Message:    "Resource `google_compute_new` was added, but there is no `new-resource` release note for it.",
+			SourceFile: "mmv1/products/compute/New.yaml",
+			HelpURI:    releaseNotesDocs,
+		},
+	}
+	if diff := cmp.Diff(want, got.Findings); diff != "" {
+		t.Errorf("Unexpected findings (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadReleaseNoteServices(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"google/services/compute/resource_compute_address_meta.yaml":    "resource: 'google_compute_address'\nsource_file: 'products/compute/Address.yaml'\n",
+		"google/services/compute/resource_compute_instance_meta.yaml":   "resource: 'google_compute_instance'\n",
+		"google/services/cloudrun/resource_cloud_run_service_meta.yaml": "resource: 'google_cloud_run_service'\nsource_file: 'products/cloudrun/Service.yaml'\n",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := loadReleaseNoteServices(dir, filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("loadReleaseNoteServices() error: %s", err)
+	}
+	want := map[string]string{
+		"google_compute_address":   "compute",
+		"google_compute_instance":  "compute",
+		"google_cloud_run_service": "cloudrun",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("loadReleaseNoteServices() returned unexpected services (-want +got):\n%s", diff)
+	}
+}
@@ -25,6 +25,8 @@ func newRootCmd() (*cobra.Command, *rootOptions, error) {
 	cmd.AddCommand(newDetectMissingTestsCmd(o))
 	cmd.AddCommand(newSchemaDiffCmd(o))
 	cmd.AddCommand(newDetectMissingDocsCmd(o))
+	cmd.AddCommand(newTestCoverageCmd(o))
+	cmd.AddCommand(newReleaseNotesCmd(o))
 	return cmd, o, nil
 }
 
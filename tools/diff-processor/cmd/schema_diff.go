@@ -11,6 +11,7 @@ import (
 	"sort"
 
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +19,10 @@ const schemaDiffDesc = `Return a simple summary of the schema diff for this buil
 
 var schemaDiff = diff.ComputeSchemaDiff(oldProvider.ResourceMap(), newProvider.ResourceMap())
 
+func computeSchemaDiffWithApiTypes(oldApiTypes, newApiTypes diff.ApiTypes) diff.SchemaDiff {
+	return diff.ComputeSchemaDiffWithApiTypes(oldProvider.ResourceMap(), newProvider.ResourceMap(), oldApiTypes, newApiTypes)
+}
+
 type simpleSchemaDiff struct {
 	AddedResources, ModifiedResources, RemovedResources []string
 }
@@ -25,6 +30,8 @@ type simpleSchemaDiff struct {
 type schemaDiffOptions struct {
 	rootOptions       *rootOptions
 	computeSchemaDiff func() diff.SchemaDiff
+	format            string
+	loadSourceFiles   func() (map[string]string, error)
 	stdout            io.Writer
 }
 
@@ -34,6 +41,9 @@ func newSchemaDiffCmd(rootOptions *rootOptions) *cobra.Command {
 		computeSchemaDiff: func() diff.SchemaDiff {
 			return schemaDiff
 		},
+		loadSourceFiles: func() (map[string]string, error) {
+			return loadSourceFiles("new", "old")
+		},
 		stdout: os.Stdout,
 	}
 	cmd := &cobra.Command{
@@ -45,9 +55,13 @@ func newSchemaDiffCmd(rootOptions *rootOptions) *cobra.Command {
 			return o.run()
 		},
 	}
+	addFormatFlag(cmd, &o.format)
 	return cmd
 }
 func (o *schemaDiffOptions) run() error {
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
 	schemaDiff := o.computeSchemaDiff()
 
 	simple := simpleSchemaDiff{}
@@ -66,9 +80,36 @@ func (o *schemaDiffOptions) run() error {
 	sort.Strings(simple.ModifiedResources)
 	sort.Strings(simple.RemovedResources)
 
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "schema-diff", schemaDiffFindings(simple), o.loadSourceFiles)
+	}
+
 	if err := json.NewEncoder(o.stdout).Encode(simple); err != nil {
 		return fmt.Errorf("Error encoding json: %w", err)
 	}
 
 	return nil
 }
+
+func schemaDiffFindings(simple simpleSchemaDiff) []report.Finding {
+	var findings []report.Finding
+	for _, change := range []struct {
+		resources []string
+		ruleID    string
+		verb      string
+	}{
+		{simple.AddedResources, "resource-added", "added"},
+		{simple.ModifiedResources, "resource-modified", "modified"},
+		{simple.RemovedResources, "resource-removed", "removed"},
+	} {
+		for _, resource := range change.resources {
+			findings = append(findings, report.Finding{
+				Resource: resource,
+				RuleID:   change.ruleID,
+				Severity: report.SeverityNote,
+				Message:  fmt.Sprintf("Resource `%s` was %s", resource, change.verb),
+			})
+		}
+	}
+	return findings
+}
@@ -280,3 +280,41 @@ func TestSchemaDiffCmdRun(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaDiffCmdRunMarkdown(t *testing.T) {
+	oldResourceMap := map[string]*schema.Resource{
+		"google_z_resource": {
+			Schema: map[string]*schema.Schema{
+				"field_a": {Description: "beep", Optional: true},
+			},
+		},
+	}
+	newResourceMap := map[string]*schema.Resource{
+		"google_x_resource": {
+			Schema: map[string]*schema.Schema{
+				"field_a": {Description: "beep", Optional: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	o := schemaDiffOptions{
+		computeSchemaDiff: func() diff.SchemaDiff {
+			return diff.ComputeSchemaDiff(oldResourceMap, newResourceMap)
+		},
+		format: "markdown",
+		stdout: &buf,
+	}
+	if err := o.run(); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+
+	want := "## diff-processor schema-diff\n\n" +
+		"| Severity | Resource | Field | Rule | Message | Source |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| note | `google_x_resource` |  | resource-added | Resource `google_x_resource` was added |  |\n" +
+		"| note | `google_z_resource` |  | resource-removed | Resource `google_z_resource` was removed |  |\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Unexpected markdown (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+
+	newProvider "google/provider/new/google/provider"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/test-reader/reader"
+	"github.com/golang/glog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cobra"
+)
+
+const testCoverageDesc = "Compute the test coverage of every field of every resource in the new provider using the given services directory"
+
+type testCoverageOptions struct {
+	rootOptions     *rootOptions
+	resourceMap     func() map[string]*schema.Resource
+	loadServices    func() (map[string]string, error)
+	resources       []string
+	summary         bool
+	format          string
+	loadSourceFiles func() (map[string]string, error)
+	stdout          io.Writer
+}
+
+// coverageTrend is a compact summary meant to be appended to a file on every
+// run, one JSON object per line.
+type coverageTrend struct {
+	Total    detector.CoverageSummary            `json:"total"`
+	Services map[string]detector.CoverageSummary `json:"services"`
+}
+
+func newTestCoverageCmd(rootOptions *rootOptions) *cobra.Command {
+	o := &testCoverageOptions{
+		rootOptions: rootOptions,
+		resourceMap: newProvider.ResourceMap,
+		loadServices: func() (map[string]string, error) {
+			return loadResourceServices("new")
+		},
+		loadSourceFiles: func() (map[string]string, error) {
+			return loadSourceFiles("new")
+		},
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "test-coverage SERVICES_DIR",
+		Short: testCoverageDesc,
+		Long:  testCoverageDesc,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+	cmd.Flags().StringSliceVar(&o.resources, "resources", nil, "Only report coverage for these resources")
+	cmd.Flags().BoolVar(&o.summary, "summary", false, "Print only the total and per-service coverage on a single line")
+	addFormatFlag(cmd, &o.format)
+	return cmd
+}
+
+func (o *testCoverageOptions) run(args []string) error {
+	if err := validateFormat(o.format); err != nil {
+		return err
+	}
+	if o.summary && o.format != "" {
+		return fmt.Errorf("--summary and --format can't be used together")
+	}
+	allTests, errs := reader.ReadAllTests(args[0])
+	for path, err := range errs {
+		glog.Infof("error reading path: %s, err: %v", path, err)
+	}
+	services, err := o.loadServices()
+	if err != nil {
+		return err
+	}
+	resourceMap := o.resourceMap()
+	if len(o.resources) > 0 {
+		filtered := make(map[string]*schema.Resource)
+		for name, resource := range resourceMap {
+			if slices.Contains(o.resources, name) {
+				filtered[name] = resource
+			}
+		}
+		resourceMap = filtered
+	}
+	coverage, err := detector.ComputeTestCoverage(resourceMap, services, allTests)
+	if err != nil {
+		return fmt.Errorf("error computing test coverage: %w", err)
+	}
+
+	if o.format != "" {
+		return writeReport(o.stdout, o.format, "test-coverage", testCoverageFindings(coverage), o.loadSourceFiles)
+	}
+
+	var output any = coverage
+	if o.summary {
+		trend := coverageTrend{
+			Total:    coverage.Total,
+			Services: make(map[string]detector.CoverageSummary),
+		}
+		for _, service := range coverage.Services {
+			trend.Services[service.Name] = service.CoverageSummary
+		}
+		output = trend
+	}
+	if err := json.NewEncoder(o.stdout).Encode(output); err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+	return nil
+}
+
+func testCoverageFindings(coverage detector.CoverageReport) []report.Finding {
+	var findings []report.Finding
+	for _, resource := range coverage.Resources {
+		for _, field := range resource.UntestedFields {
+			findings = append(findings, report.Finding{
+				Resource: resource.Name,
+				Field:    field,
+				RuleID:   "untested-field",
+				Severity: report.SeverityNote,
+				Message:  fmt.Sprintf("Field `%s` of `%s` is not set in any test", field, resource.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// loadResourceServices returns the service of each generated resource in the
+// given provider directory, or no services if the directory doesn't exist.
+func loadResourceServices(providerPath string) (map[string]string, error) {
+	if _, err := os.Stat(providerPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	services, err := diff.LoadResourceServices(providerPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading resource metadata from %s: %w", providerPath, err)
+	}
+	return services, nil
+}
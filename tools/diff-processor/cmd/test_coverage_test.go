@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/detector"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/report"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTestCoverageCmd(t *testing.T) {
+	resourceMap := map[string]*schema.Resource{
+		"covered_resource": {
+			Schema: map[string]*schema.Schema{
+				"field_one": {Type: schema.TypeString, Optional: true},
+				"field_ten": {Type: schema.TypeString, Optional: true},
+			},
+		},
+		"no_test": {
+			Schema: map[string]*schema.Schema{
+				"field_one": {Type: schema.TypeString, Required: true},
+			},
+		},
+	}
+	services := map[string]string{
+		"covered_resource": "service",
+		"no_test":          "other",
+	}
+
+	cases := map[string]struct {
+		resources []string
+		summary   bool
+		want      any
+	}{
+		"summary": {
+			summary: true,
+			want: coverageTrend{
+				Total: detector.CoverageSummary{Fields: 3, TestedFields: 1, Percent: 33.33},
+				Services: map[string]detector.CoverageSummary{
+					"other":   {Fields: 1, TestedFields: 0, Percent: 0},
+					"service": {Fields: 2, TestedFields: 1, Percent: 50},
+				},
+			},
+		},
+		"filtered resources": {
+			resources: []string{"covered_resource"},
+			summary:   true,
+			want: coverageTrend{
+				Total: detector.CoverageSummary{Fields: 2, TestedFields: 1, Percent: 50},
+				Services: map[string]detector.CoverageSummary{
+					"service": {Fields: 2, TestedFields: 1, Percent: 50},
+				},
+			},
+		},
+	}
+	for tn, tc := range cases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			o := testCoverageOptions{
+				resourceMap: func() map[string]*schema.Resource {
+					return resourceMap
+				},
+				loadServices: func() (map[string]string, error) {
+					return services, nil
+				},
+				resources: tc.resources,
+				summary:   tc.summary,
+				stdout:    &buf,
+			}
+			if err := o.run([]string{"../../test-reader/reader/testdata"}); err != nil {
+				t.Fatalf("Error running command: %s", err)
+			}
+			var got coverageTrend
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshall output: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected output (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTestCoverageCmdFormat(t *testing.T) {
+	var buf bytes.Buffer
+	o := testCoverageOptions{
+		resourceMap: func() map[string]*schema.Resource {
+			return map[string]*schema.Resource{
+				"no_test": {
+					Schema: map[string]*schema.Schema{
+						"field_one": {Type: schema.TypeString, Required: true},
+					},
+				},
+			}
+		},
+		loadServices: func() (map[string]string, error) {
+			return map[string]string{"no_test": "other"}, nil
+		},
+		format: report.FormatJSON,
+		loadSourceFiles: func() (map[string]string, error) {
+			return map[string]string{"no_test": "mmv1/products/other/NoTest.yaml"}, nil
+		},
+		stdout: &buf,
+	}
+	if err := o.run([]string{"../../test-reader/reader/testdata"}); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+
+	var got report.Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshall output: %s", err)
+	}
+	want := report.Report{
+		Version: 1,
+		Command: "test-coverage",
+		Findings: []report.Finding{
+			{
+				Resource:   "no_test",
+				Field:      "field_one",
+				RuleID:     "untested-field",
+				Severity:   report.SeverityNote,
+				Message:    "Field `field_one` of `no_test` is not set in any test",
+				SourceFile: "mmv1/products/other/NoTest.yaml",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected report (-want, +got):\n%s", diff)
+	}
+
+	o.summary = true
+	if err := o.run([]string{"../../test-reader/reader/testdata"}); err == nil {
+		t.Errorf("Expected an error for --summary with --format")
+	}
+	o.summary = false
+	o.format = "xml"
+	if err := o.run([]string{"../../test-reader/reader/testdata"}); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,137 @@
+package detector
+
+import (
+	"math"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/test-reader/reader"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// unknownService is used for resources without a generated metadata file.
+const unknownService = "unknown"
+
+// CoverageReport is the test coverage of the fields of every resource.
+type CoverageReport struct {
+	Total     CoverageSummary    `json:"total"`
+	Services  []ServiceCoverage  `json:"services"`
+	Resources []ResourceCoverage `json:"resources"`
+}
+
+// CoverageSummary counts the fields that are set in at least one test.
+type CoverageSummary struct {
+	Fields       int     `json:"fields"`
+	TestedFields int     `json:"tested_fields"`
+	Percent      float64 `json:"percent"`
+}
+
+type ServiceCoverage struct {
+	Name      string `json:"name"`
+	Resources int    `json:"resources"`
+	CoverageSummary
+}
+
+type ResourceCoverage struct {
+	Name    string `json:"name"`
+	Service string `json:"service"`
+	// Tests is the number of tests that include the resource.
+	Tests int `json:"tests"`
+	CoverageSummary
+	UntestedFields []string `json:"untested_fields"`
+}
+
+// ComputeTestCoverage computes which fields of each resource are set in at
+// least one of the tests. Services are keyed by resource name; resources
+// without a service are reported under "unknown".
+func ComputeTestCoverage(resourceMap map[string]*schema.Resource, services map[string]string, allTests []*reader.Test) (CoverageReport, error) {
+	fields := make(map[string]ResourceChanges)
+	for resource, resourceSchema := range resourceMap {
+		resourceFields := make(ResourceChanges)
+		for field, fieldSchema := range diff.FlattenSchema(resourceSchema.Schema) {
+			if isTestableField(resource, field, fieldSchema) {
+				resourceFields[field] = &Field{}
+			}
+		}
+		fields[resource] = resourceFields
+	}
+
+	resourceTests := make(map[string]map[string]struct{})
+	for _, test := range allTests {
+		for _, step := range test.Steps {
+			for resource, resourceMap := range step {
+				resourceFields, ok := fields[resource]
+				if !ok {
+					continue
+				}
+				if resourceTests[resource] == nil {
+					resourceTests[resource] = make(map[string]struct{})
+				}
+				resourceTests[resource][test.Name] = struct{}{}
+				for _, resourceConfig := range resourceMap {
+					if err := markCoverage(resourceFields, resourceConfig); err != nil {
+						return CoverageReport{}, err
+					}
+				}
+			}
+		}
+	}
+
+	report := CoverageReport{
+		Services:  []ServiceCoverage{},
+		Resources: []ResourceCoverage{},
+	}
+	serviceCoverage := make(map[string]*ServiceCoverage)
+	for resource, resourceFields := range fields {
+		service, ok := services[resource]
+		if !ok || service == "" {
+			service = unknownService
+		}
+		untested := untestedFields(resourceFields)
+		sort.Strings(untested)
+		summary := newCoverageSummary(len(resourceFields), len(resourceFields)-len(untested))
+		report.Resources = append(report.Resources, ResourceCoverage{
+			Name:            resource,
+			Service:         service,
+			Tests:           len(resourceTests[resource]),
+			CoverageSummary: summary,
+			UntestedFields:  untested,
+		})
+
+		if serviceCoverage[service] == nil {
+			serviceCoverage[service] = &ServiceCoverage{Name: service}
+		}
+		serviceCoverage[service].Resources++
+		serviceCoverage[service].Fields += summary.Fields
+		serviceCoverage[service].TestedFields += summary.TestedFields
+		report.Total.Fields += summary.Fields
+		report.Total.TestedFields += summary.TestedFields
+	}
+	sort.Slice(report.Resources, func(i, j int) bool {
+		return report.Resources[i].Name < report.Resources[j].Name
+	})
+	for _, coverage := range serviceCoverage {
+		coverage.CoverageSummary = newCoverageSummary(coverage.Fields, coverage.TestedFields)
+		report.Services = append(report.Services, *coverage)
+	}
+	sort.Slice(report.Services, func(i, j int) bool {
+		return report.Services[i].Name < report.Services[j].Name
+	})
+	report.Total = newCoverageSummary(report.Total.Fields, report.Total.TestedFields)
+	return report, nil
+}
+
+// newCoverageSummary returns the summary with the percentage of tested fields
+// rounded to two decimal places. Resources without testable fields are fully
+// covered.
+func newCoverageSummary(fields, testedFields int) CoverageSummary {
+	percent := 100.0
+	if fields > 0 {
+		percent = math.Round(float64(testedFields)*10000/float64(fields)) / 100
+	}
+	return CoverageSummary{
+		Fields:       fields,
+		TestedFields: testedFields,
+		Percent:      percent,
+	}
+}
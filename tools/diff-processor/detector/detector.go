@@ -10,9 +10,7 @@ import (
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/documentparser"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/test-reader/reader"
-	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/zclconf/go-cty/cty"
 )
 
 type MissingTestInfo struct {
@@ -48,7 +46,11 @@ type MissingDocDetails struct {
 // Return a map of resource names to missing test info about that resource.
 func DetectMissingTests(schemaDiff diff.SchemaDiff, allTests []*reader.Test) (map[string]*MissingTestInfo, error) {
 	changedFields := getChangedFieldsFromSchemaDiff(schemaDiff)
-	return getMissingTestsForChanges(changedFields, allTests)
+	resourceSchemas := make(map[string]map[string]*schema.Schema)
+	for resource, resourceDiff := range schemaDiff {
+		resourceSchemas[resource] = resourceDiff.FlattenedSchema.New
+	}
+	return getMissingTestsForChanges(changedFields, resourceSchemas, allTests)
 }
 
 // Convert SchemaDiff object to map of ResourceChanges objects.
@@ -58,24 +60,11 @@ func getChangedFieldsFromSchemaDiff(schemaDiff diff.SchemaDiff) map[string]Resou
 	for resource, resourceDiff := range schemaDiff {
 		resourceChanges := make(ResourceChanges)
 		for field, fieldDiff := range resourceDiff.Fields {
-			if field == "project" {
-				// Skip the project field.
-				continue
-			}
-			if strings.Contains(resource, "iam") && field == "condition" {
-				// Skip the condition field of iam resources because some iam resources do not support it.
-				continue
-			}
 			if fieldDiff.New == nil {
 				// Skip deleted fields.
 				continue
 			}
-			if fieldDiff.New.Computed && !fieldDiff.New.Optional {
-				// Skip output-only fields.
-				continue
-			}
-			if _, ok := fieldDiff.New.Elem.(*schema.Resource); ok {
-				// Skip parent fields.
+			if !isTestableField(resource, field, fieldDiff.New) {
 				continue
 			}
 			if fieldDiff.Old == nil {
@@ -91,14 +80,47 @@ func getChangedFieldsFromSchemaDiff(schemaDiff diff.SchemaDiff) map[string]Resou
 	return changedFields
 }
 
-func getMissingTestsForChanges(changedFields map[string]ResourceChanges, allTests []*reader.Test) (map[string]*MissingTestInfo, error) {
+// isTestableField reports whether a field is expected to be set in a test.
+// Parent fields, output-only fields and a few common fields are skipped.
+func isTestableField(resource, field string, fieldSchema *schema.Schema) bool {
+	if field == "project" {
+		// Skip the project field.
+		return false
+	}
+	if strings.Contains(resource, "iam") && field == "condition" {
+		// Skip the condition field of iam resources because some iam resources do not support it.
+		return false
+	}
+	if fieldSchema.Computed && !fieldSchema.Optional {
+		// Skip output-only fields.
+		return false
+	}
+	if _, ok := fieldSchema.Elem.(*schema.Resource); ok {
+		// Skip parent fields.
+		return false
+	}
+	return true
+}
+
+// Resource schemas are flattened, keyed by resource name, and used for the
+// placeholder values in suggested tests.
+func getMissingTestsForChanges(changedFields map[string]ResourceChanges, resourceSchemas map[string]map[string]*schema.Schema, allTests []*reader.Test) (map[string]*MissingTestInfo, error) {
 	resourceNamesToTests := make(map[string][]string)
-	for _, test := range allTests {
-		for _, step := range test.Steps {
+	resourceNamesToConfigs := make(map[string][]string)
+	sortedTests := make([]*reader.Test, len(allTests))
+	copy(sortedTests, allTests)
+	sort.SliceStable(sortedTests, func(i, j int) bool {
+		return sortedTests[i].Name < sortedTests[j].Name
+	})
+	for _, test := range sortedTests {
+		for i, step := range test.Steps {
 			for resourceName, resourceMap := range step {
 				if changedResourceFields, ok := changedFields[resourceName]; ok {
 					// This resource type has changed fields.
 					resourceNamesToTests[resourceName] = append(resourceNamesToTests[resourceName], test.Name)
+					if i < len(test.Configs) {
+						resourceNamesToConfigs[resourceName] = append(resourceNamesToConfigs[resourceName], test.Configs[i])
+					}
 					for _, resourceConfig := range resourceMap {
 						if err := markCoverage(changedResourceFields, resourceConfig); err != nil {
 							return nil, err
@@ -115,7 +137,7 @@ func getMissingTestsForChanges(changedFields map[string]ResourceChanges, allTest
 		if len(untested) > 0 {
 			missingTests[resourceName] = &MissingTestInfo{
 				UntestedFields: untested,
-				SuggestedTest:  suggestedTest(resourceName, untested),
+				SuggestedTest:  suggestedTest(resourceName, untested, resourceSchemas[resourceName], resourceNamesToConfigs[resourceName]),
 				Tests:          resourceNamesToTests[resourceName],
 			}
 		}
@@ -142,28 +164,6 @@ func untestedFields(fieldCoverage ResourceChanges) []string {
 	return fields
 }
 
-func suggestedTest(resourceName string, untested []string) string {
-	f := hclwrite.NewEmptyFile()
-	rootBody := f.Body()
-	resourceBlock := rootBody.AppendNewBlock("resource", []string{resourceName, "primary"})
-	for _, field := range untested {
-		body := resourceBlock.Body()
-		path := strings.Split(field, ".")
-		for i, step := range path {
-			if i < len(path)-1 {
-				block := body.FirstMatchingBlock(step, nil)
-				if block == nil {
-					block = body.AppendNewBlock(step, nil)
-				}
-				body = block.Body()
-			} else {
-				body.SetAttributeValue(step, cty.StringVal("VALUE"))
-			}
-		}
-	}
-	return strings.ReplaceAll(string(f.Bytes()), `"VALUE"`, "# value needed")
-}
-
 // DetectMissingDocs detect new fields that are missing docs given the schema diffs.
 // Return a map of resource names to missing doc info.
 // It parses the document to see if the field is present within the resource document file,
@@ -5,9 +5,11 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/breaking_changes"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
 	"github.com/GoogleCloudPlatform/magic-modules/tools/test-reader/reader"
 	"github.com/google/go-cmp/cmp"
+	changelog "github.com/hashicorp/go-changelog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -101,13 +103,16 @@ func TestGetMissingTestsForChanges(t *testing.T) {
 			expectedMissingTests: map[string]MissingTestInfo{
 				"uncovered_resource": {
 					UntestedFields: []string{"field_four.field_five.field_six", "field_one"},
-					SuggestedTest: `resource "uncovered_resource" "primary" {
+					SuggestedTest: `resource "uncovered_resource" "resource" {
+  field_two {
+    field_three = "value-two"
+  }
   field_four {
     field_five {
-      field_six = # value needed
+      field_six = "value"
     }
   }
-  field_one = # value needed
+  field_one = "value"
 }
 `,
 				},
@@ -132,7 +137,7 @@ func TestGetMissingTestsForChanges(t *testing.T) {
 				"no_test": {
 					UntestedFields: []string{"field_one"},
 					SuggestedTest: `resource "no_test" "primary" {
-  field_one = # value needed
+  field_one = "value"
 }
 `,
 				},
@@ -154,26 +159,29 @@ func TestGetMissingTestsForChanges(t *testing.T) {
 				"no_test": {
 					UntestedFields: []string{"field_one"},
 					SuggestedTest: `resource "no_test" "primary" {
-  field_one = # value needed
+  field_one = "value"
 }
 `,
 				},
 				"uncovered_resource": {
 					UntestedFields: []string{"field_four.field_five.field_six", "field_one"},
-					SuggestedTest: `resource "uncovered_resource" "primary" {
+					SuggestedTest: `resource "uncovered_resource" "resource" {
+  field_two {
+    field_three = "value-two"
+  }
   field_four {
     field_five {
-      field_six = # value needed
+      field_six = "value"
     }
   }
-  field_one = # value needed
+  field_one = "value"
 }
 `,
 				},
 			},
 		},
 	} {
-		missingTests, err := getMissingTestsForChanges(test.changedFields, allTests)
+		missingTests, err := getMissingTestsForChanges(test.changedFields, nil, allTests)
 		if err != nil {
 			t.Errorf("error detecting missing tests for %s: %s", test.name, err)
 		}
@@ -204,6 +212,238 @@ func TestGetMissingTestsForChanges(t *testing.T) {
 	}
 }
 
+func TestSuggestedTest(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"name": {Type: schema.TypeString, Required: true},
+		"tier": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: `The tier of the resource. Possible values: ["BASIC", "PREMIUM"]`,
+		},
+		"labels":        {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"replica_count": {Type: schema.TypeInt, Optional: true},
+		"enabled":       {Type: schema.TypeBool, Optional: true},
+		"zones":         {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"settings": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"mode":    {Type: schema.TypeString, Required: true},
+					"timeout": {Type: schema.TypeString, Optional: true},
+					"retry": {
+						Type:     schema.TypeList,
+						Required: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"attempts": {Type: schema.TypeInt, Required: true},
+							},
+						},
+					},
+				},
+			},
+		},
+		"settings.mode":           {Type: schema.TypeString, Required: true},
+		"settings.timeout":        {Type: schema.TypeString, Optional: true},
+		"settings.retry":          {Type: schema.TypeList, Required: true, Elem: &schema.Resource{}},
+		"settings.retry.attempts": {Type: schema.TypeInt, Required: true},
+	}
+	untested := []string{"enabled", "labels", "replica_count", "settings.timeout", "tier", "zones"}
+	for _, tc := range []struct {
+		name    string
+		configs []string
+		want    string
+	}{
+		{
+			name: "no configs",
+			want: `resource "google_x" "primary" {
+  name    = "value"
+  enabled = true
+  labels = {
+    key = "value"
+  }
+  replica_count = 1
+  settings {
+    mode = "value"
+    retry {
+      attempts = 1
+    }
+    timeout = "value"
+  }
+  tier  = "BASIC"
+  zones = ["value"]
+}
+`,
+		},
+		{
+			name: "existing config",
+			configs: []string{
+				`
+resource "google_other" "other" {
+  name = "other-%{random_suffix}"
+}
+`,
+				`
+resource "google_network" "network" {
+  name = "tf-test-network%{random_suffix}"
+}
+
+resource "google_x" "primary" {
+  name    = "tf-test-%{random_suffix}"
+  network = google_network.network.id
+  project = %s
+  settings {
+    mode = "FAST"
+  }
+}
+`,
+			},
+			want: `resource "google_network" "network" {
+  name = "tf-test-network%{random_suffix}"
+}
+
+resource "google_x" "primary" {
+  name    = "tf-test-%{random_suffix}"
+  network = google_network.network.id
+  project = %s
+  settings {
+    mode    = "FAST"
+    timeout = "value"
+  }
+  enabled = true
+  labels = {
+    key = "value"
+  }
+  replica_count = 1
+  tier          = "BASIC"
+  zones         = ["value"]
+}
+`,
+		},
+		{
+			name:    "unparseable config",
+			configs: []string{`resource "google_x" "primary" {`},
+			want: `resource "google_x" "primary" {
+  name    = "value"
+  enabled = true
+  labels = {
+    key = "value"
+  }
+  replica_count = 1
+  settings {
+    mode = "value"
+    retry {
+      attempts = 1
+    }
+    timeout = "value"
+  }
+  tier  = "BASIC"
+  zones = ["value"]
+}
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := suggestedTest("google_x", untested, resourceSchema, tc.configs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("suggestedTest() returned unexpected config (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComputeTestCoverage(t *testing.T) {
+	allTests, errs := reader.ReadAllTests("../../test-reader/reader/testdata")
+	if len(errs) > 0 {
+		t.Errorf("errors reading tests before testing test coverage: %v", errs)
+	}
+	nestedField := func(name string, field *schema.Schema) *schema.Schema {
+		return &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Resource{Schema: map[string]*schema.Schema{name: field}},
+		}
+	}
+	resourceMap := map[string]*schema.Resource{
+		"covered_resource": {
+			Schema: map[string]*schema.Schema{
+				"field_one":   {Type: schema.TypeString, Optional: true},
+				"field_two":   nestedField("field_three", &schema.Schema{Type: schema.TypeString, Optional: true}),
+				"field_four":  nestedField("field_five", nestedField("field_six", &schema.Schema{Type: schema.TypeString, Optional: true})),
+				"field_seven": {Type: schema.TypeBool, Optional: true},
+				"output":      {Type: schema.TypeString, Computed: true},
+				"project":     {Type: schema.TypeString, Optional: true},
+			},
+		},
+		"uncovered_resource": {
+			Schema: map[string]*schema.Schema{
+				"field_one": {Type: schema.TypeString, Optional: true},
+				"field_two": nestedField("field_three", &schema.Schema{Type: schema.TypeString, Optional: true}),
+				"field_ten": {Type: schema.TypeString, Required: true},
+			},
+		},
+		"no_test": {
+			Schema: map[string]*schema.Schema{
+				"field_one": {Type: schema.TypeString, Required: true},
+			},
+		},
+		"no_fields": {
+			Schema: map[string]*schema.Schema{
+				"output": {Type: schema.TypeString, Computed: true},
+			},
+		},
+	}
+	services := map[string]string{
+		"covered_resource":   "service",
+		"uncovered_resource": "service",
+		"no_test":            "other",
+	}
+
+	got, err := ComputeTestCoverage(resourceMap, services, allTests)
+	if err != nil {
+		t.Fatalf("ComputeTestCoverage() returned error: %s", err)
+	}
+	want := CoverageReport{
+		Total: CoverageSummary{Fields: 8, TestedFields: 5, Percent: 62.5},
+		Services: []ServiceCoverage{
+			{Name: "other", Resources: 1, CoverageSummary: CoverageSummary{Fields: 1, TestedFields: 0, Percent: 0}},
+			{Name: "service", Resources: 2, CoverageSummary: CoverageSummary{Fields: 7, TestedFields: 5, Percent: 71.43}},
+			{Name: "unknown", Resources: 1, CoverageSummary: CoverageSummary{Fields: 0, TestedFields: 0, Percent: 100}},
+		},
+		Resources: []ResourceCoverage{
+			{
+				Name:            "covered_resource",
+				Service:         "service",
+				Tests:           1,
+				CoverageSummary: CoverageSummary{Fields: 4, TestedFields: 4, Percent: 100},
+				UntestedFields:  []string{},
+			},
+			{
+				Name:            "no_fields",
+				Service:         "unknown",
+				CoverageSummary: CoverageSummary{Fields: 0, TestedFields: 0, Percent: 100},
+				UntestedFields:  []string{},
+			},
+			{
+				Name:            "no_test",
+				Service:         "other",
+				CoverageSummary: CoverageSummary{Fields: 1, TestedFields: 0, Percent: 0},
+				UntestedFields:  []string{"field_one"},
+			},
+			{
+				Name:            "uncovered_resource",
+				Service:         "service",
+				Tests:           1,
+				CoverageSummary: CoverageSummary{Fields: 3, TestedFields: 1, Percent: 33.33},
+				UntestedFields:  []string{"field_one", "field_ten"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ComputeTestCoverage() returned unexpected report (-want, +got):\n%s", diff)
+	}
+}
+
 func TestDetectMissingDocs(t *testing.T) {
 	// If repo is not temp dir, then the doc file points to tools/diff-processor/testdata/website/docs/r/a_resource.html.markdown.
 	for _, test := range []struct {
@@ -446,3 +686,119 @@ func TestDetectMissingDocsForDatasource(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectReleaseNoteIssues(t *testing.T) {
+	schemaDiff := diff.SchemaDiff{
+		"google_compute_new": {
+			ResourceConfig: diff.ResourceConfigDiff{New: &schema.Resource{}},
+		},
+		"google_compute_instance": {
+			ResourceConfig: diff.ResourceConfigDiff{Old: &schema.Resource{}, New: &schema.Resource{}},
+		},
+	}
+	datasourceSchemaDiff := diff.SchemaDiff{
+		"google_compute_new": {
+			ResourceConfig: diff.ResourceConfigDiff{New: &schema.Resource{}},
+		},
+	}
+	services := map[string]string{
+		"google_compute_new":      "compute",
+		"google_compute_instance": "compute",
+		"google_pubsub_topic":     "pubsub",
+	}
+	breakingChanges := []breaking_changes.BreakingChange{
+		{Resource: "google_compute_instance", Field: "name", Message: "Field `name` changed from optional to required"},
+	}
+	for _, tc := range []struct {
+		name                 string
+		notes                []changelog.Note
+		schemaDiff           diff.SchemaDiff
+		datasourceSchemaDiff diff.SchemaDiff
+		breakingChanges      []breaking_changes.BreakingChange
+		want                 []ReleaseNoteIssue
+	}{
+		{
+			name:                 "matching notes",
+			schemaDiff:           schemaDiff,
+			datasourceSchemaDiff: datasourceSchemaDiff,
+			notes: []changelog.Note{
+				{Type: "new-resource", Body: "`google_compute_new`"},
+				{Type: "new-datasource", Body: "`google_compute_new`"},
+				{Type: "enhancement", Body: "compute: added `foo` field to `google_compute_instance` resource"},
+				{Type: "bug", Body: "provider: fixed retries"},
+			},
+		},
+		{
+			name:       "no notes",
+			schemaDiff: diff.SchemaDiff{},
+			want: []ReleaseNoteIssue{
+				{RuleID: RuleMissingReleaseNote, Message: "No release note was found. Add at least one release note block to the PR description."},
+			},
+		},
+		{
+			name:                 "missing new resource and datasource notes",
+			schemaDiff:           schemaDiff,
+			datasourceSchemaDiff: datasourceSchemaDiff,
+			notes: []changelog.Note{
+				{Type: "new-datasource", Body: "`google_compute_other`"},
+				{Type: "enhancement", Body: "compute: added `google_compute_new` resource"},
+			},
+			want: []ReleaseNoteIssue{
+				{RuleID: RuleMissingNewResourceNote, Resource: "google_compute_new", Message: "Resource `google_compute_new` was added, but there is no `new-resource` release note for it.", SuggestedNote: "```release-note:new-resource\n`google_compute_new`\n```"},
+				{RuleID: RuleMissingNewDatasourceNote, Resource: "google_compute_new", Message: "Datasource `google_compute_new` was added, but there is no `new-datasource` release note for it.", SuggestedNote: "```release-note:new-datasource\n`google_compute_new`\n```"},
+			},
+		},
+		{
+			name:            "breaking change labeled enhancement",
+			schemaDiff:      diff.SchemaDiff{},
+			breakingChanges: breakingChanges,
+			notes: []changelog.Note{
+				{Type: "enhancement", Body: "compute: made `name` required in `google_compute_instance`"},
+			},
+			want: []ReleaseNoteIssue{
+				{RuleID: RuleMissingBreakingChangeNote, Resource: "google_compute_instance", NoteType: "enhancement", Note: "compute: made `name` required in `google_compute_instance`", Message: "`google_compute_instance` has breaking changes, but no release note has the `breaking-change` type.", SuggestedNote: "```release-note:breaking-change\ncompute: made `name` required in `google_compute_instance`\n```"},
+			},
+		},
+		{
+			name:            "breaking change note",
+			schemaDiff:      diff.SchemaDiff{},
+			breakingChanges: breakingChanges,
+			notes: []changelog.Note{
+				{Type: "breaking-change", Body: "compute: made `name` required in `google_compute_instance`"},
+			},
+		},
+		{
+			name: "wrong service prefix",
+			schemaDiff: diff.SchemaDiff{
+				"google_compute_instance": schemaDiff["google_compute_instance"],
+			},
+			notes: []changelog.Note{
+				{Type: "enhancement", Body: "gce: added `foo` field to `google_compute_instance` resource"},
+				{Type: "bug", Body: "compute: fixed a perma-diff in `google_pubsub_topic`"},
+				{Type: "bug", Body: "container: fixed a crash"},
+			},
+			want: []ReleaseNoteIssue{
+				{RuleID: RuleWrongServicePrefix, NoteType: "enhancement", Note: "gce: added `foo` field to `google_compute_instance` resource", Message: "Release note starts with `gce:`, but the changed resources belong to `compute`. Use `provider:` for changes that affect the whole provider.", SuggestedNote: "```release-note:enhancement\ncompute: added `foo` field to `google_compute_instance` resource\n```"},
+				{RuleID: RuleWrongServicePrefix, NoteType: "bug", Note: "compute: fixed a perma-diff in `google_pubsub_topic`", Message: "Release note starts with `compute:`, but the changed resources belong to `pubsub`. Use `provider:` for changes that affect the whole provider.", SuggestedNote: "```release-note:bug\npubsub: fixed a perma-diff in `google_pubsub_topic`\n```"},
+				{RuleID: RuleWrongServicePrefix, NoteType: "bug", Note: "container: fixed a crash", Message: "Release note starts with `container:`, but the changed resources belong to `compute`. Use `provider:` for changes that affect the whole provider.", SuggestedNote: "```release-note:bug\ncompute: fixed a crash\n```"},
+			},
+		},
+		{
+			name:       "invalid note",
+			schemaDiff: diff.SchemaDiff{},
+			notes: []changelog.Note{
+				{Type: "feature", Body: "compute: added a feature"},
+			},
+			want: []ReleaseNoteIssue{
+				{RuleID: RuleInvalidReleaseNote, NoteType: "feature", Note: "compute: added a feature", Message: "unknown changelog types feature: please use only the configured changelog entry types: compute: added a feature"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := DetectReleaseNoteIssues(tc.notes, tc.schemaDiff, tc.datasourceSchemaDiff, tc.breakingChanges, services)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DetectReleaseNoteIssues() returned unexpected issues (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
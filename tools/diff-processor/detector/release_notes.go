@@ -0,0 +1,216 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/breaking_changes"
+	"github.com/GoogleCloudPlatform/magic-modules/tools/diff-processor/diff"
+	changelog "github.com/hashicorp/go-changelog"
+	"golang.org/x/exp/maps"
+)
+
+// Rules checked by DetectReleaseNoteIssues.
+const (
+	RuleInvalidReleaseNote        = "invalid-release-note"
+	RuleMissingReleaseNote        = "missing-release-note"
+	RuleMissingNewResourceNote    = "missing-new-resource-note"
+	RuleMissingNewDatasourceNote  = "missing-new-datasource-note"
+	RuleMissingBreakingChangeNote = "missing-breaking-change-note"
+	RuleWrongServicePrefix        = "wrong-service-prefix"
+)
+
+// providerServicePrefix is used for changes that affect the whole provider.
+const providerServicePrefix = "provider"
+
+var (
+	// servicePrefixPattern matches the product that release notes start with,
+	// such as "compute: added ...".
+	servicePrefixPattern = regexp.MustCompile(`^([a-z0-9_]+): `)
+	// resourceNamePattern matches the resource and datasource names that
+	// release notes mention.
+	resourceNamePattern = regexp.MustCompile("`(google_[a-z0-9_]+)`")
+)
+
+// ReleaseNoteIssue is a mismatch between the release notes of a PR and the
+// changes it makes.
+type ReleaseNoteIssue struct {
+	RuleID string
+	// Resource is the resource or datasource the issue is about, if any.
+	Resource string
+	// NoteType and Note are the type and body of the release note the issue is
+	// about, if any.
+	NoteType string
+	Note     string
+	Message  string
+	// SuggestedNote is a release note block that would fix the issue, if one
+	// can be generated from the schema diff.
+	SuggestedNote string
+}
+
+// DetectReleaseNoteIssues checks the release notes of a PR against its schema
+// diffs and breaking changes. Services are the products that release notes
+// for each resource should start with, keyed by resource name; the prefix
+// check is skipped for resources without a known service.
+func DetectReleaseNoteIssues(notes []changelog.Note, schemaDiff, datasourceSchemaDiff diff.SchemaDiff, breakingChanges []breaking_changes.BreakingChange, services map[string]string) []ReleaseNoteIssue {
+	var issues []ReleaseNoteIssue
+	if len(notes) == 0 {
+		issues = append(issues, ReleaseNoteIssue{
+			RuleID:  RuleMissingReleaseNote,
+			Message: "No release note was found. Add at least one release note block to the PR description.",
+		})
+	}
+
+	notedResources := make(map[string]map[string]struct{})
+	for i := range notes {
+		note := &notes[i]
+		if err := note.Validate(); err != nil {
+			issues = append(issues, ReleaseNoteIssue{
+				RuleID:   RuleInvalidReleaseNote,
+				NoteType: note.Type,
+				Note:     note.Body,
+				Message:  err.Error(),
+			})
+		}
+		if notedResources[note.Type] == nil {
+			notedResources[note.Type] = make(map[string]struct{})
+		}
+		for _, match := range resourceNamePattern.FindAllStringSubmatch(note.Body, -1) {
+			notedResources[note.Type][match[1]] = struct{}{}
+		}
+	}
+
+	for _, added := range []struct {
+		schemaDiff diff.SchemaDiff
+		noteType   string
+		ruleID     string
+		kind       string
+	}{
+		{schemaDiff, "new-resource", RuleMissingNewResourceNote, "Resource"},
+		{datasourceSchemaDiff, "new-datasource", RuleMissingNewDatasourceNote, "Datasource"},
+	} {
+		for _, resource := range sortedKeys(added.schemaDiff) {
+			resourceDiff := added.schemaDiff[resource]
+			if resourceDiff.ResourceConfig.Old != nil || resourceDiff.ResourceConfig.New == nil {
+				continue
+			}
+			if _, ok := notedResources[added.noteType][resource]; ok {
+				continue
+			}
+			issues = append(issues, ReleaseNoteIssue{
+				RuleID:        added.ruleID,
+				Resource:      resource,
+				Message:       fmt.Sprintf("%s `%s` was added, but there is no `%s` release note for it.", added.kind, resource, added.noteType),
+				SuggestedNote: releaseNoteBlock(added.noteType, "`"+resource+"`"),
+			})
+		}
+	}
+
+	if len(breakingChanges) > 0 && !hasNoteOfType(notes, "breaking-change") {
+		breakingResources := make(map[string]struct{})
+		for _, breakingChange := range breakingChanges {
+			breakingResources[breakingChange.Resource] = struct{}{}
+		}
+		resources := maps.Keys(breakingResources)
+		sort.Strings(resources)
+		for _, resource := range resources {
+			issue := ReleaseNoteIssue{
+				RuleID:   RuleMissingBreakingChangeNote,
+				Resource: resource,
+				Message:  fmt.Sprintf("`%s` has breaking changes, but no release note has the `breaking-change` type.", resource),
+			}
+			// Suggest changing the type of the note that describes the change.
+			for _, note := range notes {
+				if strings.Contains(note.Body, "`"+resource+"`") {
+					issue.NoteType = note.Type
+					issue.Note = note.Body
+					issue.SuggestedNote = releaseNoteBlock("breaking-change", note.Body)
+					break
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	changedServices := make(map[string]struct{})
+	for _, resource := range append(maps.Keys(schemaDiff), maps.Keys(datasourceSchemaDiff)...) {
+		if service, ok := services[resource]; ok && service != "" {
+			changedServices[service] = struct{}{}
+		}
+	}
+	for i := range notes {
+		if issue, ok := checkServicePrefix(&notes[i], changedServices, services); ok {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// checkServicePrefix checks that a release note starts with the service of
+// the resources it mentions, or of one of the changed resources if it doesn't
+// mention any with a known service.
+func checkServicePrefix(note *changelog.Note, changedServices map[string]struct{}, services map[string]string) (ReleaseNoteIssue, bool) {
+	match := servicePrefixPattern.FindStringSubmatch(note.Body)
+	if match == nil || match[1] == providerServicePrefix {
+		return ReleaseNoteIssue{}, false
+	}
+	prefix := match[1]
+	expected := make(map[string]struct{})
+	for _, resource := range resourceNamePattern.FindAllStringSubmatch(note.Body, -1) {
+		if service, ok := services[resource[1]]; ok && service != "" {
+			expected[service] = struct{}{}
+		}
+	}
+	if len(expected) == 0 {
+		expected = changedServices
+	}
+	if len(expected) == 0 {
+		return ReleaseNoteIssue{}, false
+	}
+	if _, ok := expected[prefix]; ok {
+		return ReleaseNoteIssue{}, false
+	}
+	expectedServices := maps.Keys(expected)
+	sort.Strings(expectedServices)
+	issue := ReleaseNoteIssue{
+		RuleID:   RuleWrongServicePrefix,
+		NoteType: note.Type,
+		Note:     note.Body,
+		Message:  fmt.Sprintf("Release note starts with `%s:`, but the changed resources belong to %s. Use `%s:` for changes that affect the whole provider.", prefix, quoteServices(expectedServices), providerServicePrefix),
+	}
+	if len(expectedServices) == 1 {
+		issue.SuggestedNote = releaseNoteBlock(note.Type, expectedServices[0]+": "+strings.TrimPrefix(note.Body, match[0]))
+	}
+	return issue, true
+}
+
+// releaseNoteBlock formats a release note the way it's written in PR
+// descriptions.
+func releaseNoteBlock(noteType, body string) string {
+	return fmt.Sprintf("```release-note:%s\n%s\n```", noteType, body)
+}
+
+func hasNoteOfType(notes []changelog.Note, noteType string) bool {
+	for _, note := range notes {
+		if note.Type == noteType {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteServices(services []string) string {
+	quoted := make([]string, len(services))
+	for i, service := range services {
+		quoted[i] = "`" + service + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func sortedKeys(schemaDiff diff.SchemaDiff) []string {
+	keys := maps.Keys(schemaDiff)
+	sort.Strings(keys)
+	return keys
+}
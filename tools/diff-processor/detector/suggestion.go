@@ -0,0 +1,171 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fmtVerbPattern matches the fmt substitutions in test configs, the same way
+// the test reader does.
+var fmtVerbPattern = regexp.MustCompile("%({[^{}]*}|[vTtbcspqxXUeEfFgGdo])")
+
+// enumValuesPattern matches the list of possible values that mmv1 adds to the
+// description of enum fields.
+var enumValuesPattern = regexp.MustCompile(`Possible values: \[("[^\]]*")\]`)
+
+// suggestedTest returns a test config that sets the untested fields of the
+// resource. It starts from the first of the given configs that contains the
+// resource, and from an empty config if there is none. Values are placeholders
+// derived from the flattened resource schema, which may be nil.
+func suggestedTest(resourceName string, untested []string, resourceSchema map[string]*schema.Schema, configs []string) string {
+	for _, config := range configs {
+		if suggestion, err := suggestedTestFromConfig(resourceName, untested, resourceSchema, config); err == nil {
+			return suggestion
+		}
+	}
+	f := hclwrite.NewEmptyFile()
+	body := f.Body().AppendNewBlock("resource", []string{resourceName, "primary"}).Body()
+	addRequiredFields(body, "", resourceSchema)
+	addFields(body, untested, resourceSchema)
+	return string(hclwrite.Format(f.Bytes()))
+}
+
+func suggestedTestFromConfig(resourceName string, untested []string, resourceSchema map[string]*schema.Schema, config string) (string, error) {
+	// Substitutions aren't valid HCL, so they are swapped for identifiers
+	// while the config is edited.
+	var substitutions []string
+	config = fmtVerbPattern.ReplaceAllStringFunc(config, func(verb string) string {
+		substitutions = append(substitutions, verb)
+		return fmt.Sprintf("fmt_substitution_%d", len(substitutions)-1)
+	})
+	f, diags := hclwrite.ParseConfig([]byte(config), "config.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	var resourceBlock *hclwrite.Block
+	for _, block := range f.Body().Blocks() {
+		if labels := block.Labels(); block.Type() == "resource" && len(labels) == 2 && labels[0] == resourceName {
+			resourceBlock = block
+			break
+		}
+	}
+	if resourceBlock == nil {
+		return "", fmt.Errorf("no %s resource in config", resourceName)
+	}
+	addFields(resourceBlock.Body(), untested, resourceSchema)
+	suggestion := strings.TrimSpace(string(hclwrite.Format(f.Bytes()))) + "\n"
+	// Replace in reverse so that fmt_substitution_1 doesn't match fmt_substitution_10.
+	for i := len(substitutions) - 1; i >= 0; i-- {
+		suggestion = strings.ReplaceAll(suggestion, fmt.Sprintf("fmt_substitution_%d", i), substitutions[i])
+	}
+	return suggestion, nil
+}
+
+// addFields sets each field (a flattened field name) in body, adding the
+// blocks on its path along with their required fields.
+func addFields(body *hclwrite.Body, fields []string, resourceSchema map[string]*schema.Schema) {
+	for _, field := range fields {
+		fieldBody := body
+		path := strings.Split(field, ".")
+		for i, name := range path {
+			key := strings.Join(path[:i+1], ".")
+			if i == len(path)-1 {
+				if fieldBody.GetAttribute(name) == nil {
+					fieldBody.SetAttributeValue(name, placeholderValue(resourceSchema[key]))
+				}
+				break
+			}
+			if fieldBody.GetAttribute(name) != nil {
+				// The parent is set as an attribute, which can't be extended.
+				break
+			}
+			block := fieldBody.FirstMatchingBlock(name, nil)
+			if block == nil {
+				block = fieldBody.AppendNewBlock(name, nil)
+				addRequiredFields(block.Body(), key, resourceSchema)
+			}
+			fieldBody = block.Body()
+		}
+	}
+}
+
+// addRequiredFields sets the required fields directly under parentKey that
+// aren't set yet, recursing into required blocks.
+func addRequiredFields(body *hclwrite.Body, parentKey string, resourceSchema map[string]*schema.Schema) {
+	prefix := ""
+	if parentKey != "" {
+		prefix = parentKey + "."
+	}
+	var required []string
+	for key, fieldSchema := range resourceSchema {
+		if !strings.HasPrefix(key, prefix) || strings.Contains(strings.TrimPrefix(key, prefix), ".") {
+			continue
+		}
+		if fieldSchema.Required {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+	for _, key := range required {
+		name := strings.TrimPrefix(key, prefix)
+		if body.GetAttribute(name) != nil || body.FirstMatchingBlock(name, nil) != nil {
+			continue
+		}
+		if _, ok := resourceSchema[key].Elem.(*schema.Resource); ok {
+			addRequiredFields(body.AppendNewBlock(name, nil).Body(), key, resourceSchema)
+			continue
+		}
+		body.SetAttributeValue(name, placeholderValue(resourceSchema[key]))
+	}
+}
+
+// placeholderValue returns a value of the field's type. Enum fields get their
+// first possible value.
+func placeholderValue(fieldSchema *schema.Schema) cty.Value {
+	if fieldSchema == nil {
+		return cty.StringVal("value")
+	}
+	enumValue := firstEnumValue(fieldSchema.Description)
+	switch fieldSchema.Type {
+	case schema.TypeBool:
+		return cty.True
+	case schema.TypeInt:
+		return cty.NumberIntVal(1)
+	case schema.TypeFloat:
+		return cty.NumberFloatVal(1.5)
+	case schema.TypeList, schema.TypeSet:
+		return cty.ListVal([]cty.Value{elemPlaceholderValue(fieldSchema.Elem, enumValue)})
+	case schema.TypeMap:
+		return cty.MapVal(map[string]cty.Value{"key": elemPlaceholderValue(fieldSchema.Elem, "")})
+	}
+	if enumValue != "" {
+		return cty.StringVal(enumValue)
+	}
+	return cty.StringVal("value")
+}
+
+func elemPlaceholderValue(elem any, enumValue string) cty.Value {
+	if elemSchema, ok := elem.(*schema.Schema); ok && elemSchema.Type != schema.TypeString {
+		return placeholderValue(elemSchema)
+	}
+	if enumValue != "" {
+		return cty.StringVal(enumValue)
+	}
+	return cty.StringVal("value")
+}
+
+func firstEnumValue(description string) string {
+	match := enumValuesPattern.FindStringSubmatch(description)
+	if match == nil {
+		return ""
+	}
+	value, _, _ := strings.Cut(strings.TrimPrefix(match[1], `"`), `"`)
+	return value
+}
@@ -0,0 +1,208 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api"
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/google"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// ApiTypes is a nested map with Terraform resource names as top-level keys
+// and flattened field names (as used in ResourceDiff.Fields) as second-level keys.
+// It holds the mmv1 definition of each field, which carries validation details
+// (enum values, regexes, validation functions) that can't be read back from
+// the schema.
+type ApiTypes map[string]map[string]*api.Type
+
+type ApiTypeDiff struct {
+	Old *api.Type
+	New *api.Type
+}
+
+// LoadApiTypes reads the mmv1 product and resource definitions in productsDir
+// (usually mmv1/products). Files are parsed leniently so that definitions from
+// older commits still load if fields have since been added to or removed from api.Type.
+func LoadApiTypes(productsDir string) (ApiTypes, error) {
+	productFiles, err := filepath.Glob(filepath.Join(productsDir, "*", "product.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	apiTypes := make(ApiTypes)
+	for _, productFile := range productFiles {
+		product := &api.Product{}
+		if err := unmarshalYamlFile(productFile, product); err != nil {
+			return nil, err
+		}
+		resourceFiles, err := filepath.Glob(filepath.Join(filepath.Dir(productFile), "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, resourceFile := range resourceFiles {
+			if filepath.Base(resourceFile) == "product.yaml" {
+				continue
+			}
+			resource := &api.Resource{}
+			if err := unmarshalYamlFile(resourceFile, resource, "examples"); err != nil {
+				return nil, err
+			}
+			if resource.Exclude || resource.ExcludeResource {
+				continue
+			}
+			resource.ProductMetadata = product
+			fields := make(map[string]*api.Type)
+			flattenApiTypes("", resource.AllProperties(), fields)
+			apiTypes[resource.TerraformName()] = fields
+		}
+	}
+	return apiTypes, nil
+}
+
+// unmarshalYamlFile parses the YAML file at path into obj, leaving out the
+// given top-level keys. Examples are skipped when loading resources because
+// they read their config templates relative to the mmv1 directory.
+func unmarshalYamlFile(path string, obj interface{}, skipKeys ...string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(skipKeys) > 0 {
+		var fields yaml.MapSlice
+		if err := yaml.Unmarshal(content, &fields); err != nil {
+			return fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		fields = slices.DeleteFunc(fields, func(item yaml.MapItem) bool {
+			key, _ := item.Key.(string)
+			return slices.Contains(skipKeys, key)
+		})
+		if content, err = yaml.Marshal(fields); err != nil {
+			return fmt.Errorf("error parsing %s: %w", path, err)
+		}
+	}
+	if err := yaml.Unmarshal(content, obj); err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// flattenApiTypes mirrors flattenSchema for mmv1 properties.
+func flattenApiTypes(parentKey string, properties []*api.Type, flattened map[string]*api.Type) {
+	for _, property := range properties {
+		if property == nil || property.Exclude {
+			continue
+		}
+		// Flattened objects don't exist in the schema; their properties are
+		// added to the parent.
+		if property.FlattenObject {
+			flattenApiTypes(parentKey, property.Properties, flattened)
+			continue
+		}
+		key := google.Underscore(property.Name)
+		if parentKey != "" {
+			key = parentKey + "." + key
+		}
+		flattened[key] = property
+		switch {
+		case property.IsA("NestedObject"):
+			flattenApiTypes(key, property.Properties, flattened)
+		case property.IsA("Array") && property.ItemType != nil && property.ItemType.IsA("NestedObject"):
+			flattenApiTypes(key, property.ItemType.Properties, flattened)
+		case property.IsA("Map") && property.ValueType != nil:
+			flattenApiTypes(key, property.ValueType.Properties, flattened)
+		}
+	}
+}
+
+// ComputeSchemaDiffWithApiTypes computes the schema diff and additionally
+// records fields whose mmv1 validation or write-only setting changed.
+// Validation functions are opaque in the schema, so for example removing an
+// enum value doesn't show up in the schema diff on its own.
+func ComputeSchemaDiffWithApiTypes(oldResourceMap, newResourceMap map[string]*schema.Resource, oldApiTypes, newApiTypes ApiTypes) SchemaDiff {
+	schemaDiff := ComputeSchemaDiff(oldResourceMap, newResourceMap)
+	for resource, oldFields := range oldApiTypes {
+		newFields, ok := newApiTypes[resource]
+		if !ok {
+			continue
+		}
+		oldResource, ok := oldResourceMap[resource]
+		if !ok {
+			continue
+		}
+		newResource, ok := newResourceMap[resource]
+		if !ok {
+			continue
+		}
+		var flattenedOldSchema, flattenedNewSchema map[string]*schema.Schema
+		for key, oldType := range oldFields {
+			newType, ok := newFields[key]
+			if !ok || !apiTypeChanged(oldType, newType) {
+				continue
+			}
+			if flattenedOldSchema == nil {
+				flattenedOldSchema = flattenSchema("", oldResource.Schema)
+				flattenedNewSchema = flattenSchema("", newResource.Schema)
+			}
+			oldField, newField := flattenedOldSchema[key], flattenedNewSchema[key]
+			if oldField == nil || newField == nil {
+				continue
+			}
+			resourceDiff, ok := schemaDiff[resource]
+			if !ok {
+				resourceDiff = ResourceDiff{
+					ResourceConfig: ResourceConfigDiff{
+						Old: &schema.Resource{},
+						New: &schema.Resource{},
+					},
+					FlattenedSchema: FlattenedSchemaRaw{
+						Old: flattenedOldSchema,
+						New: flattenedNewSchema,
+					},
+					Fields: make(map[string]FieldDiff),
+				}
+			}
+			fieldDiff, ok := resourceDiff.Fields[key]
+			if !ok {
+				fieldDiff = FieldDiff{
+					Old: oldField,
+					New: newField,
+				}
+			}
+			fieldDiff.ApiType = ApiTypeDiff{
+				Old: oldType,
+				New: newType,
+			}
+			resourceDiff.Fields[key] = fieldDiff
+			schemaDiff[resource] = resourceDiff
+		}
+	}
+	return schemaDiff
+}
+
+func apiTypeChanged(oldType, newType *api.Type) bool {
+	if oldType.WriteOnly != newType.WriteOnly {
+		return true
+	}
+	if !slices.Equal(EnumValues(oldType), EnumValues(newType)) {
+		return true
+	}
+	if oldType.Validation != newType.Validation || oldType.ItemValidation != newType.ItemValidation {
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values of an Enum field, or of the items of
+// an Array of Enums.
+func EnumValues(t *api.Type) []string {
+	if t == nil {
+		return nil
+	}
+	if t.ItemType != nil {
+		return t.ItemType.EnumValues
+	}
+	return t.EnumValues
+}
@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testProductYaml = `
+name: 'ServiceOne'
+display_name: 'Service One'
+versions:
+  - name: 'ga'
+    base_url: 'https://serviceone.googleapis.com/v1/'
+`
+
+const testResourceYaml = `
+name: 'ResourceOne'
+base_url: 'projects/{{project}}/resourceOnes'
+unknown_future_field: true
+parameters:
+  - name: 'location'
+    type: String
+    required: true
+properties:
+  - name: 'tier'
+    type: Enum
+    enum_values:
+      - 'BASIC'
+      - 'PREMIUM'
+  - name: 'displayName'
+    type: String
+    validation:
+      regex: '^[a-z]+$'
+  - name: 'settings'
+    type: NestedObject
+    properties:
+      - name: 'retryCount'
+        type: Integer
+        validation:
+          function: 'validation.IntBetween(0, 10)'
+  - name: 'rules'
+    type: Array
+    item_type:
+      type: NestedObject
+      properties:
+        - name: 'action'
+          type: Enum
+          enum_values:
+            - 'ALLOW'
+  - name: 'flattened'
+    type: NestedObject
+    flatten_object: true
+    properties:
+      - name: 'innerField'
+        type: String
+  - name: 'excludedField'
+    type: String
+    exclude: true
+`
+
+func TestLoadApiTypes(t *testing.T) {
+	productsDir := t.TempDir()
+	productDir := filepath.Join(productsDir, "serviceone")
+	if err := os.MkdirAll(productDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(productDir, "product.yaml"), []byte(testProductYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(productDir, "ResourceOne.yaml"), []byte(testResourceYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	apiTypes, err := LoadApiTypes(productsDir)
+	if err != nil {
+		t.Fatalf("LoadApiTypes() returned error: %s", err)
+	}
+	fields, ok := apiTypes["google_service_one_resource_one"]
+	if !ok {
+		t.Fatalf("LoadApiTypes() did not load google_service_one_resource_one, got %v", apiTypes)
+	}
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"display_name", "inner_field", "location", "rules", "rules.action", "settings", "settings.retry_count", "tier"}
+	if diff := cmp.Diff(wantKeys, keys); diff != "" {
+		t.Errorf("LoadApiTypes() returned unexpected fields (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"BASIC", "PREMIUM"}, fields["tier"].EnumValues); diff != "" {
+		t.Errorf("LoadApiTypes() returned unexpected enum values (-want, +got):\n%s", diff)
+	}
+	if got, want := fields["settings.retry_count"].Validation.Function, "validation.IntBetween(0, 10)"; got != want {
+		t.Errorf("LoadApiTypes() returned validation function %q, want %q", got, want)
+	}
+}
+
+func TestComputeSchemaDiffWithApiTypes(t *testing.T) {
+	resourceMap := map[string]*schema.Resource{
+		"google_service_one_resource_one": {
+			Schema: map[string]*schema.Schema{
+				"tier": {Type: schema.TypeString, Optional: true},
+				"name": {Type: schema.TypeString, Optional: true},
+			},
+		},
+	}
+	oldTier := &api.Type{Name: "tier", Type: "Enum", EnumValues: []string{"BASIC", "PREMIUM"}}
+	newTier := &api.Type{Name: "tier", Type: "Enum", EnumValues: []string{"BASIC"}}
+	name := &api.Type{Name: "name", Type: "String"}
+	oldApiTypes := ApiTypes{
+		"google_service_one_resource_one": {"tier": oldTier, "name": name},
+	}
+	newApiTypes := ApiTypes{
+		"google_service_one_resource_one": {"tier": newTier, "name": name},
+	}
+
+	schemaDiff := ComputeSchemaDiffWithApiTypes(resourceMap, resourceMap, oldApiTypes, newApiTypes)
+	resourceDiff, ok := schemaDiff["google_service_one_resource_one"]
+	if !ok {
+		t.Fatalf("ComputeSchemaDiffWithApiTypes() did not return a diff for the resource")
+	}
+	if len(resourceDiff.Fields) != 1 {
+		t.Fatalf("ComputeSchemaDiffWithApiTypes() returned %d field diffs, want 1", len(resourceDiff.Fields))
+	}
+	fieldDiff, ok := resourceDiff.Fields["tier"]
+	if !ok {
+		t.Fatalf("ComputeSchemaDiffWithApiTypes() did not return a diff for field tier")
+	}
+	if fieldDiff.ApiType.Old != oldTier || fieldDiff.ApiType.New != newTier {
+		t.Errorf("ComputeSchemaDiffWithApiTypes() returned unexpected api types %v", fieldDiff.ApiType)
+	}
+	if fieldDiff.Old == nil || fieldDiff.New == nil {
+		t.Errorf("ComputeSchemaDiffWithApiTypes() did not set the field schemas")
+	}
+	if resourceDiff.IsNewResource() {
+		t.Errorf("ComputeSchemaDiffWithApiTypes() returned a diff for a new resource")
+	}
+}
@@ -14,6 +14,8 @@ type SchemaDiff map[string]ResourceDiff
 type ResourceDiffInterface interface {
 	IsNewResource() bool
 	IsFieldInNewNestedStructure(fieldPath string) bool
+	OldField(fieldPath string) *schema.Schema
+	NewField(fieldPath string) *schema.Schema
 }
 
 type ResourceDiff struct {
@@ -21,6 +23,7 @@ type ResourceDiff struct {
 	FlattenedSchema FlattenedSchemaRaw
 	Fields          map[string]FieldDiff
 	FieldSets       ResourceFieldSetsDiff
+	Metadata        ResourceMetadataDiff
 }
 
 type ResourceFieldSetsDiff struct {
@@ -45,6 +48,9 @@ type ResourceConfigDiff struct {
 type FieldDiff struct {
 	Old *schema.Schema
 	New *schema.Schema
+	// ApiType is only set for mmv1 fields whose validation or write-only
+	// setting changed, when the diff is computed with ComputeSchemaDiffWithApiTypes.
+	ApiType ApiTypeDiff
 }
 
 type FlattenedSchemaRaw struct {
@@ -64,6 +70,7 @@ func ComputeSchemaDiff(oldResourceMap, newResourceMap map[string]*schema.Resourc
 			flattenedOldSchema = flattenSchema("", oldResource.Schema)
 			resourceDiff.FlattenedSchema.Old = flattenedOldSchema
 			resourceDiff.ResourceConfig.Old = &schema.Resource{}
+			resourceDiff.Metadata.Old = resourceMetadata(oldResource)
 		}
 
 		var flattenedNewSchema map[string]*schema.Schema
@@ -71,6 +78,7 @@ func ComputeSchemaDiff(oldResourceMap, newResourceMap map[string]*schema.Resourc
 			flattenedNewSchema = flattenSchema("", newResource.Schema)
 			resourceDiff.FlattenedSchema.New = flattenedNewSchema
 			resourceDiff.ResourceConfig.New = &schema.Resource{}
+			resourceDiff.Metadata.New = resourceMetadata(newResource)
 		}
 
 		resourceDiff.Fields = make(map[string]FieldDiff)
@@ -82,13 +90,20 @@ func ComputeSchemaDiff(oldResourceMap, newResourceMap map[string]*schema.Resourc
 				resourceDiff.FieldSets = mergeFieldSetsDiff(resourceDiff.FieldSets, fieldSetsDiff)
 			}
 		}
-		if len(resourceDiff.Fields) > 0 || !cmp.Equal(resourceDiff.ResourceConfig.Old, resourceDiff.ResourceConfig.New) {
+		metadataChanged := resourceDiff.ResourceConfig.Old != nil && resourceDiff.ResourceConfig.New != nil && !cmp.Equal(resourceDiff.Metadata.Old, resourceDiff.Metadata.New)
+		if len(resourceDiff.Fields) > 0 || !cmp.Equal(resourceDiff.ResourceConfig.Old, resourceDiff.ResourceConfig.New) || metadataChanged {
 			schemaDiff[resource] = resourceDiff
 		}
 	}
 	return schemaDiff
 }
 
+// FlattenSchema returns the fields of a resource schema, including nested
+// fields, keyed by their dot-separated path.
+func FlattenSchema(schemaObj map[string]*schema.Schema) map[string]*schema.Schema {
+	return flattenSchema("", schemaObj)
+}
+
 func flattenSchema(parentKey string, schemaObj map[string]*schema.Schema) map[string]*schema.Schema {
 	flattened := make(map[string]*schema.Schema)
 
@@ -356,3 +371,15 @@ func (rd ResourceDiff) IsFieldInNewNestedStructure(fieldPath string) bool {
 
 	return !parentExistsInOld && parentExistsInNew
 }
+
+// OldField returns the schema of a field in the old version of the resource,
+// or nil if the field is new
+func (rd ResourceDiff) OldField(fieldPath string) *schema.Schema {
+	return rd.FlattenedSchema.Old[removeZeroPadding(fieldPath)]
+}
+
+// NewField returns the schema of a field in the new version of the resource,
+// or nil if the field was removed
+func (rd ResourceDiff) NewField(fieldPath string) *schema.Schema {
+	return rd.FlattenedSchema.New[removeZeroPadding(fieldPath)]
+}
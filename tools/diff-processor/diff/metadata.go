@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/magic-modules/mmv1/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// ResourceMetadata holds the resource properties that determine whether IDs
+// and state stored by an older provider version can still be used.
+type ResourceMetadata struct {
+	// IdFormat and ImportFormats are read from the generated metadata files
+	// of mmv1 resources, and are empty for handwritten resources.
+	IdFormat      string
+	ImportFormats []string
+
+	Timeouts              *schema.ResourceTimeout
+	SchemaVersion         int
+	StateUpgraderVersions []int
+	HasMigrateState       bool
+}
+
+type ResourceMetadataDiff struct {
+	Old ResourceMetadata
+	New ResourceMetadata
+}
+
+func resourceMetadata(resource *schema.Resource) ResourceMetadata {
+	if resource == nil {
+		return ResourceMetadata{}
+	}
+	var upgraderVersions []int
+	for _, upgrader := range resource.StateUpgraders {
+		upgraderVersions = append(upgraderVersions, upgrader.Version)
+	}
+	return ResourceMetadata{
+		Timeouts:              resource.Timeouts,
+		SchemaVersion:         resource.SchemaVersion,
+		StateUpgraderVersions: upgraderVersions,
+		HasMigrateState:       resource.MigrateState != nil,
+	}
+}
+
+// resourceMetadataFile is the subset of a generated resource_*_meta.yaml file
+// used by the diff processor.
+type resourceMetadataFile struct {
+	Resource      string   `yaml:"resource"`
+	SourceFile    string   `yaml:"source_file"`
+	IdFormat      string   `yaml:"id_format"`
+	ImportFormats []string `yaml:"import_format"`
+
+	// service is the name of the directory containing the file.
+	service string
+}
+
+// LoadIdFormats reads the id and import formats from the generated metadata
+// files in a provider directory, keyed by resource name.
+func LoadIdFormats(providerDir string) (map[string]ResourceMetadata, error) {
+	files, err := loadResourceMetadataFiles(providerDir)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]ResourceMetadata)
+	for _, file := range files {
+		if file.IdFormat == "" {
+			continue
+		}
+		metadata[file.Resource] = ResourceMetadata{
+			IdFormat:      file.IdFormat,
+			ImportFormats: file.ImportFormats,
+		}
+	}
+	return metadata, nil
+}
+
+// LoadSourceFiles reads the mmv1 YAML file each generated resource in a
+// provider directory was built from, keyed by resource name. Paths are
+// relative to the root of the Magic Modules repository.
+func LoadSourceFiles(providerDir string) (map[string]string, error) {
+	files, err := loadResourceMetadataFiles(providerDir)
+	if err != nil {
+		return nil, err
+	}
+	sourceFiles := make(map[string]string)
+	for _, file := range files {
+		if file.SourceFile == "" {
+			continue
+		}
+		sourceFiles[file.Resource] = path.Join(api.RELATIVE_MAGICIAN_LOCATION, file.SourceFile)
+	}
+	return sourceFiles, nil
+}
+
+// LoadResourceServices reads the service of each resource in a provider
+// directory from the location of its metadata file, keyed by resource name.
+func LoadResourceServices(providerDir string) (map[string]string, error) {
+	files, err := loadResourceMetadataFiles(providerDir)
+	if err != nil {
+		return nil, err
+	}
+	services := make(map[string]string)
+	for _, file := range files {
+		services[file.Resource] = file.service
+	}
+	return services, nil
+}
+
+func loadResourceMetadataFiles(providerDir string) ([]resourceMetadataFile, error) {
+	var files []resourceMetadataFile
+	err := filepath.WalkDir(providerDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasPrefix(d.Name(), "resource_") || !strings.HasSuffix(d.Name(), "_meta.yaml") {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		var file resourceMetadataFile
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			return fmt.Errorf("error parsing %s: %w", filePath, err)
+		}
+		if file.Resource != "" {
+			file.service = filepath.Base(filepath.Dir(filePath))
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// AddIdFormats records the id and import formats of resources present in both
+// versions, adding resources to the diff if only their formats changed.
+func AddIdFormats(schemaDiff SchemaDiff, oldIdFormats, newIdFormats map[string]ResourceMetadata) {
+	for resource, oldFormats := range oldIdFormats {
+		newFormats, ok := newIdFormats[resource]
+		if !ok {
+			continue
+		}
+		resourceDiff, ok := schemaDiff[resource]
+		if !ok {
+			if oldFormats.IdFormat == newFormats.IdFormat && slices.Equal(oldFormats.ImportFormats, newFormats.ImportFormats) {
+				continue
+			}
+			resourceDiff = ResourceDiff{
+				ResourceConfig: ResourceConfigDiff{
+					Old: &schema.Resource{},
+					New: &schema.Resource{},
+				},
+				Fields: make(map[string]FieldDiff),
+			}
+		}
+		// Resources that were added or removed don't have metadata to compare.
+		if resourceDiff.ResourceConfig.Old == nil || resourceDiff.ResourceConfig.New == nil {
+			continue
+		}
+		resourceDiff.Metadata.Old.IdFormat = oldFormats.IdFormat
+		resourceDiff.Metadata.Old.ImportFormats = oldFormats.ImportFormats
+		resourceDiff.Metadata.New.IdFormat = newFormats.IdFormat
+		resourceDiff.Metadata.New.ImportFormats = newFormats.ImportFormats
+		schemaDiff[resource] = resourceDiff
+	}
+}
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func writeTestMetadataFiles(t *testing.T) string {
+	t.Helper()
+	providerDir := t.TempDir()
+	serviceDir := filepath.Join(providerDir, "google", "services", "pubsub")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"resource_pubsub_topic_generated_meta.yaml": `resource: 'google_pubsub_topic'
+generation_type: 'mmv1'
+source_file: 'products/pubsub/Topic.yaml'
+id_format: 'projects/{{project}}/topics/{{name}}'
+import_format:
+  - 'projects/{{project}}/topics/{{name}}'
+  - '{{name}}'
+fields:
+  - field: 'name'
+`,
+		"resource_pubsub_handwritten_meta.yaml": `resource: 'google_pubsub_handwritten'
+generation_type: 'handwritten'
+`,
+		"resource_pubsub_topic.go": "package pubsub\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(serviceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return providerDir
+}
+
+func TestLoadIdFormats(t *testing.T) {
+	providerDir := writeTestMetadataFiles(t)
+
+	got, err := LoadIdFormats(providerDir)
+	if err != nil {
+		t.Fatalf("LoadIdFormats() returned error: %s", err)
+	}
+	want := map[string]ResourceMetadata{
+		"google_pubsub_topic": {
+			IdFormat:      "projects/{{project}}/topics/{{name}}",
+			ImportFormats: []string{"projects/{{project}}/topics/{{name}}", "{{name}}"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadIdFormats() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLoadSourceFiles(t *testing.T) {
+	providerDir := writeTestMetadataFiles(t)
+
+	got, err := LoadSourceFiles(providerDir)
+	if err != nil {
+		t.Fatalf("LoadSourceFiles() returned error: %s", err)
+	}
+	want := map[string]string{
+		"google_pubsub_topic": "mmv1/products/pubsub/Topic.yaml",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadSourceFiles() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLoadResourceServices(t *testing.T) {
+	providerDir := writeTestMetadataFiles(t)
+
+	got, err := LoadResourceServices(providerDir)
+	if err != nil {
+		t.Fatalf("LoadResourceServices() returned error: %s", err)
+	}
+	want := map[string]string{
+		"google_pubsub_topic":       "pubsub",
+		"google_pubsub_handwritten": "pubsub",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadResourceServices() returned unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestComputeSchemaDiffMetadata(t *testing.T) {
+	timeout := 20 * time.Minute
+	oldResourceMap := map[string]*schema.Resource{
+		"google_x": {
+			Schema: map[string]*schema.Schema{"field_a": {Type: schema.TypeString, Optional: true}},
+		},
+	}
+	newResourceMap := map[string]*schema.Resource{
+		"google_x": {
+			Schema:        map[string]*schema.Schema{"field_a": {Type: schema.TypeString, Optional: true}},
+			Timeouts:      &schema.ResourceTimeout{Create: &timeout},
+			SchemaVersion: 1,
+			StateUpgraders: []schema.StateUpgrader{
+				{Version: 0},
+			},
+		},
+	}
+
+	schemaDiff := ComputeSchemaDiff(oldResourceMap, newResourceMap)
+	want := ResourceMetadataDiff{
+		New: ResourceMetadata{
+			Timeouts:              &schema.ResourceTimeout{Create: &timeout},
+			SchemaVersion:         1,
+			StateUpgraderVersions: []int{0},
+		},
+	}
+	if diff := cmp.Diff(want, schemaDiff["google_x"].Metadata); diff != "" {
+		t.Errorf("ComputeSchemaDiff() returned unexpected metadata diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAddIdFormats(t *testing.T) {
+	schemaDiff := SchemaDiff{
+		"google_added": ResourceDiff{
+			ResourceConfig: ResourceConfigDiff{New: &schema.Resource{}},
+		},
+	}
+	oldIdFormats := map[string]ResourceMetadata{
+		"google_changed":   {IdFormat: "{{name}}"},
+		"google_unchanged": {IdFormat: "{{name}}"},
+		"google_removed":   {IdFormat: "{{name}}"},
+		"google_added":     {IdFormat: "{{name}}"},
+	}
+	newIdFormats := map[string]ResourceMetadata{
+		"google_changed":   {IdFormat: "projects/{{project}}/{{name}}"},
+		"google_unchanged": {IdFormat: "{{name}}"},
+		"google_added":     {IdFormat: "{{name}}"},
+	}
+
+	AddIdFormats(schemaDiff, oldIdFormats, newIdFormats)
+
+	if _, ok := schemaDiff["google_unchanged"]; ok {
+		t.Errorf("AddIdFormats() added a resource with unchanged formats")
+	}
+	if _, ok := schemaDiff["google_removed"]; ok {
+		t.Errorf("AddIdFormats() added a removed resource")
+	}
+	if got := schemaDiff["google_added"].Metadata; got.Old.IdFormat != "" || got.New.IdFormat != "" {
+		t.Errorf("AddIdFormats() set formats on an added resource: %v", got)
+	}
+	changed, ok := schemaDiff["google_changed"]
+	if !ok {
+		t.Fatalf("AddIdFormats() did not add a resource with changed formats")
+	}
+	if changed.Metadata.Old.IdFormat != "{{name}}" || changed.Metadata.New.IdFormat != "projects/{{project}}/{{name}}" {
+		t.Errorf("AddIdFormats() returned unexpected formats: %v", changed.Metadata)
+	}
+	if changed.IsNewResource() {
+		t.Errorf("AddIdFormats() added the resource as a new resource")
+	}
+}
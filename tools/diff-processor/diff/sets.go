@@ -23,6 +23,11 @@ func union[K comparable, V any](map1, map2 map[K]V) map[K]V {
 	return merged
 }
 
+// NewFieldSet returns the set of the given fields with zero padding removed
+func NewFieldSet(fields ...string) FieldSet {
+	return sliceToSetRemoveZeroPadding(fields)
+}
+
 func sliceToSetRemoveZeroPadding(slice []string) map[string]struct{} {
 	set := make(map[string]struct{})
 	for _, item := range slice {
@@ -72,3 +77,13 @@ func (fs FieldSet) Difference(subset FieldSet) map[string]struct{} {
 	}
 	return diff
 }
+
+func (fs FieldSet) Intersection(other FieldSet) map[string]struct{} {
+	intersection := make(map[string]struct{})
+	for k := range fs {
+		if _, ok := other[k]; ok {
+			intersection[k] = struct{}{}
+		}
+	}
+	return intersection
+}
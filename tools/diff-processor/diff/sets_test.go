@@ -113,3 +113,38 @@ func TestDifference(t *testing.T) {
 	}
 
 }
+
+func TestIntersection(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		set1     FieldSet
+		set2     FieldSet
+		expected FieldSet
+	}{
+		{
+			name:     "empty set intersection is empty set",
+			set1:     FieldSet{},
+			set2:     FieldSet{"a": {}},
+			expected: FieldSet{},
+		},
+		{
+			name:     "disjoint sets have empty intersection",
+			set1:     FieldSet{"a": {}},
+			set2:     FieldSet{"b": {}},
+			expected: FieldSet{},
+		},
+		{
+			name:     "overlapping sets intersect on shared fields",
+			set1:     FieldSet{"a": {}, "b": {}},
+			set2:     FieldSet{"b": {}, "c": {}},
+			expected: FieldSet{"b": {}},
+		},
+	} {
+		got := tc.set1.Intersection(tc.set2)
+		gotKeys := setToSortedSlice(got)
+		expectedKeys := setToSortedSlice(tc.expected)
+		if !cmp.Equal(gotKeys, expectedKeys) {
+			t.Errorf("unexpected result for test case %s: %v (expected %v)", tc.name, gotKeys, expectedKeys)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package report writes the results of diff-processor commands in formats
+// that can be consumed by CI systems and code scanning tools.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatSARIF    = "sarif"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatJSON, FormatSARIF, FormatMarkdown}
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Finding is a single result of a diff-processor command.
+type Finding struct {
+	Resource string   `json:"resource"`
+	Field    string   `json:"field,omitempty"`
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// SourceFile is the mmv1 YAML file the resource is generated from,
+	// relative to the root of the Magic Modules repository. It is empty for
+	// handwritten resources.
+	SourceFile string `json:"source_file,omitempty"`
+	HelpURI    string `json:"help_uri,omitempty"`
+}
+
+// Report is the JSON output of a command. Its schema is versioned so that
+// consumers can detect incompatible changes.
+type Report struct {
+	Version  int       `json:"version"`
+	Command  string    `json:"command"`
+	Findings []Finding `json:"findings"`
+}
+
+const reportVersion = 1
+
+// ValidateFormat returns an error if format isn't one of Formats.
+func ValidateFormat(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("unknown format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// Write writes the findings of command to w in the given format. Findings
+// are sorted by resource, field, rule and message.
+func Write(w io.Writer, format, command string, findings []Finding) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	findings = slices.Clone(findings)
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Message < b.Message
+	})
+	switch format {
+	case FormatSARIF:
+		return writeSARIF(w, findings)
+	case FormatMarkdown:
+		return writeMarkdown(w, command, findings)
+	default:
+		return writeJSON(w, command, findings)
+	}
+}
+
+func writeJSON(w io.Writer, command string, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Report{Version: reportVersion, Command: command, Findings: findings}); err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+	return nil
+}
+
+func writeMarkdown(w io.Writer, command string, findings []Finding) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## diff-processor %s\n\n", command)
+	if len(findings) == 0 {
+		sb.WriteString("No findings.\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	sb.WriteString("| Severity | Resource | Field | Rule | Message | Source |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, f := range findings {
+		rule := f.RuleID
+		if f.HelpURI != "" {
+			rule = fmt.Sprintf("[%s](%s)", f.RuleID, f.HelpURI)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n",
+			f.Severity,
+			markdownCode(f.Resource),
+			markdownCode(f.Field),
+			rule,
+			markdownEscape(f.Message),
+			markdownCode(f.SourceFile),
+		)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownEscape(s) + "`"
+}
+
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testFindings = []Finding{
+	{
+		Resource: "google_y",
+		RuleID:   "resource-map-resource-removal-or-rename",
+		Severity: SeverityError,
+		Message:  "Resource `google_y` was either removed or renamed",
+		HelpURI:  "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-map-resource-removal-or-rename",
+	},
+	{
+		Resource:   "google_x",
+		Field:      "field_a",
+		RuleID:     "field-optional-to-required",
+		Severity:   SeverityError,
+		Message:    "Field `field_a` changed from optional to required on `google_x`",
+		SourceFile: "mmv1/products/x/X.yaml",
+		HelpURI:    "https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required",
+	},
+	{
+		Resource:   "google_x",
+		Field:      "field_b",
+		RuleID:     "missing-test",
+		Severity:   SeverityWarning,
+		Message:    "Field `field_b` of resource `google_x` | is not set in any test",
+		SourceFile: "mmv1/products/x/X.yaml",
+	},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, "breaking-changes", testFindings); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshal output: %s", err)
+	}
+	want := Report{
+		Version:  1,
+		Command:  "breaking-changes",
+		Findings: []Finding{testFindings[1], testFindings[2], testFindings[0]},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Write() returned unexpected report (-want, +got):\n%s", diff)
+	}
+	// Write must not reorder the caller's findings.
+	if testFindings[0].Resource != "google_y" {
+		t.Errorf("Write() modified its input")
+	}
+}
+
+func TestWriteJSONNoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, "schema-diff", nil); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	if !strings.Contains(buf.String(), `"findings": []`) {
+		t.Errorf("Write() with no findings got %s; want an empty findings list", buf.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSARIF, "breaking-changes", testFindings); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshal output: %s", err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("Write() returned unexpected sarif log: %s", buf.String())
+	}
+	run := got.Runs[0]
+	wantRules := []sarifRule{
+		{ID: "field-optional-to-required", HelpURI: testFindings[1].HelpURI},
+		{ID: "missing-test"},
+		{ID: "resource-map-resource-removal-or-rename", HelpURI: testFindings[0].HelpURI},
+	}
+	if diff := cmp.Diff(wantRules, run.Tool.Driver.Rules); diff != "" {
+		t.Errorf("Write() returned unexpected rules (-want, +got):\n%s", diff)
+	}
+	wantResults := []sarifResult{
+		{
+			RuleID:    "field-optional-to-required",
+			RuleIndex: 0,
+			Level:     SeverityError,
+			Message:   sarifMessage{Text: testFindings[1].Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "mmv1/products/x/X.yaml"}},
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "google_x.field_a", Kind: "member"}},
+			}},
+		},
+		{
+			RuleID:    "missing-test",
+			RuleIndex: 1,
+			Level:     SeverityWarning,
+			Message:   sarifMessage{Text: testFindings[2].Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "mmv1/products/x/X.yaml"}},
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "google_x.field_b", Kind: "member"}},
+			}},
+		},
+		{
+			RuleID:    "resource-map-resource-removal-or-rename",
+			RuleIndex: 2,
+			Level:     SeverityError,
+			Message:   sarifMessage{Text: testFindings[0].Message},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "google_y", Kind: "type"}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(wantResults, run.Results); diff != "" {
+		t.Errorf("Write() returned unexpected results (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatMarkdown, "breaking-changes", testFindings); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	want := "## diff-processor breaking-changes\n\n" +
+		"| Severity | Resource | Field | Rule | Message | Source |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| error | `google_x` | `field_a` | [field-optional-to-required](https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#field-optional-to-required) | Field `field_a` changed from optional to required on `google_x` | `mmv1/products/x/X.yaml` |\n" +
+		"| warning | `google_x` | `field_b` | missing-test | Field `field_b` of resource `google_x` \\| is not set in any test | `mmv1/products/x/X.yaml` |\n" +
+		"| error | `google_y` |  | [resource-map-resource-removal-or-rename](https://googlecloudplatform.github.io/magic-modules/breaking-changes/breaking-changes#resource-map-resource-removal-or-rename) | Resource `google_y` was either removed or renamed |  |\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write() returned unexpected markdown (-want, +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatMarkdown, "schema-diff", nil); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	if want := "## diff-processor schema-diff\n\nNo findings.\n"; buf.String() != want {
+		t.Errorf("Write() with no findings got %q; want %q", buf.String(), want)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "xml", "schema-diff", testFindings); err == nil {
+		t.Errorf("Write() with unknown format returned no error")
+	}
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The subset of SARIF 2.1.0 needed to report findings.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "diff-processor"
+	toolURI      = "https://github.com/GoogleCloudPlatform/magic-modules/tree/main/tools/diff-processor"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func writeSARIF(w io.Writer, findings []Finding) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := make(map[string]int)
+	for _, f := range findings {
+		index, ok := ruleIndexes[f.RuleID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[f.RuleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: f.RuleID, HelpURI: f.HelpURI})
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: index,
+			Level:     f.Severity,
+			Message:   sarifMessage{Text: f.Message},
+			Locations: sarifLocations(f),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("error encoding sarif: %w", err)
+	}
+	return nil
+}
+
+func sarifLocations(f Finding) []sarifLocation {
+	if f.Resource == "" && f.SourceFile == "" {
+		return nil
+	}
+	location := sarifLocation{}
+	if f.SourceFile != "" {
+		location.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: f.SourceFile},
+		}
+	}
+	if f.Resource != "" {
+		logical := sarifLogicalLocation{FullyQualifiedName: f.Resource, Kind: "type"}
+		if f.Field != "" {
+			logical = sarifLogicalLocation{FullyQualifiedName: f.Resource + "." + f.Field, Kind: "member"}
+		}
+		location.LogicalLocations = []sarifLogicalLocation{logical}
+	}
+	return []sarifLocation{location}
+}
@@ -26,8 +26,9 @@ import (
 
 var (
 	// used for flags
-	backfillSince  string
-	backfillDryRun bool
+	backfillSince       string
+	backfillDryRun      bool
+	backfillMetadataDir string
 )
 
 var backfillIssueLabels = &cobra.Command{
@@ -46,9 +47,9 @@ var backfillIssueLabels = &cobra.Command{
 }
 
 func execBackfillIssueLabels() error {
-	regexpLabels, err := labeler.BuildRegexLabels(labeler.EnrolledTeamsYaml)
+	regexpLabels, err := buildRegexLabels(backfillMetadataDir)
 	if err != nil {
-		return fmt.Errorf("building regex labels: %w", err)
+		return err
 	}
 	repository := "hashicorp/terraform-provider-google"
 	issues, err := labeler.GetIssues(repository, backfillSince)
@@ -67,4 +68,5 @@ func init() {
 	rootCmd.AddCommand(backfillIssueLabels)
 	backfillIssueLabels.Flags().BoolVar(&backfillDryRun, "dry-run", false, "Only log write actions instead of updating issues")
 	backfillIssueLabels.Flags().StringVar(&backfillSince, "since", "1973-01-01", "Only apply labels to issues filed after given date")
+	backfillIssueLabels.Flags().StringVar(&backfillMetadataDir, "metadata-dir", "", "Directory with resource metadata files used to label resources not listed in enrolled_teams.yml with service/<api> for their API service")
 }
@@ -0,0 +1,84 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GoogleCloudPlatform/magic-modules/tools/issue-labeler/labeler"
+)
+
+var checkLabels = &cobra.Command{
+	Use:   "check-labels METADATA_DIR",
+	Short: "Reports resources that match no service label or more than one",
+	Long: `Reports resources with metadata files under METADATA_DIR (usually a provider's services directory) that match no service label, or more than one label in enrolled_teams.yml.
+
+Resources not listed in enrolled_teams.yml are labeled service/<api> after
+their API service, such as service/pubsub for pubsub.googleapis.com. Label
+names don't always follow API names, so resources of a team with another label
+need an enrolled_teams.yml entry.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execCheckLabels(args[0])
+	},
+}
+
+func execCheckLabels(metadataDir string) error {
+	regexpLabels, err := labeler.BuildRegexLabels(labeler.EnrolledTeamsYaml)
+	if err != nil {
+		return fmt.Errorf("building regex labels: %w", err)
+	}
+	services, err := labeler.LoadMetadataServices(metadataDir)
+	if err != nil {
+		return err
+	}
+	issues := labeler.CheckLabels(services, regexpLabels)
+	for _, issue := range issues {
+		if len(issue.Labels) == 0 {
+			fmt.Printf("%s: no label, add the resource to enrolled_teams.yml\n", issue.Resource)
+		} else {
+			fmt.Printf("%s: multiple labels: %s\n", issue.Resource, strings.Join(issue.Labels, ", "))
+		}
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d resources without exactly one service label", len(issues))
+	}
+	return nil
+}
+
+// buildRegexLabels returns the labels from enrolled_teams.yml, followed by
+// labels derived from the metadata files under metadataDir if it is set.
+func buildRegexLabels(metadataDir string) ([]labeler.RegexpLabel, error) {
+	regexpLabels, err := labeler.BuildRegexLabels(labeler.EnrolledTeamsYaml)
+	if err != nil {
+		return nil, fmt.Errorf("building regex labels: %w", err)
+	}
+	if metadataDir == "" {
+		return regexpLabels, nil
+	}
+	services, err := labeler.LoadMetadataServices(metadataDir)
+	if err != nil {
+		return nil, err
+	}
+	return labeler.AddMetadataLabels(regexpLabels, services), nil
+}
+
+func init() {
+	rootCmd.AddCommand(checkLabels)
+}
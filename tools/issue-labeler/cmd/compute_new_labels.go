@@ -26,6 +26,8 @@ import (
 	"github.com/GoogleCloudPlatform/magic-modules/tools/issue-labeler/labeler"
 )
 
+var computeNewLabelsMetadataDir string
+
 var computeNewLabels = &cobra.Command{
 	Use:   "compute-new-labels",
 	Short: "Computes labels that should be added to an issue based on its body",
@@ -37,9 +39,9 @@ var computeNewLabels = &cobra.Command{
 }
 
 func execComputeNewLabels() error {
-	regexpLabels, err := labeler.BuildRegexLabels(labeler.EnrolledTeamsYaml)
+	regexpLabels, err := buildRegexLabels(computeNewLabelsMetadataDir)
 	if err != nil {
-		return fmt.Errorf("building regex labels: %w", err)
+		return err
 	}
 	issueBody := os.Getenv("ISSUE_BODY")
 	affectedResources := labeler.ExtractAffectedResources(issueBody)
@@ -72,4 +74,5 @@ func execComputeNewLabels() error {
 
 func init() {
 	rootCmd.AddCommand(computeNewLabels)
+	computeNewLabels.Flags().StringVar(&computeNewLabelsMetadataDir, "metadata-dir", "", "Directory with resource metadata files used to label resources not listed in enrolled_teams.yml with service/<api> for their API service")
 }
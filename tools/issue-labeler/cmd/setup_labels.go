@@ -17,7 +17,6 @@ package cmd
 
 import (
 	"flag"
-	"fmt"
 
 	"github.com/spf13/cobra"
 
@@ -25,6 +24,8 @@ import (
 	"github.com/GoogleCloudPlatform/magic-modules/tools/issue-labeler/labeler"
 )
 
+var setupLabelsMetadataDir string
+
 var setupLabels = &cobra.Command{
 	Use:   "setup-labels",
 	Short: "Sets up labels for the relevant services",
@@ -37,9 +38,9 @@ var setupLabels = &cobra.Command{
 
 func execSetupLabels(repo string) error {
 	flag.Set("logtostderr", "true")
-	regexpLabels, err := labeler.BuildRegexLabels(labeler.EnrolledTeamsYaml)
+	regexpLabels, err := buildRegexLabels(setupLabelsMetadataDir)
 	if err != nil {
-		return fmt.Errorf("building regex labels: %w", err)
+		return err
 	}
 	var serviceLabels = make([]string, 0, len(regexpLabels))
 	var serviceLabelMap = map[string]bool{}
@@ -57,4 +58,5 @@ func execSetupLabels(repo string) error {
 
 func init() {
 	rootCmd.AddCommand(setupLabels)
+	setupLabels.Flags().StringVar(&setupLabelsMetadataDir, "metadata-dir", "", "Directory with resource metadata files whose service/<api> labels are set up as well")
 }
@@ -0,0 +1,130 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package labeler
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Metadata files may be templates, so they're read line by line instead of
+// being unmarshalled.
+var metadataResourceRegexp = regexp.MustCompile(`(?m)^resource:\s*['"]?(\w+)`)
+var metadataServiceRegexp = regexp.MustCompile(`(?m)^api_service_name:\s*['"]?([\w.-]+)`)
+
+// LabelIssue is a resource that doesn't resolve to exactly one service label.
+// Labels is empty if no label matched the resource.
+type LabelIssue struct {
+	Resource string
+	Labels   []string
+}
+
+// LoadMetadataServices walks dir for resource metadata files
+// (resource_*_meta.yaml, optionally templated) and returns the API service of
+// each resource, such as "pubsub" for pubsub.googleapis.com.
+func LoadMetadataServices(dir string) (map[string]string, error) {
+	services := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() || !strings.HasPrefix(name, "resource_") || !(strings.HasSuffix(name, "_meta.yaml") || strings.HasSuffix(name, "_meta.yaml.tmpl")) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		resource := metadataResourceRegexp.FindSubmatch(content)
+		service := metadataServiceRegexp.FindSubmatch(content)
+		if resource == nil || service == nil {
+			return nil
+		}
+		services[string(resource[1])] = strings.Split(string(service[1]), ".")[0]
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata from %s: %w", dir, err)
+	}
+	return services, nil
+}
+
+// AddMetadataLabels appends a service/<service> label for each resource,
+// named after its API service. The metadata labels go after the existing ones,
+// so enrolled_teams.yml entries take precedence in ComputeLabels, which is
+// how resources whose team label doesn't follow the API name are labeled.
+func AddMetadataLabels(regexpLabels []RegexpLabel, services map[string]string) []RegexpLabel {
+	var metadataLabels []RegexpLabel
+	for resource, service := range services {
+		metadataLabels = append(metadataLabels, RegexpLabel{
+			Regexp: regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(resource))),
+			Label:  "service/" + service,
+		})
+	}
+	sort.Slice(metadataLabels, func(i, j int) bool {
+		return metadataLabels[i].Regexp.String() < metadataLabels[j].Regexp.String()
+	})
+
+	return append(append([]RegexpLabel{}, regexpLabels...), metadataLabels...)
+}
+
+// CheckLabels reports the resources in services that match no label, or that
+// match more than one label in regexpLabels. regexpLabels should come from
+// BuildRegexLabels; the metadata label of a resource is only used if none of
+// them match it.
+func CheckLabels(services map[string]string, regexpLabels []RegexpLabel) []LabelIssue {
+	metadataLabels := AddMetadataLabels(regexpLabels, services)[len(regexpLabels):]
+
+	resources := make([]string, 0, len(services))
+	for resource := range services {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	issues := []LabelIssue{}
+	for _, resource := range resources {
+		labelSet := make(map[string]struct{})
+		for _, rl := range regexpLabels {
+			if rl.Regexp.MatchString(resource) {
+				labelSet[rl.Label] = struct{}{}
+			}
+		}
+		if len(labelSet) == 0 {
+			for _, rl := range metadataLabels {
+				if rl.Regexp.MatchString(resource) {
+					labelSet[rl.Label] = struct{}{}
+				}
+			}
+		}
+		if len(labelSet) == 1 {
+			continue
+		}
+		labels := []string{}
+		for label := range labelSet {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		issues = append(issues, LabelIssue{Resource: resource, Labels: labels})
+	}
+
+	return issues
+}
@@ -0,0 +1,140 @@
+/*
+* Copyright 2025 Google LLC. All Rights Reserved.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package labeler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLoadMetadataServices(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pubsub/resource_pubsub_topic_meta.yaml":                  "resource: 'google_pubsub_topic'\ngeneration_type: 'mmv1'\napi_service_name: 'pubsub.googleapis.com'\n",
+		"compute/resource_compute_instance_meta.yaml.tmpl":        "resource: 'google_compute_instance'\napi_service_name: 'compute.googleapis.com'\n{{- if ne $.TargetVersionName \"ga\" }}\napi_version: 'beta'\n{{- end }}\n",
+		"containeranalysis/resource_container_registry_meta.yaml": "resource: 'google_container_registry'\ngeneration_type: 'handwritten'\n",
+		"pubsub/resource_pubsub_topic.go":                         "resource: 'google_not_metadata'\napi_service_name: 'pubsub.googleapis.com'\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	services, err := LoadMetadataServices(dir)
+	if err != nil {
+		t.Fatalf("LoadMetadataServices() error: %s", err)
+	}
+	want := map[string]string{
+		"google_pubsub_topic":     "pubsub",
+		"google_compute_instance": "compute",
+	}
+	if !reflect.DeepEqual(services, want) {
+		t.Errorf("want %v; got %v", want, services)
+	}
+
+	if _, err := LoadMetadataServices(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadMetadataServices() with a missing directory succeeded, want error")
+	}
+}
+
+func TestAddMetadataLabels(t *testing.T) {
+	regexpLabels := []RegexpLabel{
+		{
+			Regexp: regexp.MustCompile("^google_service1_resource1$"),
+			Label:  "service/service1",
+		},
+		{
+			Regexp: regexp.MustCompile("^google_service2_.*$"),
+			Label:  "service/service2-subteam1",
+		},
+	}
+	services := map[string]string{
+		"google_service1_resource2": "service1",
+		"google_service2_resource1": "service1",
+		"google_service3_resource1": "service3",
+	}
+	regexpLabels = AddMetadataLabels(regexpLabels, services)
+
+	cases := map[string]struct {
+		resources      []string
+		expectedLabels []string
+	}{
+		"label from metadata": {
+			resources:      []string{"google_service1_resource2"},
+			expectedLabels: []string{"service/service1"},
+		},
+		"enrolled teams override metadata": {
+			resources:      []string{"google_service2_resource1"},
+			expectedLabels: []string{"service/service2-subteam1"},
+		},
+		"label from metadata without an existing label": {
+			resources:      []string{"google_service3_resource1"},
+			expectedLabels: []string{"service/service3"},
+		},
+	}
+
+	for tn, tc := range cases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+			labels := ComputeLabels(tc.resources, regexpLabels)
+			if !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Errorf("want %v; got %v", tc.expectedLabels, labels)
+			}
+		})
+	}
+}
+
+func TestCheckLabels(t *testing.T) {
+	regexpLabels := []RegexpLabel{
+		{
+			Regexp: regexp.MustCompile("^google_service1_.*$"),
+			Label:  "service/service1",
+		},
+		{
+			Regexp: regexp.MustCompile("^google_service1_resource2$"),
+			Label:  "service/service1-subteam1",
+		},
+		{
+			Regexp: regexp.MustCompile("^google_service2_resource1$"),
+			Label:  "service/service2",
+		},
+	}
+	services := map[string]string{
+		"google_service1_resource1": "service1",
+		"google_service1_resource2": "service1",
+		"google_service2_resource1": "service1",
+		"google_service2_resource2": "service2",
+		"google_service3_resource1": "service3",
+	}
+	want := []LabelIssue{
+		{
+			Resource: "google_service1_resource2",
+			Labels:   []string{"service/service1", "service/service1-subteam1"},
+		},
+	}
+	if got := CheckLabels(services, regexpLabels); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
@@ -0,0 +1,329 @@
+package reader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxEvalDepth bounds the nesting of expressions and helper calls followed
+// while evaluating a config, which also stops recursive helpers.
+const maxEvalDepth = 64
+
+// binding is an expression assigned to a name, with the scope it is
+// evaluated in.
+type binding struct {
+	expr  ast.Expr
+	scope *scope
+}
+
+// scope holds what is known while reading a function: the package-level
+// declarations of the service's test files and the function's local
+// variables and parameters. It is used to propagate constant strings through
+// config helpers, fmt.Sprintf and acctest.Nprintf.
+type scope struct {
+	funcDecls map[string]*ast.FuncDecl
+	varDecls  map[string]ast.Expr
+	locals    map[string]binding
+	depth     int
+}
+
+func newPackageScope(funcDecls map[string]*ast.FuncDecl, varDecls map[string]ast.Expr) *scope {
+	return &scope{
+		funcDecls: funcDecls,
+		varDecls:  varDecls,
+		locals:    make(map[string]binding),
+	}
+}
+
+// newFuncScope returns an empty scope for a function called from s.
+func (s *scope) newFuncScope() *scope {
+	return &scope{
+		funcDecls: s.funcDecls,
+		varDecls:  s.varDecls,
+		locals:    make(map[string]binding),
+		depth:     s.depth + 1,
+	}
+}
+
+// withStmt returns the scope after the assignments in stmt, if any. Assigned
+// expressions are evaluated in the scope before the assignment, so that
+// reassignments such as `config = config + "..."` see the previous value.
+func (s *scope) withStmt(stmt ast.Stmt) *scope {
+	var names []*ast.Ident
+	var values []ast.Expr
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if len(stmt.Lhs) != len(stmt.Rhs) {
+			return s
+		}
+		for i, lhs := range stmt.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			value := stmt.Rhs[i]
+			switch stmt.Tok {
+			case token.DEFINE, token.ASSIGN:
+			case token.ADD_ASSIGN:
+				value = &ast.BinaryExpr{X: ident, Op: token.ADD, Y: value}
+			default:
+				continue
+			}
+			names = append(names, ident)
+			values = append(values, value)
+		}
+	case *ast.DeclStmt:
+		genDecl, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok {
+			return s
+		}
+		for _, spec := range genDecl.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				for i, name := range valueSpec.Names {
+					if i < len(valueSpec.Values) {
+						names = append(names, name)
+						values = append(values, valueSpec.Values[i])
+					}
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return s
+	}
+	next := &scope{
+		funcDecls: s.funcDecls,
+		varDecls:  s.varDecls,
+		locals:    make(map[string]binding, len(s.locals)+len(names)),
+		depth:     s.depth,
+	}
+	for name, b := range s.locals {
+		next.locals[name] = b
+	}
+	for i, name := range names {
+		next.locals[name.Name] = binding{expr: values[i], scope: s}
+	}
+	return next
+}
+
+// lookup returns the expression bound to name, preferring local variables
+// over package-level declarations.
+func (s *scope) lookup(name string) (binding, bool) {
+	if b, ok := s.locals[name]; ok {
+		return b, true
+	}
+	if expr, ok := s.varDecls[name]; ok {
+		return binding{expr: expr, scope: newPackageScope(s.funcDecls, s.varDecls)}, true
+	}
+	return binding{}, false
+}
+
+// evalString returns the string value of expr. Substitutions in fmt.Sprintf
+// and acctest.Nprintf templates whose values can't be determined are left in
+// place, to be replaced with placeholders when the config is parsed.
+func (s *scope) evalString(expr ast.Expr) (string, error) {
+	if s.depth > maxEvalDepth {
+		return "", fmt.Errorf("exceeded maximum depth evaluating %v", expr)
+	}
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind == token.STRING {
+			return strconv.Unquote(expr.Value)
+		}
+		return expr.Value, nil
+	case *ast.ParenExpr:
+		return s.evalString(expr.X)
+	case *ast.Ident:
+		if expr.Name == "true" || expr.Name == "false" {
+			return expr.Name, nil
+		}
+		b, ok := s.lookup(expr.Name)
+		if !ok {
+			return "", fmt.Errorf("failed to find value of %s", expr.Name)
+		}
+		return b.scope.deeper(s).evalString(b.expr)
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", fmt.Errorf("unknown operator %s in config", expr.Op)
+		}
+		x, err := s.evalString(expr.X)
+		if err != nil {
+			return "", err
+		}
+		y, err := s.evalString(expr.Y)
+		if err != nil {
+			return "", err
+		}
+		return x + y, nil
+	case *ast.CallExpr:
+		return s.evalCall(expr)
+	}
+	return "", fmt.Errorf("unknown config expression %v (%T)", expr, expr)
+}
+
+// deeper returns s with its depth raised past from's, so that following
+// bindings counts towards the depth limit.
+func (s *scope) deeper(from *scope) *scope {
+	if s.depth > from.depth {
+		return s
+	}
+	next := *s
+	next.depth = from.depth + 1
+	return &next
+}
+
+func (s *scope) evalCall(call *ast.CallExpr) (string, error) {
+	var name string
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		name = fun.Name
+	case *ast.SelectorExpr:
+		name = fun.Sel.Name
+	}
+	switch {
+	case name == "Sprintf" && len(call.Args) > 0:
+		return s.evalSprintf(call.Args[0], call.Args[1:])
+	case name == "Nprintf" && len(call.Args) > 0:
+		var context ast.Expr
+		if len(call.Args) > 1 {
+			context = call.Args[1]
+		}
+		return s.evalNprintf(call.Args[0], context)
+	}
+	if funcDecl, ok := s.funcDecls[name]; ok {
+		return s.evalFunc(funcDecl, call.Args)
+	}
+	// Unknown wrappers usually take the config as their first argument.
+	if len(call.Args) > 0 {
+		return s.evalString(call.Args[0])
+	}
+	return "", fmt.Errorf("failed to find function declaration %s", name)
+}
+
+// evalFunc returns the value of the first top-level return statement of a
+// helper function, with its parameters bound to the call's arguments.
+func (s *scope) evalFunc(funcDecl *ast.FuncDecl, args []ast.Expr) (string, error) {
+	funcScope := s.newFuncScope()
+	if funcDecl.Type.Params != nil {
+		i := 0
+		for _, field := range funcDecl.Type.Params.List {
+			for _, name := range field.Names {
+				if i < len(args) {
+					funcScope.locals[name.Name] = binding{expr: args[i], scope: s}
+				}
+				i++
+			}
+		}
+	}
+	if funcDecl.Body == nil {
+		return "", fmt.Errorf("function %s has no body", funcDecl.Name.Name)
+	}
+	for _, stmt := range funcDecl.Body.List {
+		if returnStmt, ok := stmt.(*ast.ReturnStmt); ok {
+			if len(returnStmt.Results) > 0 {
+				return funcScope.evalString(returnStmt.Results[0])
+			}
+			return "", fmt.Errorf("failed to find a config string in results %v", returnStmt.Results)
+		}
+		funcScope = funcScope.withStmt(stmt)
+	}
+	return "", fmt.Errorf("failed to find a return statement in %s", funcDecl.Name.Name)
+}
+
+var sprintfVerbPattern = regexp.MustCompile(`%(\[(\d+)\])?[-+# 0]*\d*(\.\d+)?([vTtbcspqxXUeEfFgGdo%])`)
+
+// evalSprintf formats the template with the arguments whose values are known.
+// Verbs for other arguments are normalized to %v.
+func (s *scope) evalSprintf(format ast.Expr, args []ast.Expr) (string, error) {
+	template, err := s.evalString(format)
+	if err != nil {
+		return "", err
+	}
+	next := 0
+	return sprintfVerbPattern.ReplaceAllStringFunc(template, func(verb string) string {
+		match := sprintfVerbPattern.FindStringSubmatch(verb)
+		if match[4] == "%" {
+			return "%"
+		}
+		i := next
+		if match[2] != "" {
+			n, err := strconv.Atoi(match[2])
+			if err != nil {
+				return "%v"
+			}
+			i = n - 1
+		}
+		next = i + 1
+		if i < 0 || i >= len(args) {
+			return "%v"
+		}
+		value, err := s.evalString(args[i])
+		if err != nil {
+			return "%v"
+		}
+		if match[4] == "q" {
+			return strconv.Quote(value)
+		}
+		return value
+	}), nil
+}
+
+// evalNprintf substitutes the keys of the context map whose values are known.
+func (s *scope) evalNprintf(format, context ast.Expr) (string, error) {
+	template, err := s.evalString(format)
+	if err != nil {
+		return "", err
+	}
+	if context == nil {
+		return template, nil
+	}
+	compLit, compLitScope := s.resolveCompositeLit(context)
+	if compLit == nil {
+		return template, nil
+	}
+	var replacements []string
+	for _, elt := range compLit.Elts {
+		keyValueExpr, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, err := compLitScope.evalString(keyValueExpr.Key)
+		if err != nil {
+			continue
+		}
+		value, err := compLitScope.evalString(keyValueExpr.Value)
+		if err != nil {
+			continue
+		}
+		replacements = append(replacements, "%{"+key+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template), nil
+}
+
+// resolveCompositeLit follows variables to the composite literal they hold,
+// such as the context map passed to acctest.Nprintf.
+func (s *scope) resolveCompositeLit(expr ast.Expr) (*ast.CompositeLit, *scope) {
+	for depth := 0; depth < maxEvalDepth; depth++ {
+		switch e := expr.(type) {
+		case *ast.CompositeLit:
+			return e, s
+		case *ast.UnaryExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			b, ok := s.lookup(e.Name)
+			if !ok {
+				return nil, nil
+			}
+			expr, s = b.expr, b.scope
+		default:
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -26,6 +25,9 @@ type Step map[string]Resources // map of resource types to resources of that typ
 type Test struct {
 	Name  string
 	Steps []Step
+	// Configs holds the config string of each step, with fmt substitutions
+	// (e.g. %{random_suffix}) that couldn't be resolved left in place.
+	Configs []string
 }
 
 func (t *Test) String() string {
@@ -67,7 +69,7 @@ func ReadAllTests(servicesDir string) ([]*Test, map[string]error) {
 // Read all the test files in a service directory together to capture cross-file function usage.
 func ReadTestFiles(filenames []string) ([]*Test, map[string]error) {
 	funcDecls := make(map[string]*ast.FuncDecl) // map of function names to function declarations
-	varDecls := make(map[string]*ast.BasicLit)  // map of variable names to value expressions
+	varDecls := make(map[string]ast.Expr)       // map of package-level constant and variable names to value expressions
 	errs := make(map[string]error)              // map of file or test names to errors encountered parsing
 	fset := token.NewFileSet()
 	for _, filename := range filenames {
@@ -84,9 +86,9 @@ func ReadTestFiles(filenames []string) ([]*Test, map[string]error) {
 				// This is an import, constant, type, or variable declaration
 				for _, spec := range genDecl.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
-						if len(valueSpec.Values) > 0 {
-							if basicLit, ok := valueSpec.Values[0].(*ast.BasicLit); ok {
-								varDecls[valueSpec.Names[0].Name] = basicLit
+						for i, name := range valueSpec.Names {
+							if i < len(valueSpec.Values) {
+								varDecls[name.Name] = valueSpec.Values[i]
 							}
 						}
 					}
@@ -94,10 +96,11 @@ func ReadTestFiles(filenames []string) ([]*Test, map[string]error) {
 			}
 		}
 	}
+	pkgScope := newPackageScope(funcDecls, varDecls)
 	tests := make([]*Test, 0)
 	for name, funcDecl := range funcDecls {
 		if strings.HasPrefix(name, "TestAcc") {
-			funcTests, err := readTestFunc(funcDecl, funcDecls, varDecls)
+			funcTests, err := readTestFunc(funcDecl, pkgScope)
 			if err != nil {
 				errs[name] = err
 			}
@@ -110,19 +113,21 @@ func ReadTestFiles(filenames []string) ([]*Test, map[string]error) {
 	return tests, nil
 }
 
-func readTestFunc(testFunc *ast.FuncDecl, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) ([]*Test, error) {
+func readTestFunc(testFunc *ast.FuncDecl, pkgScope *scope) ([]*Test, error) {
 	// This is an exported test function.
 	var tests []*Test
 	var errs []error
+	funcScope := pkgScope.newFuncScope()
 	vars := make(map[string]*ast.CompositeLit, len(testFunc.Body.List)) // map of variable names to composite literal values in function body
 	for _, stmt := range testFunc.Body.List {
+		funcScope = funcScope.withStmt(stmt)
 		if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
 			if callExpr, ok := exprStmt.X.(*ast.CallExpr); ok {
 				// This is a call expression.
 				ident, isIdent := callExpr.Fun.(*ast.Ident)
 				selExpr, isSelExpr := callExpr.Fun.(*ast.SelectorExpr)
 				if isIdent && ident.Name == "VcrTest" || isSelExpr && selExpr.Sel.Name == "VcrTest" {
-					test, err := readVcrTestCall(callExpr, funcDecls, varDecls)
+					test, err := readVcrTestCall(callExpr, funcScope)
 					if err != nil {
 						errs = append(errs, err)
 					}
@@ -143,7 +148,7 @@ func readTestFunc(testFunc *ast.FuncDecl, funcDecls map[string]*ast.FuncDecl, va
 		} else if rangeStmt, ok := stmt.(*ast.RangeStmt); ok {
 			if ident, ok := rangeStmt.X.(*ast.Ident); ok {
 				if varCompLit, ok := vars[ident.Name]; ok {
-					serialTests, serialErrs := readSerialTestCompLit(varCompLit, funcDecls, varDecls)
+					serialTests, serialErrs := readSerialTestCompLit(varCompLit, pkgScope)
 					errs = append(errs, serialErrs...)
 					tests = append(tests, serialTests...)
 				}
@@ -157,12 +162,12 @@ func readTestFunc(testFunc *ast.FuncDecl, funcDecls map[string]*ast.FuncDecl, va
 }
 
 // Reads a composite literal which is either a slice or a map of serialized test functions.
-func readSerialTestCompLit(varCompLit *ast.CompositeLit, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) ([]*Test, []error) {
+func readSerialTestCompLit(varCompLit *ast.CompositeLit, pkgScope *scope) ([]*Test, []error) {
 	var tests []*Test
 	var errs []error
 	for _, elt := range varCompLit.Elts {
 		if eltKeyValueExpr, ok := elt.(*ast.KeyValueExpr); ok {
-			eltTests, err := readSerialTestEltKeyValueExpr(eltKeyValueExpr, funcDecls, varDecls)
+			eltTests, err := readSerialTestEltKeyValueExpr(eltKeyValueExpr, pkgScope)
 			if err != nil {
 				errs = append(errs, err)
 			}
@@ -172,22 +177,22 @@ func readSerialTestCompLit(varCompLit *ast.CompositeLit, funcDecls map[string]*a
 	return tests, errs
 }
 
-func readSerialTestEltKeyValueExpr(eltKeyValueExpr *ast.KeyValueExpr, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) ([]*Test, error) {
+func readSerialTestEltKeyValueExpr(eltKeyValueExpr *ast.KeyValueExpr, pkgScope *scope) ([]*Test, error) {
 	if ident, ok := eltKeyValueExpr.Value.(*ast.Ident); ok {
-		if testFunc, ok := funcDecls[ident.Name]; ok {
-			return readTestFunc(testFunc, funcDecls, varDecls)
+		if testFunc, ok := pkgScope.funcDecls[ident.Name]; ok {
+			return readTestFunc(testFunc, pkgScope)
 		}
 		return nil, fmt.Errorf("failed to find function with name %s", ident.Name)
 	}
 	return nil, fmt.Errorf("element key value expression with key %+v had non-ident value %+v", eltKeyValueExpr.Key, eltKeyValueExpr.Value)
 }
 
-func readVcrTestCall(vcrTestCall *ast.CallExpr, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (*Test, error) {
+func readVcrTestCall(vcrTestCall *ast.CallExpr, funcScope *scope) (*Test, error) {
 	for _, arg := range vcrTestCall.Args {
 		if vcrTestArgCompLit, ok := arg.(*ast.CompositeLit); ok {
 			if selExpr, ok := vcrTestArgCompLit.Type.(*ast.SelectorExpr); ok {
 				if ident, ok := selExpr.X.(*ast.Ident); ok && ident.Name == "resource" && selExpr.Sel.Name == "TestCase" {
-					return readTestCaseCompLit(vcrTestArgCompLit, funcDecls, varDecls)
+					return readTestCaseCompLit(vcrTestArgCompLit, funcScope)
 				}
 			}
 		}
@@ -195,12 +200,12 @@ func readVcrTestCall(vcrTestCall *ast.CallExpr, funcDecls map[string]*ast.FuncDe
 	return nil, fmt.Errorf("failed to find TestCase in %v", vcrTestCall.Args)
 }
 
-func readTestCaseCompLit(testCaseCompLit *ast.CompositeLit, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (*Test, error) {
+func readTestCaseCompLit(testCaseCompLit *ast.CompositeLit, funcScope *scope) (*Test, error) {
 	for _, elt := range testCaseCompLit.Elts {
 		if keyValueExpr, ok := elt.(*ast.KeyValueExpr); ok {
 			if ident, ok := keyValueExpr.Key.(*ast.Ident); ok && ident.Name == "Steps" {
 				if stepsCompLit, ok := keyValueExpr.Value.(*ast.CompositeLit); ok {
-					return readStepsCompLit(stepsCompLit, funcDecls, varDecls)
+					return readStepsCompLit(stepsCompLit, funcScope)
 				}
 			}
 		}
@@ -208,7 +213,7 @@ func readTestCaseCompLit(testCaseCompLit *ast.CompositeLit, funcDecls map[string
 	return nil, fmt.Errorf("failed to find Steps in %v", testCaseCompLit.Elts)
 }
 
-func readStepsCompLit(stepsCompLit *ast.CompositeLit, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (*Test, error) {
+func readStepsCompLit(stepsCompLit *ast.CompositeLit, funcScope *scope) (*Test, error) {
 	test := &Test{}
 	errs := make([]error, 0)
 	for _, elt := range stepsCompLit.Elts {
@@ -216,15 +221,7 @@ func readStepsCompLit(stepsCompLit *ast.CompositeLit, funcDecls map[string]*ast.
 			for _, eltCompLitElt := range eltCompLit.Elts {
 				if keyValueExpr, ok := eltCompLitElt.(*ast.KeyValueExpr); ok {
 					if ident, ok := keyValueExpr.Key.(*ast.Ident); ok && ident.Name == "Config" {
-						var configStr string
-						var err error
-						if configCallExpr, ok := keyValueExpr.Value.(*ast.CallExpr); ok {
-							configStr, err = readConfigCallExpr(configCallExpr, funcDecls, varDecls)
-						} else if ident, ok := keyValueExpr.Value.(*ast.Ident); ok {
-							if configVar, ok := varDecls[ident.Name]; ok {
-								configStr, err = strconv.Unquote(configVar.Value)
-							}
-						}
+						configStr, err := funcScope.evalString(keyValueExpr.Value)
 						if err != nil {
 							errs = append(errs, err)
 						}
@@ -233,6 +230,7 @@ func readStepsCompLit(stepsCompLit *ast.CompositeLit, funcDecls map[string]*ast.
 							errs = append(errs, err)
 						}
 						test.Steps = append(test.Steps, step)
+						test.Configs = append(test.Configs, configStr)
 					}
 				}
 			}
@@ -244,70 +242,17 @@ func readStepsCompLit(stepsCompLit *ast.CompositeLit, funcDecls map[string]*ast.
 	return test, nil
 }
 
-// Read the call expression in the public test function that returns the config.
-func readConfigCallExpr(configCallExpr *ast.CallExpr, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (string, error) {
-	if ident, ok := configCallExpr.Fun.(*ast.Ident); ok {
-		if configFunc, ok := funcDecls[ident.Name]; ok {
-			return readConfigFunc(configFunc, funcDecls, varDecls)
-		}
-		return "", fmt.Errorf("failed to find function declaration %s", ident.Name)
-	}
-	return "", fmt.Errorf("failed to get ident for %v", configCallExpr.Fun)
-}
-
-func readConfigFunc(configFunc *ast.FuncDecl, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (string, error) {
-	for _, stmt := range configFunc.Body.List {
-		if returnStmt, ok := stmt.(*ast.ReturnStmt); ok {
-			if len(returnStmt.Results) > 0 {
-				return readConfigFuncResult(returnStmt.Results[0], funcDecls, varDecls)
-			}
-			return "", fmt.Errorf("failed to find a config string in results %v", returnStmt.Results)
-		}
-	}
-	return "", fmt.Errorf("failed to find a return statement in %v", configFunc.Body.List)
-}
-
-// Read the return result of a config func and return the config string.
-func readConfigFuncResult(result ast.Expr, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (string, error) {
-	if basicLit, ok := result.(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
-		return strconv.Unquote(basicLit.Value)
-	} else if callExpr, ok := result.(*ast.CallExpr); ok {
-		return readConfigFuncCallExpr(callExpr, funcDecls, varDecls)
-	} else if binaryExpr, ok := result.(*ast.BinaryExpr); ok {
-		xConfigStr, err := readConfigFuncResult(binaryExpr.X, funcDecls, varDecls)
-		if err != nil {
-			return "", err
-		}
-		yConfigStr, err := readConfigFuncResult(binaryExpr.Y, funcDecls, varDecls)
-		if err != nil {
-			return "", err
-		}
-		return xConfigStr + yConfigStr, nil
-	}
-	return "", fmt.Errorf("unknown config func result %v (%T)", result, result)
-}
-
-// Read the call expression in the config function that returns the config string.
-// The call expression can contain a nested call expression.
-// Return the config string.
-func readConfigFuncCallExpr(configFuncCallExpr *ast.CallExpr, funcDecls map[string]*ast.FuncDecl, varDecls map[string]*ast.BasicLit) (string, error) {
-	if len(configFuncCallExpr.Args) > 0 {
-		if basicLit, ok := configFuncCallExpr.Args[0].(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
-			return strconv.Unquote(basicLit.Value)
-		} else if nestedCallExpr, ok := configFuncCallExpr.Args[0].(*ast.CallExpr); ok {
-			return readConfigFuncCallExpr(nestedCallExpr, funcDecls, varDecls)
-		}
-	}
-	// Config string not readable from args, attempt to read call expression as a helper function.
-	return readConfigCallExpr(configFuncCallExpr, funcDecls, varDecls)
-}
-
 var subPattern = regexp.MustCompile("%({[^{}]*}|[vTtbcspqxXUeEfFgGdo])")
 
+// Substitutions on a line of their own insert whole blocks or attributes.
+var lineSubPattern = regexp.MustCompile("(?m)^[ \t]*%({[^{}]*}|[vTtbcspqxXUeEfFgGdo])[ \t]*$")
+
 // Read the config string and return a test step.
 func readConfigStr(configStr string) (Step, error) {
 	// Remove fmt substitutions because they interfere with hcl parsing.
-	// Replace with a value that can be parsed outside quotation marks.
+	// Substitutions that stand for blocks are dropped, and the rest are
+	// replaced with a value that can be parsed outside quotation marks.
+	configStr = lineSubPattern.ReplaceAllString(configStr, "")
 	configStr = subPattern.ReplaceAllString(configStr, "true")
 	parser := hclparse.NewParser()
 	file, diagnostics := parser.ParseHCL([]byte(configStr), "config.hcl")
@@ -34,7 +34,7 @@ func TestReadCoveredResourceTestFile(t *testing.T) {
 	} else if coveredResource, ok := coveredResources["resource"]; !ok {
 		t.Errorf("did not find a covered resource in %v", coveredResources)
 	} else if expectedResource := (Resource{
-		"field_four.field_five.field_six": "true",
+		"field_four.field_five.field_six": "0",
 		"field_one":                       "\"value-one\"",
 		"field_seven":                     "true",
 	}); !reflect.DeepEqual(coveredResource, expectedResource) {
@@ -96,6 +96,20 @@ func TestReadMultipleResourcesTestFile(t *testing.T) {
 	}
 }
 
+const serialResourceConfig1 = `
+resource "serial_resource" "resource" {
+  field_one = "value-one"
+}
+`
+
+const serialResourceConfig2 = `
+resource "serial_resource" "resource" {
+  field_two {
+    field_three = "value-two"
+  }
+}
+`
+
 func TestReadSerialResourceTestFile(t *testing.T) {
 	tests, err := ReadTestFiles([]string{"testdata/service/serial_resource_test.go"})
 	if err != nil {
@@ -114,6 +128,7 @@ func TestReadSerialResourceTestFile(t *testing.T) {
 					},
 				},
 			},
+			Configs: []string{serialResourceConfig1},
 		},
 		{
 			Name: "testAccSerialResource2",
@@ -126,6 +141,7 @@ func TestReadSerialResourceTestFile(t *testing.T) {
 					},
 				},
 			},
+			Configs: []string{serialResourceConfig2},
 		},
 	}; !reflect.DeepEqual(tests, expectedTests) {
 		t.Errorf("found unexpected serialized tests: %v, expected %v", tests, expectedTests)
@@ -149,6 +165,7 @@ func TestReadCrossFileTests(t *testing.T) {
 					},
 				},
 			},
+			Configs: []string{serialResourceConfig1},
 		},
 		{
 			Name: "testAccCrossFile2",
@@ -161,6 +178,7 @@ func TestReadCrossFileTests(t *testing.T) {
 					},
 				},
 			},
+			Configs: []string{serialResourceConfig2},
 		},
 	}
 
@@ -198,12 +216,87 @@ func TestReadHelperFunctionCall(t *testing.T) {
 				},
 			},
 		},
+		Configs: []string{`
+resource "helper_resource" "default" {
+  field_one = "value-one"
+}
+
+resource "helped_resource" "primary" {
+  field_one = "value-one"
+}
+`},
 	}
 	if !reflect.DeepEqual(tests[0], expectedTest) {
 		t.Errorf("found unexpected tests using helper function: %v, expected %v", tests[0], expectedTest)
 	}
 }
 
+func TestReadConstantPropagationTestFile(t *testing.T) {
+	tests, err := ReadTestFiles([]string{"testdata/service/constant_propagation_test.go"})
+	if err != nil {
+		t.Fatalf("error reading constant propagation test file: %v", err)
+	}
+	if len(tests) != 1 {
+		t.Fatalf("unexpected number of tests: %d, expected 1", len(tests))
+	}
+	expectedTest := &Test{
+		Name: "TestAccConstantPropagation",
+		Steps: []Step{
+			{
+				"propagated_resource": Resources{
+					"resource": Resource{
+						"name": "\"tf-test-true\"",
+						"tier": "\"PREMIUM\"",
+					},
+				},
+			},
+			{
+				"propagated_network": Resources{
+					"network": Resource{
+						"name": "\"tf-test-network\"",
+					},
+				},
+				"propagated_resource": Resources{
+					"resource": Resource{
+						"network": "\"tf-test-network\"",
+						"count":   "3",
+					},
+				},
+			},
+			{
+				"propagated_resource": Resources{
+					"inline": Resource{
+						"tier": "\"PREMIUM\"",
+					},
+				},
+			},
+		},
+		Configs: []string{`
+resource "propagated_resource" "resource" {
+  name = "tf-test-%{random_suffix}"
+  tier = "PREMIUM"
+}
+`, `
+resource "propagated_network" "network" {
+  name = "tf-test-network"
+}
+
+resource "propagated_resource" "resource" {
+  network = "tf-test-network"
+  count   = 3
+  %v
+}
+`, `
+resource "propagated_resource" "inline" {
+  tier = "PREMIUM"
+}
+`},
+	}
+	if !reflect.DeepEqual(tests[0], expectedTest) {
+		t.Errorf("found unexpected test with constant propagation: %#v, expected %#v", tests[0], expectedTest)
+	}
+}
+
 func TestFlattenResource(t *testing.T) {
 	for _, tc := range []struct {
 		name        string
@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-provider-google-beta/google-beta/acctest"
+)
+
+const networkTemplate = `
+resource "propagated_network" "network" {
+  name = "%s"
+}
+`
+
+func TestAccConstantPropagation(t *testing.T) {
+	context := map[string]interface{}{
+		"tier":          "PREMIUM",
+		"random_suffix": acctest.RandString(t, 10),
+	}
+	name := "tf-test-network"
+
+	acctest.VcrTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConstantPropagation_nprintf(context),
+			},
+			{
+				Config: testAccConstantPropagation_sprintf(name, 3),
+			},
+			{
+				Config: acctest.Nprintf(`
+resource "propagated_resource" "inline" {
+  tier = "%{tier}"
+}
+`, context),
+			},
+		},
+	})
+}
+
+func testAccConstantPropagation_nprintf(context map[string]interface{}) string {
+	config := acctest.Nprintf(`
+resource "propagated_resource" "resource" {
+  name = "tf-test-%{random_suffix}"
+  tier = "%{tier}"
+}
+`, context)
+	return config
+}
+
+func testAccConstantPropagation_sprintf(name string, count int) string {
+	config := fmt.Sprintf(networkTemplate, name)
+	config += fmt.Sprintf(`
+resource "propagated_resource" "resource" {
+  network = %[1]q
+  count   = %[2]d
+  %[3]s
+}
+`, name, count, testAccConstantPropagation_unknown())
+	return config
+}